@@ -35,6 +35,7 @@ func NewRootCmd(version string, defaultConfigs embed.FS) *cobra.Command {
 			debugLSP, _ := cmd.Flags().GetString("debug-lsp")
 			pprof, _ := cmd.Flags().GetString("pprof")
 			disableMouse, _ := cmd.Flags().GetBool("disable-mouse")
+			noSession, _ := cmd.Flags().GetBool("no-session")
 
 			if debug != "" {
 				logFile, err := tea.LogToFile(debug, "gopad")
@@ -77,7 +78,7 @@ func NewRootCmd(version string, defaultConfigs embed.FS) *cobra.Command {
 			}
 
 			lsClient := ls.New(version, config.LanguageServers, lspLogFile)
-			e := gopad.New(lsClient, version, getWorkspace(workspace, args), args)
+			e := gopad.New(lsClient, version, getWorkspace(workspace, args), args, noSession)
 
 			opts := []tea.ProgramOption{
 				tea.WithAltScreen(),
@@ -86,7 +87,7 @@ func NewRootCmd(version string, defaultConfigs embed.FS) *cobra.Command {
 			zone.NewGlobal()
 			defer zone.Close()
 			if !disableMouse {
-				opts = append(opts, tea.WithMouseCellMotion())
+				opts = append(opts, tea.WithMouseAllMotion())
 			} else {
 				zone.SetEnabled(false)
 			}
@@ -107,6 +108,7 @@ func NewRootCmd(version string, defaultConfigs embed.FS) *cobra.Command {
 	cmd.Flags().StringP("debug-lsp", "l", "", "set debug lsp log file")
 	cmd.Flags().StringP("pprof", "p", "", "set pprof address:port")
 	cmd.Flags().BoolP("disable-mouse", "", false, "disable mouse support (enabled by default)")
+	cmd.Flags().BoolP("no-session", "", false, "do not restore or save the workspace session")
 
 	return cmd
 }
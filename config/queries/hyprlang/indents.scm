@@ -1,6 +1,3 @@
-(section) @indent.begin
+(section) @indent
 
-(section
-  "}" @indent.end)
-
-"}" @indent.branch
+"}" @outdent
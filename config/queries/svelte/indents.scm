@@ -19,6 +19,4 @@
   (await_end_expr)
   ">"
   "/>"
-] @branch
-
-(comment) @ignore
+] @outdent
@@ -4,19 +4,26 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"slices"
+	"sync"
+	"unicode/utf8"
 
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/htmlindex"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 
-	"go.gopad.dev/gopad/internal/xbytes"
+	"go.gopad.dev/gopad/internal/xos"
 )
 
+// ErrModifiedOnDisk is returned by Buffer.Save if the file changed on disk since it was loaded or saved.
+var ErrModifiedOnDisk = errors.New("file changed on disk")
+
 // New creates a new buffer from an io.Reader.
 func New(name string, r io.Reader, encoding string, lineEnding LineEnding, onDisk bool) (*Buffer, error) {
 	fileEncoding, err := htmlindex.Get(encoding)
@@ -26,46 +33,46 @@ func New(name string, r io.Reader, encoding string, lineEnding LineEnding, onDis
 
 	hasher := sha256.New()
 
-	br := bufio.NewReader(io.TeeReader(transform.NewReader(r, fileEncoding.NewDecoder()), hasher))
-	var lines []Line
-	for {
-		data, err := br.ReadBytes('\n')
-		if len(data) > 0 {
-			if data[len(data)-1] == '\n' {
-				data = data[:len(data)-1]
-			}
+	// hash the raw file content, this matches the checksum calculated on save
+	content, err := io.ReadAll(transform.NewReader(io.TeeReader(r, hasher), fileEncoding.NewDecoder()))
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
 
-			if len(data) > 0 && data[len(data)-1] == '\r' {
-				data = data[:len(data)-1]
-				if lineEnding == LineEndingAuto {
-					lineEnding = LineEndingCRLF
-				}
-			} else if lineEnding == LineEndingAuto {
-				lineEnding = LineEndingLF
+	// the lines share the content, they are clipped so appending to a line never overwrites the next one
+	lines := make([]Line, 0, bytes.Count(content, lineEndingLF)+1)
+	for {
+		data, rest, found := bytes.Cut(content, lineEndingLF)
+		if len(data) > 0 && data[len(data)-1] == '\r' {
+			data = data[:len(data)-1]
+			if lineEnding == LineEndingAuto {
+				lineEnding = LineEndingCRLF
 			}
+		} else if (found || len(data) > 0) && lineEnding == LineEndingAuto {
+			lineEnding = LineEndingLF
+		}
 
-			lines = append(lines, NewLine(data))
+		if len(data) > 0 {
+			lines = append(lines, NewLine(slices.Clip(data)))
 		} else {
 			lines = append(lines, NewEmptyLine())
 		}
 
-		if err == io.EOF {
+		if !found {
 			break
 		}
-
-		if err != nil {
-			return nil, fmt.Errorf("error reading file: %w", err)
-		}
+		content = rest
 	}
 
 	b := &Buffer{
 		name:       name,
 		encoding:   encoding,
 		lineEnding: lineEnding,
-		lines:      lines,
+		lines:      newRope(lines),
 		checksum:   hasher.Sum(nil),
 		onDisk:     onDisk,
 	}
+	b.setSaved()
 
 	return b, nil
 }
@@ -86,35 +93,64 @@ func NewFromFile(name string, encoding string, lineEnding LineEnding) (*Buffer,
 	return New(name, file, encoding, lineEnding, true)
 }
 
-// Buffer represents a file in memory.
-type Buffer struct {
-	name       string
-	encoding   string
-	lineEnding LineEnding
-	version    int32
-	lines      []Line
-	checksum   []byte
-	onDisk     bool
-	dirty      bool
+// SaveOptions configures how the buffer is normalized when it is saved.
+type SaveOptions struct {
+	TrimTrailingWhitespace bool
+	InsertFinalNewline     bool
 }
 
+// Buffer represents a file in memory.
+type Buffer struct {
+	name        string
+	encoding    string
+	lineEnding  LineEnding
+	saveOptions SaveOptions
+	version     int32
+	lines       rope
+	checksum    []byte
+	onDisk      bool
+	dirty       bool
+	// stat is the file info of lazily loaded buffers, nil otherwise
+	stat os.FileInfo
+	// file is the file lazily loaded buffers read their lines from, nil otherwise
+	file io.Closer
+
+	// saved is the content of the file on disk, an empty rope if unknown
+	saved           rope
+	savedEncoding   string
+	savedLineEnding LineEnding
+
+	// data caches the content returned by Bytes for the rope it was built from, Copy shares it with other goroutines
+	dataMu   sync.Mutex
+	data     []byte
+	dataRoot *ropeNode
+}
+
+// Copy returns a snapshot of the buffer. The lines are shared, so this is cheap and changes to either buffer are not visible in the other.
 func (b *Buffer) Copy() *Buffer {
-	lines := make([]Line, len(b.lines))
-	for i, line := range b.lines {
-		lines[i] = line.Copy()
-	}
 	checksum := make([]byte, len(b.checksum))
 	copy(checksum, b.checksum)
 
+	b.dataMu.Lock()
+	defer b.dataMu.Unlock()
+
 	return &Buffer{
-		name:       b.name,
-		encoding:   b.encoding,
-		lineEnding: b.lineEnding,
-		version:    b.version,
-		lines:      lines,
-		checksum:   checksum,
-		onDisk:     b.onDisk,
-		dirty:      b.dirty,
+		name:            b.name,
+		encoding:        b.encoding,
+		lineEnding:      b.lineEnding,
+		saveOptions:     b.saveOptions,
+		version:         b.version,
+		lines:           b.lines,
+		checksum:        checksum,
+		onDisk:          b.onDisk,
+		dirty:           b.dirty,
+		stat:            b.stat,
+		file:            b.file,
+		saved:           b.saved,
+		savedEncoding:   b.savedEncoding,
+		savedLineEnding: b.savedLineEnding,
+		data:            b.data,
+		dataRoot:        b.dataRoot,
 	}
 }
 
@@ -156,6 +192,30 @@ func (b *Buffer) SetLineEnding(lineEnding LineEnding) {
 	b.lineEnding = lineEnding
 }
 
+// SaveOptions returns the options used to normalize the buffer on save.
+func (b *Buffer) SaveOptions() SaveOptions {
+	return b.saveOptions
+}
+
+// SetSaveOptions sets the options used to normalize the buffer on save.
+func (b *Buffer) SetSaveOptions(options SaveOptions) {
+	b.saveOptions = options
+}
+
+// Lazy returns whether the lines of the buffer are read from disk when they are accessed.
+func (b *Buffer) Lazy() bool {
+	return b.stat != nil
+}
+
+// Close closes the file lazily loaded buffers read their lines from, lines which are not in memory can't be read afterward.
+// Copies of the buffer share the file, so they must not be used after closing it.
+func (b *Buffer) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	return b.file.Close()
+}
+
 // Version returns the version of the buffer.
 func (b *Buffer) Version() int32 {
 	return b.version
@@ -166,6 +226,48 @@ func (b *Buffer) Checksum() []byte {
 	return b.checksum
 }
 
+// SetChecksum sets the checksum of the file on disk the buffer content is compared against.
+func (b *Buffer) SetChecksum(checksum []byte) {
+	b.checksum = checksum
+	b.saved = rope{}
+	b.onDisk = true
+	b.refreshDirty()
+}
+
+// DiskChecksum returns the sha256 checksum of the file the buffer represents.
+func (b *Buffer) DiskChecksum() ([]byte, error) {
+	file, err := readFile(b.name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err = io.Copy(hasher, file); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return hasher.Sum(nil), nil
+}
+
+// ModifiedOnDisk returns whether the file on disk differs from the last loaded or saved version of the buffer.
+func (b *Buffer) ModifiedOnDisk() (bool, error) {
+	if !b.onDisk {
+		return false, nil
+	}
+	if b.stat != nil {
+		stat, err := os.Stat(b.name)
+		if err != nil {
+			return false, err
+		}
+		return stat.Size() != b.stat.Size() || !stat.ModTime().Equal(b.stat.ModTime()), nil
+	}
+	checksum, err := b.DiskChecksum()
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(b.checksum, checksum), nil
+}
+
 // Dirty returns whether the buffer has unsaved changes.
 func (b *Buffer) Dirty() bool {
 	if !b.onDisk {
@@ -174,40 +276,107 @@ func (b *Buffer) Dirty() bool {
 	return b.dirty
 }
 
-// Save saves the buffer to the file it represents.
+// TrailingWhitespace returns the ranges of trailing spaces and tabs of all lines.
+func (b *Buffer) TrailingWhitespace() []Range {
+	var ranges []Range
+	b.lines.walk(0, func(i int, line Line) bool {
+		data := line.Bytes()
+		trimmed := bytes.TrimRight(data, " \t")
+		if len(trimmed) < len(data) {
+			ranges = append(ranges, Range{
+				Start: Position{Row: i, Col: utf8.RuneCount(trimmed)},
+				End:   Position{Row: i, Col: line.Len()},
+			})
+		}
+		return true
+	})
+	return ranges
+}
+
+// HasFinalNewline returns whether the buffer ends with a line ending. Empty buffers count as having one.
+func (b *Buffer) HasFinalNewline() bool {
+	return b.lines.line(b.lines.len()-1).Len() == 0
+}
+
+// normalize applies the save options to the buffer content.
+func (b *Buffer) normalize() {
+	if b.saveOptions.TrimTrailingWhitespace {
+		ranges := b.TrailingWhitespace()
+		for i := len(ranges) - 1; i >= 0; i-- {
+			r := ranges[i]
+			b.DeleteRange(r.Start.Row, r.Start.Col, r.End.Row, r.End.Col)
+		}
+	}
+	if b.saveOptions.InsertFinalNewline && !b.HasFinalNewline() {
+		row := b.lines.len() - 1
+		b.InsertNewLine(row, b.lines.line(row).Len())
+	}
+}
+
+// Save applies the save options and saves the buffer to the file it represents.
+// It returns ErrModifiedOnDisk if the file changed on disk since it was loaded or saved.
 func (b *Buffer) Save() error {
-	file, err := writeFile(b.name)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+	modified, err := b.ModifiedOnDisk()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check file: %w", err)
 	}
+	if modified {
+		return ErrModifiedOnDisk
+	}
+
+	return b.Overwrite()
+}
+
+// Overwrite applies the save options and saves the buffer to the file it represents even if the file changed on disk.
+func (b *Buffer) Overwrite() error {
+	b.normalize()
 
 	hasher := sha256.New()
 	fileEncoding := b.Encoding()
 	eol := b.lineEnding.Bytes()
+	last := b.lines.len() - 1
+
+	err := xos.WriteFile(b.name, defaultFileMode, func(file io.Writer) error {
+		tw := transform.NewWriter(io.MultiWriter(hasher, file), fileEncoding.NewEncoder())
+		w := bufio.NewWriter(tw)
+
+		var err error
+		b.lines.walk(0, func(i int, line Line) bool {
+			if len(line.data) > 0 {
+				if _, err = w.Write(line.data); err != nil {
+					err = fmt.Errorf("error writing line: %w", err)
+					return false
+				}
+			}
 
-	w := bufio.NewWriter(transform.NewWriter(io.MultiWriter(hasher, file), fileEncoding.NewEncoder()))
-	defer func() {
-		w.Flush()
-		file.Sync()
-	}()
-
-	for i, line := range b.lines {
-		if line.Len() > 0 {
-			if _, err = w.Write(line.data); err != nil {
-				return fmt.Errorf("error writing line: %w", err)
+			if i < last {
+				if _, err = w.Write(eol); err != nil {
+					err = fmt.Errorf("error writing line ending: %w", err)
+					return false
+				}
 			}
+			return true
+		})
+		if err != nil {
+			return err
 		}
 
-		if i < len(b.lines)-1 {
-			if _, err = w.Write(eol); err != nil {
-				return fmt.Errorf("error writing line ending: %w", err)
-			}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("error writing file: %w", err)
+		}
+		if err := tw.Close(); err != nil {
+			return fmt.Errorf("error writing file: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	b.dirty = false
 	b.onDisk = true
 	b.checksum = hasher.Sum(nil)
+	b.setSaved()
 
 	return nil
 }
@@ -237,48 +406,64 @@ func (b *Buffer) Delete() error {
 
 // ByteIndex returns the byte index in the buffer for the given row and col.
 func (b *Buffer) ByteIndex(row int, col int) int {
-	var n int
-	for i, line := range b.lines {
-		if i == row {
-			return n + len(line.CutEnd(col).Bytes())
-		}
-		n += len(line.Bytes()) + 1
+	if row >= b.lines.len() {
+		return b.lines.offset(row)
 	}
-	return n
+	return b.lines.offset(row) + len(b.lines.line(row).CutEnd(col).Bytes())
 }
 
 // Position returns the row and column for the given byte index.
 func (b *Buffer) Position(index int) Position {
-	var n int
-	for i, line := range b.lines {
-		if n+len(line.Bytes()) >= index {
-			return Position{Row: i, Col: index - n}
-		}
-		n += len(line.Bytes()) + 1
+	row, start := b.lines.row(index)
+	line := b.lines.line(row)
+	if index-start > len(line.data) {
+		return Position{Row: row, Col: line.Len()}
 	}
-	return Position{Row: len(b.lines) - 1, Col: b.lines[len(b.lines)-1].Len()}
+	return Position{Row: row, Col: index - start}
 }
 
 // Bytes returns the buffer as a byte slice. This uses \n as the line ending.
+// The result is cached until the buffer changes and must not be modified.
 func (b *Buffer) Bytes() []byte {
-	var bs []byte
-	for i, line := range b.lines {
-		bs = append(bs, line.Bytes()...)
-		if i < len(b.lines)-1 {
+	b.dataMu.Lock()
+	defer b.dataMu.Unlock()
+
+	if b.data != nil && b.dataRoot == b.lines.root {
+		return b.data
+	}
+
+	last := b.lines.len() - 1
+	bs := make([]byte, 0, b.lines.bytes()+last)
+	b.lines.walk(0, func(i int, line Line) bool {
+		bs = append(bs, line.data...)
+		if i < last {
 			bs = append(bs, '\n')
 		}
-	}
+		return true
+	})
+	b.data = bs
+	b.dataRoot = b.lines.root
 	return bs
 }
 
+// Chunk returns the bytes of Bytes from the byte index to the end of the line, or the line ending if the index points at it.
+// It returns nil at the end of the buffer, this allows reading the buffer without building the whole content.
+func (b *Buffer) Chunk(index int) []byte {
+	return b.lines.chunk(index)
+}
+
+// Reader returns a reader for the content of the buffer at the time of the call. This uses \n as the line ending.
+func (b *Buffer) Reader() io.Reader {
+	return &reader{lines: b.lines}
+}
+
 func (b *Buffer) BytesRange(start Position, end Position) []byte {
 	if start.Row == end.Row {
-		return b.lines[start.Row].CutRange(start.Col, end.Col).Bytes()
+		return b.lines.line(start.Row).CutRange(start.Col, end.Col).Bytes()
 	}
 
 	var bs []byte
-	for i := start.Row; i <= end.Row; i++ {
-		line := b.lines[i]
+	b.lines.walk(start.Row, func(i int, line Line) bool {
 		if i == start.Row {
 			line = line.CutStart(start.Col)
 		}
@@ -289,7 +474,8 @@ func (b *Buffer) BytesRange(start Position, end Position) []byte {
 		if i < end.Row {
 			bs = append(bs, '\n')
 		}
-	}
+		return i < end.Row
+	})
 
 	return bs
 }
@@ -299,65 +485,88 @@ func (b *Buffer) String() string {
 	return string(b.Bytes())
 }
 
+// setSaved remembers the content of the buffer as the content of the file on disk.
+func (b *Buffer) setSaved() {
+	b.saved = b.lines
+	b.savedEncoding = b.encoding
+	b.savedLineEnding = b.lineEnding
+}
+
 func (b *Buffer) refreshDirty() {
-	hasher := sha256.New()
+	// comparing with the saved lines skips all lines an edit didn't touch
+	if b.saved.root != nil && b.encoding == b.savedEncoding && b.lineEnding == b.savedLineEnding {
+		b.dirty = !b.lines.equal(b.saved)
+		return
+	}
+
 	fileEncoding := b.Encoding()
 	eol := b.lineEnding.Bytes()
+	last := b.lines.len() - 1
 
+	hasher := sha256.New()
 	w := transform.NewWriter(hasher, fileEncoding.NewEncoder())
 
-	for i, line := range b.lines {
+	b.lines.walk(0, func(i int, line Line) bool {
 		_, _ = w.Write(line.data)
 
-		if i < len(b.lines)-1 {
+		if i < last {
 			_, _ = w.Write(eol)
 		}
-	}
+		return true
+	})
+	_ = w.Close()
 
 	checksum := hasher.Sum(nil)
 
 	b.dirty = !bytes.Equal(b.checksum, checksum)
+	if !b.dirty {
+		b.setSaved()
+	}
 }
 
-// Index returns the row and column for the given byte index.
+// Index returns the row and rune column for the given byte index in Bytes.
 func (b *Buffer) Index(index int) (int, int) {
-	var n int
-	for i, line := range b.lines {
-		if n+len(line.Bytes()) >= index {
-			return i, index - n
-		}
-		n += len(line.Bytes()) + 1
+	row, start := b.lines.row(index)
+	line := b.lines.line(row)
+	if index-start > len(line.data) {
+		return row, line.Len()
 	}
-	return len(b.lines) - 1, b.lines[len(b.lines)-1].Len()
+	return row, utf8.RuneCount(line.data[:max(index-start, 0)])
 }
 
 // LinesLen returns the number of lines in the buffer.
 func (b *Buffer) LinesLen() int {
-	return len(b.lines)
+	return b.lines.len()
 }
 
 // Lines returns the lines in the buffer.
 func (b *Buffer) Lines() []Line {
-	return b.lines
+	lines := make([]Line, 0, b.lines.len())
+	b.lines.walk(0, func(_ int, line Line) bool {
+		lines = append(lines, line)
+		return true
+	})
+	return lines
 }
 
 // Len returns the rune length of the buffer. The actual byte length may be different due to line endings & encoding.
 func (b *Buffer) Len() int {
 	var n int
-	for _, line := range b.lines {
+	b.lines.walk(0, func(_ int, line Line) bool {
 		n += line.Len() + 1
-	}
+		return true
+	})
 	return n
 }
 
 // Line returns the line at the given row.
 func (b *Buffer) Line(row int) Line {
-	return b.lines[row]
+	return b.lines.line(row)
 }
 
 // LineLen returns the rune length of the line at the given row.
 func (b *Buffer) LineLen(row int) int {
-	return b.lines[row].Len()
+	return b.lines.line(row).Len()
 }
 
 // InsertNewLine inserts a new line at the given position.
@@ -367,10 +576,8 @@ func (b *Buffer) InsertNewLine(row int, col int) (int, int) {
 		b.refreshDirty()
 	}()
 
-	line := b.lines[row]
-	b.lines[row] = line.CutEnd(col)
-	b.lines = slices.Insert(b.lines, row+1, NewEmptyLine())
-	b.lines[row+1] = line.CutStart(col)
+	line := b.lines.line(row)
+	b.lines = b.lines.set(row, line.CutEnd(col)).insert(row+1, line.CutStart(col))
 
 	return row + 1, 0
 }
@@ -382,20 +589,24 @@ func (b *Buffer) Insert(row int, col int, text []byte) (int, int) {
 		b.refreshDirty()
 	}()
 
-	for _, r := range xbytes.Runes(text) {
-		if r == '\n' {
-			line := b.lines[row]
-			b.lines[row] = line.CutEnd(col)
-			b.lines = slices.Insert(b.lines, row+1, line.CutStart(col))
-			row++
-			col = 0
-			continue
-		}
-		b.lines[row] = b.lines[row].Insert(col, []byte(string(r))...)
-		col++
+	line := b.lines.line(row)
+	// the new lines keep referencing the text, so it must not change afterward
+	parts := bytes.Split(bytes.Clone(text), lineEndingLF)
+	if len(parts) == 1 {
+		b.lines = b.lines.set(row, line.Insert(col, parts[0]...))
+		return row, col + utf8.RuneCount(text)
 	}
 
-	return row, col
+	lines := make([]Line, len(parts))
+	lines[0] = line.CutEnd(col).Append(NewLine(parts[0]))
+	for i, part := range parts[1 : len(parts)-1] {
+		lines[i+1] = NewLine(slices.Clip(part))
+	}
+	last := parts[len(parts)-1]
+	lines[len(lines)-1] = NewLine(slices.Clip(last)).Append(line.CutStart(col))
+	b.lines = b.lines.set(row, lines[0]).insert(row+1, lines[1:]...)
+
+	return row + len(parts) - 1, utf8.RuneCount(last)
 }
 
 func (b *Buffer) Replace(fromRow int, fromCol int, toRow int, toCol int, text []byte) (int, int) {
@@ -418,8 +629,7 @@ func (b *Buffer) DuplicateLine(row int) int {
 		b.refreshDirty()
 	}()
 
-	line := b.lines[row]
-	b.lines = slices.Insert(b.lines, row+1, line.Copy())
+	b.lines = b.lines.insert(row+1, b.lines.line(row))
 
 	return row + 1
 }
@@ -431,15 +641,15 @@ func (b *Buffer) DeleteLine(row int) int {
 		b.refreshDirty()
 	}()
 
-	if row == 0 && len(b.lines) == 1 {
-		b.lines[0] = NewEmptyLine()
+	if row == 0 && b.lines.len() == 1 {
+		b.lines = b.lines.set(0, NewEmptyLine())
 		return row
 	}
-	if row == len(b.lines)-1 {
-		b.lines = b.lines[:row]
+	last := row == b.lines.len()-1
+	b.lines = b.lines.delete(row, row+1)
+	if last {
 		return max(row-1, 0)
 	}
-	b.lines = append(b.lines[:row], b.lines[row+1:]...)
 
 	return row
 }
@@ -456,19 +666,21 @@ func (b *Buffer) DeleteBefore(row int, col int, count int) (int, int) {
 
 	for i := 0; i < count; i++ {
 		if col == 0 {
-			line := b.lines[row]
-			col = b.lines[row-1].Len()
+			line := b.lines.line(row)
+			prev := b.lines.line(row - 1)
+			col = prev.Len()
 			if line.Len() > 0 {
-				b.lines[row-1] = b.lines[row-1].Append(line)
+				prev = prev.Append(line)
 			}
-			b.lines = append(b.lines[:row], b.lines[row+1:]...)
+			b.lines = b.lines.set(row-1, prev).delete(row, row+1)
 			row = max(row-1, 0)
 		} else if col > 0 {
-			b.lines[row] = b.lines[row].CutEnd(col - 1).Append(b.lines[row].CutStart(col))
+			line := b.lines.line(row)
+			b.lines = b.lines.set(row, line.CutEnd(col-1).Append(line.CutStart(col)))
 			col = max(col-1, 0)
 		} else if row > 0 {
 			row = max(col-1, 0)
-			col = b.lines[row].Len()
+			col = b.lines.line(row).Len()
 		}
 	}
 
@@ -477,7 +689,7 @@ func (b *Buffer) DeleteBefore(row int, col int, count int) (int, int) {
 
 // DeleteAfter deletes count characters after the current position.
 func (b *Buffer) DeleteAfter(row int, col int, count int) (int, int) {
-	if row == len(b.lines)-1 && col == b.lines[row].Len() {
+	if row == b.lines.len()-1 && col == b.lines.line(row).Len() {
 		return row, col
 	}
 	defer func() {
@@ -486,11 +698,11 @@ func (b *Buffer) DeleteAfter(row int, col int, count int) (int, int) {
 	}()
 
 	for i := 0; i < count; i++ {
-		if col == b.LineLen(row) {
-			b.lines[row] = b.lines[row].Append(b.lines[row+1])
-			b.lines = append(b.lines[:row+1], b.lines[row+2:]...)
-		} else if col < b.lines[row].Len() {
-			b.lines[row] = b.lines[row].CutEnd(col).Append(b.lines[row].CutStart(col + 1))
+		line := b.lines.line(row)
+		if col == line.Len() {
+			b.lines = b.lines.set(row, line.Append(b.lines.line(row+1))).delete(row+1, row+2)
+		} else if col < line.Len() {
+			b.lines = b.lines.set(row, line.CutEnd(col).Append(line.CutStart(col+1)))
 		}
 	}
 
@@ -504,26 +716,40 @@ func (b *Buffer) DeleteRange(startRow int, startCol int, endRow int, endCol int)
 		b.refreshDirty()
 	}()
 
-	if startRow == endRow {
-		b.lines[startRow] = b.lines[startRow].CutEnd(startCol).Append(b.lines[startRow].CutStart(endCol))
-	} else {
-		b.lines[startRow] = b.lines[startRow].CutEnd(startCol).Append(b.lines[endRow].CutStart(endCol))
-		b.lines = append(b.lines[:startRow+1], b.lines[endRow+1:]...)
+	b.lines = b.lines.set(startRow, b.lines.line(startRow).CutEnd(startCol).Append(b.lines.line(endRow).CutStart(endCol)))
+	if startRow != endRow {
+		b.lines = b.lines.delete(startRow+1, endRow+1)
 	}
 
 	return startRow, startCol
 }
 
-// RemoveTab removes a tab character at the front of the current line.
-func (b *Buffer) RemoveTab(row int, col int) (int, int) {
+// LeadingIndent returns the number of runes RemoveTab removes from the front of the line.
+// This is a single tab character or up to size spaces.
+func (b *Buffer) LeadingIndent(row int, size int) int {
+	line := b.lines.line(row)
+	if line.Len() > 0 && line.Rune(0) == '\t' {
+		return 1
+	}
+
+	var n int
+	for n < min(size, line.Len()) && line.Rune(n) == ' ' {
+		n++
+	}
+	return n
+}
+
+// RemoveTab removes a tab character or up to size spaces at the front of the current line.
+func (b *Buffer) RemoveTab(row int, col int, size int) (int, int) {
 	defer func() {
 		b.version++
 		b.refreshDirty()
 	}()
 
-	if b.lines[row].Rune(0) == '\t' {
-		b.lines[row] = b.lines[row].CutStart(1)
-		return row, col - 1
+	n := b.LeadingIndent(row, size)
+	if n > 0 {
+		b.lines = b.lines.set(row, b.lines.line(row).CutStart(n))
+		return row, max(col-n, 0)
 	}
 
 	return row, col
@@ -2,9 +2,12 @@ package buffer
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
 )
 
 func TestBuffer_BytesRange(t *testing.T) {
@@ -77,3 +80,107 @@ func TestBuffer_Replace(t *testing.T) {
 		assert.Equal(t, d.want, b.Bytes())
 	}
 }
+
+func TestBuffer_normalize(t *testing.T) {
+	data := []struct {
+		text    string
+		options SaveOptions
+		want    []byte
+	}{
+		{
+			text:    "a  \nb\t\n  \nc",
+			options: SaveOptions{TrimTrailingWhitespace: true},
+			want:    []byte("a\nb\n\nc"),
+		},
+		{
+			text:    "a \nb",
+			options: SaveOptions{InsertFinalNewline: true},
+			want:    []byte("a \nb\n"),
+		},
+		{
+			text:    "a\n",
+			options: SaveOptions{InsertFinalNewline: true},
+			want:    []byte("a\n"),
+		},
+		{
+			text:    "ä \t\nb ",
+			options: SaveOptions{TrimTrailingWhitespace: true, InsertFinalNewline: true},
+			want:    []byte("ä\nb\n"),
+		},
+	}
+
+	for _, d := range data {
+		b, err := New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", LineEndingLF, false)
+		assert.NoError(t, err)
+
+		b.SetSaveOptions(d.options)
+		b.normalize()
+		assert.Equal(t, d.want, b.Bytes())
+	}
+}
+
+func TestBuffer_Save(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "test.txt")
+	assert.NoError(t, os.WriteFile(name, []byte("a\nb"), 0600))
+
+	b, err := NewFromFile(name, "utf-8", LineEndingAuto)
+	assert.NoError(t, err)
+
+	b.Insert(0, 1, []byte("c"))
+	assert.NoError(t, b.Save())
+
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ac\nb"), data)
+	assert.False(t, b.Dirty())
+
+	info, err := os.Stat(name)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the temporary file is renamed
+	entries, err := os.ReadDir(filepath.Dir(name))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, os.WriteFile(name, []byte("x"), 0600))
+	b.Insert(0, 0, []byte("d"))
+	assert.ErrorIs(t, b.Save(), ErrModifiedOnDisk)
+
+	assert.NoError(t, b.Overwrite())
+	data, err = os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("dac\nb"), data)
+}
+
+func TestBuffer_ProtocolPosition(t *testing.T) {
+	b, err := New("test.txt", bytes.NewReader([]byte("a😀b\n世界")), "utf-8", LineEndingLF, false)
+	assert.NoError(t, err)
+
+	data := []struct {
+		name     string
+		encoding PositionEncoding
+		pos      Position
+		want     uint32
+	}{
+		{name: "utf-8 emoji", encoding: PositionEncodingUTF8, pos: Position{Row: 0, Col: 2}, want: 5},
+		{name: "utf-16 emoji", encoding: PositionEncodingUTF16, pos: Position{Row: 0, Col: 2}, want: 3},
+		{name: "utf-32 emoji", encoding: PositionEncodingUTF32, pos: Position{Row: 0, Col: 2}, want: 2},
+		{name: "utf-8 cjk", encoding: PositionEncodingUTF8, pos: Position{Row: 1, Col: 1}, want: 3},
+		{name: "utf-16 cjk", encoding: PositionEncodingUTF16, pos: Position{Row: 1, Col: 2}, want: 2},
+		{name: "utf-16 line end", encoding: PositionEncodingUTF16, pos: Position{Row: 0, Col: 3}, want: 4},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			p := b.ProtocolPosition(d.pos, d.encoding)
+			assert.Equal(t, uint32(d.pos.Row), p.Line)
+			assert.Equal(t, d.want, p.Character)
+			assert.Equal(t, d.pos, b.ParsePosition(p, d.encoding))
+		})
+	}
+
+	// offsets inside a character or after the end of the line are clamped
+	assert.Equal(t, Position{Row: 0, Col: 1}, b.ParsePosition(protocol.Position{Line: 0, Character: 2}, PositionEncodingUTF16))
+	assert.Equal(t, Position{Row: 1, Col: 2}, b.ParsePosition(protocol.Position{Line: 1, Character: 10}, PositionEncodingUTF8))
+}
@@ -18,7 +18,7 @@ func (b *Buffer) ToggleBlockComment(from Position, to Position, row int, col int
 	}()
 
 	if from.Row == to.Row {
-		line := b.lines[from.Row]
+		line := b.lines.line(from.Row)
 
 		if line.Len() == 0 {
 			return row, col
@@ -46,7 +46,7 @@ func (b *Buffer) ToggleBlockComment(from Position, to Position, row int, col int
 
 		if hasStartComment && hasEndComment {
 			log.Println("removing inline block comment")
-			b.lines[from.Row] = line.ReplaceRange(from.Col, from.Col+len(blockToken.Start)).ReplaceRange(to.Col-len(blockToken.Start), to.Col-len(blockToken.Start)+len(blockToken.End))
+			b.lines = b.lines.set(from.Row, line.ReplaceRange(from.Col, from.Col+len(blockToken.Start)).ReplaceRange(to.Col-len(blockToken.Start), to.Col-len(blockToken.Start)+len(blockToken.End)))
 			if col >= from.Col {
 				col -= len(blockToken.Start)
 			}
@@ -55,7 +55,7 @@ func (b *Buffer) ToggleBlockComment(from Position, to Position, row int, col int
 			}
 		} else {
 			log.Println("adding inline block comment")
-			b.lines[from.Row] = line.Insert(from.Col, []byte(blockToken.Start)...).Insert(to.Col+len(blockToken.Start), []byte(blockToken.End)...)
+			b.lines = b.lines.set(from.Row, line.Insert(from.Col, []byte(blockToken.Start)...).Insert(to.Col+len(blockToken.Start), []byte(blockToken.End)...))
 			if col >= from.Col {
 				col += len(blockToken.Start)
 			}
@@ -67,8 +67,8 @@ func (b *Buffer) ToggleBlockComment(from Position, to Position, row int, col int
 		return row, col
 	}
 
-	startLine := b.lines[from.Row]
-	endLine := b.lines[to.Row]
+	startLine := b.lines.line(from.Row)
+	endLine := b.lines.line(to.Row)
 
 	var hasStartComment bool
 	var hasEndComment bool
@@ -101,8 +101,8 @@ func (b *Buffer) ToggleBlockComment(from Position, to Position, row int, col int
 
 	if hasStartComment && hasEndComment {
 		log.Println("removing line block comment")
-		b.lines[from.Row] = startLine.ReplaceRange(from.Col, from.Col+len(blockToken.Start))
-		b.lines[to.Row] = endLine.ReplaceRange(to.Col-len(blockToken.End), to.Col)
+		b.lines = b.lines.set(from.Row, startLine.ReplaceRange(from.Col, from.Col+len(blockToken.Start)))
+		b.lines = b.lines.set(to.Row, endLine.ReplaceRange(to.Col-len(blockToken.End), to.Col))
 		if row == from.Row && col >= from.Col {
 			col -= len(blockToken.Start)
 		} else if row == to.Row && col >= to.Col {
@@ -110,8 +110,8 @@ func (b *Buffer) ToggleBlockComment(from Position, to Position, row int, col int
 		}
 	} else {
 		log.Println("adding line block comment")
-		b.lines[from.Row] = startLine.Insert(from.Col, []byte(blockToken.Start)...)
-		b.lines[to.Row] = endLine.Insert(to.Col, []byte(blockToken.End)...)
+		b.lines = b.lines.set(from.Row, startLine.Insert(from.Col, []byte(blockToken.Start)...))
+		b.lines = b.lines.set(to.Row, endLine.Insert(to.Col, []byte(blockToken.End)...))
 		if row == from.Row && col >= from.Col {
 			col += len(blockToken.Start)
 		} else if row == to.Row && col >= to.Col {
@@ -128,7 +128,7 @@ func (b *Buffer) ToggleLineComment(row int, col int, tokens []string) (int, int)
 		b.refreshDirty()
 	}()
 
-	line := b.lines[row]
+	line := b.lines.line(row)
 
 	if line.Len() == 0 {
 		return row, col
@@ -139,7 +139,7 @@ func (b *Buffer) ToggleLineComment(row int, col int, tokens []string) (int, int)
 			lineData := line.CutStart(i).Bytes()
 
 			if ok, token := hasPrefixes(lineData, tokens); ok {
-				b.lines[row] = line.CutEnd(i).Append(line.CutStart(i + len(token)))
+				b.lines = b.lines.set(row, line.CutEnd(i).Append(line.CutStart(i+len(token))))
 				if col >= i+len(token) {
 					col -= len(token)
 				}
@@ -147,7 +147,7 @@ func (b *Buffer) ToggleLineComment(row int, col int, tokens []string) (int, int)
 			}
 
 			token := tokens[0]
-			b.lines[row] = line.Insert(i, []byte(token)...)
+			b.lines = b.lines.set(row, line.Insert(i, []byte(token)...))
 			if col >= i {
 				col += len(token)
 			}
@@ -5,6 +5,8 @@ import (
 	"os"
 )
 
+const defaultFileMode os.FileMode = 0644
+
 func readFile(name string) (*os.File, error) {
 	file, err := os.Open(name)
 	if err != nil {
@@ -14,15 +16,6 @@ func readFile(name string) (*os.File, error) {
 	return file, nil
 }
 
-func writeFile(name string) (*os.File, error) {
-	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
-	if err != nil {
-		return nil, fmt.Errorf("error creating file: %w", err)
-	}
-
-	return file, nil
-}
-
 func deleteFile(name string) error {
 	return os.Remove(name)
 }
@@ -0,0 +1,193 @@
+package buffer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// maxLoadedPages is the number of pages of a lazily loaded file kept in memory.
+const maxLoadedPages = 1024
+
+// NewLazyFromFile creates a new buffer from a UTF-8 file on disk which reads its lines only when they are accessed.
+// The file is scanned once to find the line breaks, its content is neither decoded nor hashed.
+// Changes on disk are detected by the size and modification time of the file.
+func NewLazyFromFile(name string, lineEnding LineEnding) (*Buffer, error) {
+	var err error
+	name, err = filepath.Abs(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute file path: %w", err)
+	}
+	file, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("error getting file info: %w", err)
+	}
+
+	leaves, lineEnding, err := indexPages(file, lineEnding)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	b := &Buffer{
+		name:       name,
+		encoding:   "utf-8",
+		lineEnding: lineEnding,
+		lines:      rope{root: buildRope(leaves)},
+		onDisk:     true,
+		stat:       stat,
+		file:       file,
+	}
+	b.setSaved()
+	return b, nil
+}
+
+// indexPages reads the file and returns a leaf for every maxLeafLines lines of it.
+func indexPages(file *os.File, lineEnding LineEnding) ([]*ropeNode, LineEnding, error) {
+	pf := &pageFile{
+		r:     file,
+		pages: make(map[*page][]Line),
+	}
+
+	var (
+		leaves    []*ropeNode
+		offset    int64
+		lineStart int64
+		current   = &page{file: pf}
+		buf       = make([]byte, 1<<20)
+		last      byte
+	)
+	for {
+		n, err := file.Read(buf)
+		data := buf[:n]
+		for len(data) > 0 {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				offset += int64(len(data))
+				last = data[len(data)-1]
+				break
+			}
+
+			end := offset + int64(i)
+			if i > 0 {
+				last = data[i-1]
+			}
+			lineLen := end - lineStart
+			if lineLen > 0 && last == '\r' {
+				lineLen--
+				if lineEnding == LineEndingAuto {
+					lineEnding = LineEndingCRLF
+				}
+			} else if lineEnding == LineEndingAuto {
+				lineEnding = LineEndingLF
+			}
+			current.count++
+			current.bytes += int(lineLen)
+
+			if current.count == maxLeafLines {
+				current.size = int(end - current.offset)
+				leaves = append(leaves, current.leaf())
+				current = &page{file: pf, offset: end + 1}
+			}
+
+			lineStart = end + 1
+			offset = end + 1
+			data = data[i+1:]
+			last = '\n'
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, lineEnding, fmt.Errorf("error reading file: %w", err)
+		}
+	}
+
+	// the last line is not followed by a line break
+	lineLen := offset - lineStart
+	if lineLen > 0 && last == '\r' {
+		lineLen--
+	}
+	current.count++
+	current.bytes += int(lineLen)
+	current.size = int(offset - current.offset)
+	leaves = append(leaves, current.leaf())
+
+	return leaves, lineEnding, nil
+}
+
+// pageFile is the file lazily loaded pages are read from.
+// Only the most recently loaded pages are kept in memory.
+type pageFile struct {
+	r      io.ReaderAt
+	mu     sync.Mutex
+	pages  map[*page][]Line
+	loaded []*page
+}
+
+// page is a range of lines of a file.
+// size is the byte length of the range without the line break after the last line, bytes the length of the lines without line endings.
+type page struct {
+	file   *pageFile
+	offset int64
+	size   int
+	count  int
+	bytes  int
+}
+
+func (p *page) leaf() *ropeNode {
+	return &ropeNode{
+		page:  p,
+		count: p.count,
+		bytes: p.bytes,
+	}
+}
+
+// load returns the lines of the page, reading them from the file if they are not in memory.
+// Lines which can't be read are returned empty.
+func (p *page) load() []Line {
+	f := p.file
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if lines, ok := f.pages[p]; ok {
+		return lines
+	}
+
+	data := make([]byte, p.size)
+	n, _ := f.r.ReadAt(data, p.offset)
+	data = data[:n]
+
+	lines := make([]Line, 0, p.count)
+	for len(lines) < p.count {
+		line, rest, _ := bytes.Cut(data, lineEndingLF)
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		if len(line) > 0 {
+			lines = append(lines, NewLine(slices.Clip(line)))
+		} else {
+			lines = append(lines, NewEmptyLine())
+		}
+		data = rest
+	}
+
+	f.pages[p] = lines
+	f.loaded = append(f.loaded, p)
+	if len(f.loaded) > maxLoadedPages {
+		delete(f.pages, f.loaded[0])
+		f.loaded = f.loaded[1:]
+	}
+	return lines
+}
@@ -0,0 +1,61 @@
+package buffer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLazyFromFile(t *testing.T) {
+	var long strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&long, "line %d\r\n", i)
+	}
+
+	data := []struct {
+		name       string
+		text       string
+		lineEnding LineEnding
+	}{
+		{name: "empty", text: "", lineEnding: LineEndingAuto},
+		{name: "single line", text: "hello", lineEnding: LineEndingAuto},
+		{name: "final newline", text: "hello\nworld\n", lineEnding: LineEndingLF},
+		{name: "crlf", text: "hello\r\n\r\nworld\r", lineEnding: LineEndingCRLF},
+		{name: "multiple pages", text: long.String(), lineEnding: LineEndingCRLF},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "test.txt")
+			assert.NoError(t, os.WriteFile(name, []byte(d.text), 0644))
+
+			want, err := New(name, bytes.NewReader([]byte(d.text)), "utf-8", LineEndingAuto, true)
+			assert.NoError(t, err)
+
+			b, err := NewLazyFromFile(name, LineEndingAuto)
+			assert.NoError(t, err)
+			assert.True(t, b.Lazy())
+			assert.Equal(t, d.lineEnding, b.LineEnding())
+			assert.Equal(t, want.LinesLen(), b.LinesLen())
+			assert.Equal(t, want.Lines(), b.Lines())
+			assert.Equal(t, want.Bytes(), b.Bytes())
+			assert.False(t, b.Dirty())
+
+			modified, err := b.ModifiedOnDisk()
+			assert.NoError(t, err)
+			assert.False(t, modified)
+
+			assert.NoError(t, os.WriteFile(name, []byte(d.text+"more"), 0644))
+			modified, err = b.ModifiedOnDisk()
+			assert.NoError(t, err)
+			assert.True(t, modified)
+
+			assert.NoError(t, b.Close())
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package buffer
 
 import (
+	"slices"
+	"strings"
+	"unicode/utf16"
 	"unicode/utf8"
 
 	"go.gopad.dev/gopad/internal/xbytes"
@@ -31,6 +34,17 @@ func (l LineEnding) String() string {
 	return "Unknown"
 }
 
+// ParseLineEnding parses an EditorConfig end_of_line value. Unknown values return LineEndingAuto.
+func ParseLineEnding(s string) LineEnding {
+	switch strings.ToLower(s) {
+	case "lf":
+		return LineEndingLF
+	case "crlf":
+		return LineEndingCRLF
+	}
+	return LineEndingAuto
+}
+
 func (l LineEnding) Bytes() []byte {
 	switch l {
 	case LineEndingCRLF:
@@ -52,6 +66,8 @@ func NewEmptyLine() Line {
 	}
 }
 
+// Line is a line of a buffer without the line ending.
+// Lines are shared between snapshots of a buffer, so all methods return a new line instead of modifying the data in place.
 type Line struct {
 	data []byte
 }
@@ -115,6 +131,56 @@ func (l Line) RuneIndex(index int) int {
 	return xbytes.RuneIndex(l.data, index)
 }
 
+// EncodeCol returns the length of the first col runes in the encoding. Columns after the end of the line count as the line length.
+func (l Line) EncodeCol(col int, encoding PositionEncoding) int {
+	if encoding == PositionEncodingUTF32 {
+		return min(col, l.Len())
+	}
+
+	var n int
+	data := l.data
+	for range col {
+		if len(data) == 0 {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		n += encodedLen(r, size, encoding)
+	}
+	return n
+}
+
+// DecodeCol returns the rune column of the offset in the encoding.
+// Offsets inside a character return the column of the character and offsets after the end of the line return the line length.
+func (l Line) DecodeCol(offset int, encoding PositionEncoding) int {
+	if encoding == PositionEncodingUTF32 {
+		return min(offset, l.Len())
+	}
+
+	var n, col int
+	data := l.data
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		n += encodedLen(r, size, encoding)
+		if n > offset {
+			break
+		}
+		data = data[size:]
+		col++
+	}
+	return col
+}
+
+func encodedLen(r rune, size int, encoding PositionEncoding) int {
+	if encoding == PositionEncodingUTF8 {
+		return size
+	}
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+	return 1
+}
+
 func (l Line) CutStart(index int) Line {
 	l.data = xbytes.CutStart(l.data, index)
 	return l
@@ -131,29 +197,29 @@ func (l Line) CutRange(start int, end int) Line {
 }
 
 func (l Line) Append(line Line) Line {
-	l.data = xbytes.Append(l.data, line.data...)
+	l.data = xbytes.Append(slices.Clip(l.data), line.data...)
 	return l
 }
 
 func (l Line) AppendLines(lines ...Line) Line {
 	for _, line := range lines {
-		l.data = xbytes.Append(l.data, line.data...)
+		l.data = xbytes.Append(slices.Clip(l.data), line.data...)
 	}
 	return l
 }
 
 func (l Line) Insert(index int, b ...byte) Line {
-	l.data = xbytes.Insert(l.data, index, b...)
+	l.data = xbytes.Insert(slices.Clip(l.data), index, slices.Clip(b)...)
 	return l
 }
 
 func (l Line) Replace(index int, b ...byte) Line {
-	l.data = xbytes.Replace(l.data, index, b...)
+	l.data = xbytes.Replace(slices.Clip(l.data), index, slices.Clip(b)...)
 	return l
 }
 
 func (l Line) ReplaceRange(start int, end int, b ...byte) Line {
-	l.data = xbytes.ReplaceRange(l.data, start, end, b...)
+	l.data = xbytes.ReplaceRange(slices.Clip(l.data), start, end, slices.Clip(b)...)
 	return l
 }
 
@@ -6,6 +6,16 @@ import (
 	"go.lsp.dev/protocol"
 )
 
+// PositionEncoding is the unit the columns of language server positions are counted in.
+type PositionEncoding string
+
+const (
+	PositionEncodingUTF8  PositionEncoding = "utf-8"
+	PositionEncodingUTF16 PositionEncoding = "utf-16"
+	PositionEncodingUTF32 PositionEncoding = "utf-32"
+)
+
+// ParsePosition converts a language server position with the column counted in runes.
 func ParsePosition(p protocol.Position) Position {
 	return Position{
 		Row: int(p.Line),
@@ -77,6 +87,46 @@ func (p Position) Compare(start Position) int {
 	return 0
 }
 
+// ParsePosition converts a language server position to a rune position using the line data of the buffer.
+func (b *Buffer) ParsePosition(p protocol.Position, encoding PositionEncoding) Position {
+	row := int(p.Line)
+	if row >= b.LinesLen() {
+		return ParsePosition(p)
+	}
+	return Position{
+		Row: row,
+		Col: b.Line(row).DecodeCol(int(p.Character), encoding),
+	}
+}
+
+// ProtocolPosition converts a rune position to a language server position using the line data of the buffer.
+func (b *Buffer) ProtocolPosition(p Position, encoding PositionEncoding) protocol.Position {
+	if p.Row >= b.LinesLen() {
+		return p.ToProtocol()
+	}
+	return protocol.Position{
+		Line:      uint32(p.Row),
+		Character: uint32(b.Line(p.Row).EncodeCol(p.Col, encoding)),
+	}
+}
+
+// ParseRange converts a language server range to a rune range using the line data of the buffer.
+func (b *Buffer) ParseRange(r protocol.Range, encoding PositionEncoding) Range {
+	return Range{
+		Start: b.ParsePosition(r.Start, encoding),
+		End:   b.ParsePosition(r.End, encoding),
+	}
+}
+
+// ProtocolRange converts a rune range to a language server range using the line data of the buffer.
+func (b *Buffer) ProtocolRange(r Range, encoding PositionEncoding) protocol.Range {
+	return protocol.Range{
+		Start: b.ProtocolPosition(r.Start, encoding),
+		End:   b.ProtocolPosition(r.End, encoding),
+	}
+}
+
+// ParseRange converts a language server range with the columns counted in runes.
 func ParseRange(r protocol.Range) Range {
 	return Range{
 		Start: ParsePosition(r.Start),
@@ -0,0 +1,373 @@
+package buffer
+
+import (
+	"bytes"
+	"io"
+	"slices"
+)
+
+// maxLeafLines is the maximum number of lines stored in a single leaf of the rope.
+const maxLeafLines = 64
+
+// newRope creates a balanced rope from the lines.
+func newRope(lines []Line) rope {
+	leaves := make([]*ropeNode, 0, len(lines)/maxLeafLines+1)
+	for start := 0; start < len(lines); start += maxLeafLines {
+		end := min(start+maxLeafLines, len(lines))
+		leaves = append(leaves, newRopeLeaf(slices.Clip(lines[start:end])))
+	}
+	return rope{root: buildRope(leaves)}
+}
+
+func buildRope(leaves []*ropeNode) *ropeNode {
+	switch len(leaves) {
+	case 0:
+		return nil
+	case 1:
+		return leaves[0]
+	}
+	mid := len(leaves) / 2
+	return newRopeNode(buildRope(leaves[:mid]), buildRope(leaves[mid:]))
+}
+
+// rope is a persistent balanced tree of lines.
+// Nodes are never modified after they are created, so copying a rope is free and copies never see changes of each other.
+type rope struct {
+	root *ropeNode
+}
+
+// ropeNode is either a leaf holding lines or an inner node with two children.
+// Leaves of lazily loaded buffers hold a page of the file instead, its lines are read when they are accessed.
+// Every node caches the number of lines and bytes below it, so rows and byte offsets can be converted in O(log n).
+type ropeNode struct {
+	left   *ropeNode
+	right  *ropeNode
+	lines  []Line
+	page   *page
+	height int
+	count  int
+	bytes  int
+}
+
+func newRopeLeaf(lines []Line) *ropeNode {
+	if len(lines) == 0 {
+		return nil
+	}
+	n := &ropeNode{
+		lines: lines,
+		count: len(lines),
+	}
+	for _, line := range lines {
+		n.bytes += len(line.data)
+	}
+	return n
+}
+
+func newRopeNode(left *ropeNode, right *ropeNode) *ropeNode {
+	return &ropeNode{
+		left:   left,
+		right:  right,
+		height: max(left.height, right.height) + 1,
+		count:  left.count + right.count,
+		bytes:  left.bytes + right.bytes,
+	}
+}
+
+func (n *ropeNode) leaf() bool {
+	return n.left == nil
+}
+
+// leafLines returns the lines of a leaf, loading them from its page if needed.
+func (n *ropeNode) leafLines() []Line {
+	if n.page != nil {
+		return n.page.load()
+	}
+	return n.lines
+}
+
+// size returns the number of bytes of the node including a line ending after every line.
+func (n *ropeNode) size() int {
+	if n == nil {
+		return 0
+	}
+	return n.bytes + n.count
+}
+
+// balance creates a node from two trees whose heights differ by at most two.
+func balance(left *ropeNode, right *ropeNode) *ropeNode {
+	switch {
+	case left.height > right.height+1:
+		if left.left.height >= left.right.height {
+			return newRopeNode(left.left, newRopeNode(left.right, right))
+		}
+		return newRopeNode(newRopeNode(left.left, left.right.left), newRopeNode(left.right.right, right))
+	case right.height > left.height+1:
+		if right.right.height >= right.left.height {
+			return newRopeNode(newRopeNode(left, right.left), right.right)
+		}
+		return newRopeNode(newRopeNode(left, right.left.left), newRopeNode(right.left.right, right.right))
+	}
+	return newRopeNode(left, right)
+}
+
+// concat joins two trees, small neighbouring leaves are merged.
+func concat(left *ropeNode, right *ropeNode) *ropeNode {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case left.leaf() && right.leaf() && left.count+right.count <= maxLeafLines:
+		return newRopeLeaf(append(slices.Clip(left.leafLines()), right.leafLines()...))
+	case left.height > right.height+1:
+		return balance(left.left, concat(left.right, right))
+	case right.height > left.height+1:
+		return balance(concat(left, right.left), right.right)
+	}
+	return newRopeNode(left, right)
+}
+
+// split splits the tree into the lines before and from the row.
+func split(n *ropeNode, row int) (*ropeNode, *ropeNode) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case row <= 0:
+		return nil, n
+	case row >= n.count:
+		return n, nil
+	case n.leaf():
+		lines := n.leafLines()
+		return newRopeLeaf(slices.Clip(lines[:row])), newRopeLeaf(slices.Clip(lines[row:]))
+	case row < n.left.count:
+		left, right := split(n.left, row)
+		return left, concat(right, n.right)
+	}
+	left, right := split(n.right, row-n.left.count)
+	return concat(n.left, left), right
+}
+
+// len returns the number of lines.
+func (r rope) len() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.count
+}
+
+// bytes returns the number of bytes of all lines without line endings.
+func (r rope) bytes() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.bytes
+}
+
+// line returns the line at the row.
+func (r rope) line(row int) Line {
+	n := r.root
+	for !n.leaf() {
+		if row < n.left.count {
+			n = n.left
+			continue
+		}
+		row -= n.left.count
+		n = n.right
+	}
+	return n.leafLines()[row]
+}
+
+// set returns a rope with the line at the row replaced.
+func (r rope) set(row int, line Line) rope {
+	return rope{root: setLine(r.root, row, line)}
+}
+
+func setLine(n *ropeNode, row int, line Line) *ropeNode {
+	if n.leaf() {
+		lines := slices.Clone(n.leafLines())
+		lines[row] = line
+		return newRopeLeaf(lines)
+	}
+	if row < n.left.count {
+		return newRopeNode(setLine(n.left, row, line), n.right)
+	}
+	return newRopeNode(n.left, setLine(n.right, row-n.left.count, line))
+}
+
+// insert returns a rope with the lines inserted before the row.
+func (r rope) insert(row int, lines ...Line) rope {
+	left, right := split(r.root, row)
+	return rope{root: concat(concat(left, newRope(lines).root), right)}
+}
+
+// delete returns a rope without the lines from start to end (exclusive).
+func (r rope) delete(start int, end int) rope {
+	left, rest := split(r.root, start)
+	_, right := split(rest, end-start)
+	return rope{root: concat(left, right)}
+}
+
+// offset returns the byte offset of the start of the row if every line is followed by a line ending of one byte.
+func (r rope) offset(row int) int {
+	var offset int
+	n := r.root
+	for n != nil && !n.leaf() {
+		if row < n.left.count {
+			n = n.left
+			continue
+		}
+		offset += n.left.size()
+		row -= n.left.count
+		n = n.right
+	}
+	if n == nil {
+		return offset
+	}
+	lines := n.leafLines()
+	for _, line := range lines[:min(row, len(lines))] {
+		offset += len(line.data) + 1
+	}
+	return offset
+}
+
+// row returns the row containing the byte offset and the byte offset of the start of the row.
+// The line ending belongs to the line it ends, offsets after the last line return the last row.
+func (r rope) row(offset int) (int, int) {
+	var row, start int
+	n := r.root
+	for !n.leaf() {
+		if offset-start < n.left.size() {
+			n = n.left
+			continue
+		}
+		start += n.left.size()
+		row += n.left.count
+		n = n.right
+	}
+	lines := n.leafLines()
+	for i, line := range lines {
+		if offset-start <= len(line.data) || i == len(lines)-1 {
+			return row + i, start
+		}
+		start += len(line.data) + 1
+	}
+	return row, start
+}
+
+// walk calls fn for every line starting at the row until fn returns false.
+func (r rope) walk(row int, fn func(row int, line Line) bool) {
+	walkLines(r.root, row, 0, fn)
+}
+
+func walkLines(n *ropeNode, from int, offset int, fn func(row int, line Line) bool) bool {
+	if n == nil || from >= offset+n.count {
+		return true
+	}
+	if n.leaf() {
+		lines := n.leafLines()
+		for i := max(from-offset, 0); i < len(lines); i++ {
+			if !fn(offset+i, lines[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return walkLines(n.left, from, offset, fn) && walkLines(n.right, from, offset+n.left.count, fn)
+}
+
+// chunk returns the bytes from the offset to the end of its line, or the line ending if the offset points at it.
+func (r rope) chunk(offset int) []byte {
+	if offset < 0 || offset >= r.bytes()+r.len()-1 {
+		return nil
+	}
+	row, start := r.row(offset)
+	data := r.line(row).data
+	if offset-start == len(data) {
+		return lineEndingLF
+	}
+	return data[offset-start:]
+}
+
+// reader reads the lines of a rope separated by \n.
+type reader struct {
+	lines  rope
+	offset int
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		chunk := r.lines.chunk(r.offset)
+		if len(chunk) == 0 {
+			break
+		}
+		c := copy(p[n:], chunk)
+		n += c
+		r.offset += c
+	}
+	if n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// equal returns whether both ropes contain the same lines.
+// Subtrees shared by both ropes at the same row are skipped, so comparing a rope with an edited copy of it only compares the edited lines.
+func (r rope) equal(other rope) bool {
+	if r.root == other.root {
+		return true
+	}
+	if r.len() != other.len() || r.bytes() != other.bytes() {
+		return false
+	}
+
+	a := ropeIterator{stack: []*ropeNode{r.root}}
+	b := ropeIterator{stack: []*ropeNode{other.root}}
+	for len(a.stack) > 0 && len(b.stack) > 0 {
+		x := a.stack[len(a.stack)-1]
+		y := b.stack[len(b.stack)-1]
+		switch {
+		case x == y && a.index == 0 && b.index == 0:
+			a.pop()
+			b.pop()
+		case !x.leaf() && (y.leaf() || x.count >= y.count):
+			a.expand()
+		case !y.leaf():
+			b.expand()
+		default:
+			xLines := x.leafLines()
+			yLines := y.leafLines()
+			for a.index < len(xLines) && b.index < len(yLines) {
+				if !bytes.Equal(xLines[a.index].data, yLines[b.index].data) {
+					return false
+				}
+				a.index++
+				b.index++
+			}
+			if a.index == len(xLines) {
+				a.pop()
+			}
+			if b.index == len(yLines) {
+				b.pop()
+			}
+		}
+	}
+	return len(a.stack) == 0 && len(b.stack) == 0
+}
+
+// ropeIterator walks the nodes of a rope from the first to the last line.
+// index is the next line of the leaf on top of the stack.
+type ropeIterator struct {
+	stack []*ropeNode
+	index int
+}
+
+func (i *ropeIterator) pop() {
+	i.stack = i.stack[:len(i.stack)-1]
+	i.index = 0
+}
+
+func (i *ropeIterator) expand() {
+	n := i.stack[len(i.stack)-1]
+	i.stack = append(i.stack[:len(i.stack)-1], n.right, n.left)
+}
@@ -0,0 +1,180 @@
+package buffer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLines(n int) []Line {
+	lines := make([]Line, n)
+	for i := range lines {
+		lines[i] = NewLine([]byte(fmt.Sprintf("line %d", i)))
+	}
+	return lines
+}
+
+func ropeLines(r rope) []Line {
+	var lines []Line
+	r.walk(0, func(_ int, line Line) bool {
+		lines = append(lines, line)
+		return true
+	})
+	return lines
+}
+
+func checkRope(t *testing.T, n *ropeNode) {
+	if n == nil || n.leaf() {
+		return
+	}
+	assert.LessOrEqual(t, n.left.height-n.right.height, 1)
+	assert.LessOrEqual(t, n.right.height-n.left.height, 1)
+	assert.Equal(t, n.left.count+n.right.count, n.count)
+	assert.Equal(t, n.left.bytes+n.right.bytes, n.bytes)
+	checkRope(t, n.left)
+	checkRope(t, n.right)
+}
+
+func TestRope(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	want := testLines(1000)
+	r := newRope(want)
+	for i := 0; i < 500; i++ {
+		switch row := rnd.Intn(len(want)); rnd.Intn(3) {
+		case 0:
+			lines := testLines(rnd.Intn(100) + 1)
+			r = r.insert(row, lines...)
+			want = append(want[:row:row], append(lines, want[row:]...)...)
+		case 1:
+			end := min(row+rnd.Intn(100)+1, len(want))
+			r = r.delete(row, end)
+			want = append(want[:row:row], want[end:]...)
+		case 2:
+			line := NewLine([]byte(fmt.Sprintf("set %d", i)))
+			r = r.set(row, line)
+			want[row] = line
+		}
+		if len(want) == 0 {
+			want = testLines(10)
+			r = newRope(want)
+		}
+	}
+
+	checkRope(t, r.root)
+	assert.Equal(t, want, ropeLines(r))
+	assert.Equal(t, len(want), r.len())
+
+	var offset int
+	for i, line := range want {
+		assert.Equal(t, line, r.line(i))
+		assert.Equal(t, offset, r.offset(i))
+		row, start := r.row(offset + len(line.data))
+		assert.Equal(t, i, row)
+		assert.Equal(t, offset, start)
+		offset += len(line.data) + 1
+	}
+}
+
+func TestRope_equal(t *testing.T) {
+	r := newRope(testLines(1000))
+
+	data := []struct {
+		name  string
+		other rope
+		want  bool
+	}{
+		{name: "same", other: r, want: true},
+		{name: "rebuilt", other: newRope(testLines(1000)), want: true},
+		{name: "set same line", other: r.set(500, r.line(500)), want: true},
+		{name: "insert and delete", other: r.insert(10, testLines(100)...).delete(10, 110), want: true},
+		{name: "set", other: r.set(500, NewLine([]byte("line x00"))), want: false},
+		{name: "delete", other: r.delete(999, 1000), want: false},
+		{name: "swap", other: r.set(1, r.line(2)).set(2, r.line(1)), want: false},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			assert.Equal(t, d.want, r.equal(d.other))
+			assert.Equal(t, d.want, d.other.equal(r))
+		})
+	}
+}
+
+func TestBuffer_Dirty(t *testing.T) {
+	b, err := New("test.txt", bytes.NewReader([]byte("hello\nworld")), "utf-8", LineEndingLF, true)
+	assert.NoError(t, err)
+	assert.False(t, b.Dirty())
+
+	b.Insert(0, 5, []byte("X"))
+	assert.True(t, b.Dirty())
+
+	b.DeleteRange(0, 5, 0, 6)
+	assert.False(t, b.Dirty())
+
+	b.SetLineEnding(LineEndingCRLF)
+	b.Insert(0, 0, []byte("X"))
+	b.DeleteRange(0, 0, 0, 1)
+	assert.True(t, b.Dirty())
+}
+
+func TestBuffer_Copy(t *testing.T) {
+	b, err := New("test.txt", bytes.NewReader([]byte("hello\nworld")), "utf-8", LineEndingLF, false)
+	assert.NoError(t, err)
+
+	c := b.Copy()
+	b.Insert(0, 5, []byte("X\nX"))
+	b.DeleteRange(1, 1, 2, 1)
+
+	assert.Equal(t, []byte("helloX\nXorld"), b.Bytes())
+	assert.Equal(t, []byte("hello\nworld"), c.Bytes())
+}
+
+func TestBuffer_Position(t *testing.T) {
+	b, err := New("test.txt", bytes.NewReader([]byte("ä\n\nhello world\n")), "utf-8", LineEndingLF, false)
+	assert.NoError(t, err)
+
+	data := []struct {
+		index int
+		row   int
+		col   int
+	}{
+		{index: 0, row: 0, col: 0},
+		{index: 2, row: 0, col: 1},
+		{index: 3, row: 1, col: 0},
+		{index: 9, row: 2, col: 5},
+		{index: 16, row: 3, col: 0},
+		{index: 100, row: 3, col: 0},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("%d", d.index), func(t *testing.T) {
+			row, col := b.Index(d.index)
+			assert.Equal(t, d.row, row)
+			assert.Equal(t, d.col, col)
+			if d.index < len(b.Bytes()) {
+				assert.Equal(t, d.index, b.ByteIndex(row, col))
+			}
+		})
+	}
+}
+
+func TestBuffer_Reader(t *testing.T) {
+	b, err := New("test.txt", bytes.NewReader([]byte("a\r\n\r\nbc\r\n")), "utf-8", LineEndingAuto, false)
+	assert.NoError(t, err)
+
+	data, err := io.ReadAll(b.Reader())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a\n\nbc\n"), data)
+	assert.Equal(t, b.Bytes(), data)
+
+	var chunks []byte
+	for chunk := b.Chunk(0); chunk != nil; chunk = b.Chunk(len(chunks)) {
+		chunks = append(chunks, chunk...)
+	}
+	assert.Equal(t, data, chunks)
+}
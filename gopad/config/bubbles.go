@@ -8,6 +8,7 @@ import (
 	"go.gopad.dev/gopad/internal/bubbles/button"
 	"go.gopad.dev/gopad/internal/bubbles/cursor"
 	"go.gopad.dev/gopad/internal/bubbles/filepicker"
+	"go.gopad.dev/gopad/internal/bubbles/groupedlist"
 	"go.gopad.dev/gopad/internal/bubbles/help"
 	"go.gopad.dev/gopad/internal/bubbles/list"
 	"go.gopad.dev/gopad/internal/bubbles/notifications"
@@ -24,6 +25,20 @@ func NewList[T list.Item](items []T) list.Model[T] {
 	return l
 }
 
+func NewGroupedList(items []groupedlist.Item) groupedlist.Model {
+	l := groupedlist.New(items)
+	l.Styles = groupedlist.Styles{
+		Style:                Theme.UI.List.Style,
+		ItemStyle:            Theme.UI.List.ItemStyle,
+		ItemSelectedStyle:    Theme.UI.List.ItemSelectedStyle,
+		ItemDescriptionStyle: Theme.UI.List.ItemDescriptionStyle,
+	}
+	l.TextInput = NewTextInput()
+	l.TextInput.Cursor = NewCursor()
+	l.Keys = Keys.GroupedList()
+	return l
+}
+
 func NewOverlays() overlay.Model {
 	o := overlay.New()
 	o.Styles = Theme.UI.Overlay.Styles
@@ -99,6 +99,20 @@ func Load(name string, defaultConfigs embed.FS) error {
 	return nil
 }
 
+// SetTheme switches to the loaded theme with the name.
+func SetTheme(name string) error {
+	i := slices.IndexFunc(Themes, func(t RawThemeConfig) bool {
+		return t.Name == name
+	})
+	if i == -1 {
+		return fmt.Errorf("theme %s not found", name)
+	}
+
+	Gopad.Theme = name
+	Theme = Themes[i].Theme()
+	return nil
+}
+
 func loadThemes(name string, defaultConfigs embed.FS) ([]RawThemeConfig, error) {
 	themes := make([]RawThemeConfig, 0)
 
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
+
 	"go.gopad.dev/gopad/internal/bubbles/cursor"
 )
 
@@ -11,10 +14,12 @@ type GopadConfig struct {
 	Editor   EditorConfig   `toml:"editor"`
 	FileView FileViewConfig `toml:"file_view"`
 	FileTree FileTreeConfig `toml:"file_tree"`
+	Recovery RecoveryConfig `toml:"recovery"`
 }
 
 type EditorConfig struct {
 	TabSize                int          `toml:"tab_size"`
+	IndentStyle            IndentStyle  `toml:"indent_style"`
 	IndentSize             int          `toml:"indent_size"`
 	EndOfLine              string       `toml:"end_of_line"`
 	Charset                string       `toml:"charset"`
@@ -24,6 +29,50 @@ type EditorConfig struct {
 	Cursor                 CursorConfig `toml:"cursor"`
 }
 
+// WithOverrides returns the editor config with the set values of the language editor config applied.
+func (c EditorConfig) WithOverrides(o *LanguageEditorConfig) EditorConfig {
+	if o == nil {
+		return c
+	}
+	if o.TabSize != nil {
+		c.TabSize = *o.TabSize
+	}
+	if o.IndentStyle != nil {
+		c.IndentStyle = *o.IndentStyle
+	}
+	if o.IndentSize != nil {
+		c.IndentSize = *o.IndentSize
+	}
+	if o.EndOfLine != nil {
+		c.EndOfLine = *o.EndOfLine
+	}
+	if o.Charset != nil {
+		c.Charset = *o.Charset
+	}
+	if o.TrimTrailingWhitespace != nil {
+		c.TrimTrailingWhitespace = *o.TrimTrailingWhitespace
+	}
+	if o.InsertFinalNewline != nil {
+		c.InsertFinalNewline = *o.InsertFinalNewline
+	}
+	return c
+}
+
+// Indent returns the text inserted for one level of indentation.
+func (c EditorConfig) Indent() string {
+	if c.IndentStyle == IndentStyleSpace {
+		return strings.Repeat(" ", max(c.IndentSize, 1))
+	}
+	return "\t"
+}
+
+type IndentStyle string
+
+const (
+	IndentStyleTab   IndentStyle = "tab"
+	IndentStyleSpace IndentStyle = "space"
+)
+
 type CursorConfig struct {
 	Mode          cursor.Mode  `toml:"mode"`
 	BlinkInterval Duration     `toml:"blink_interval"`
@@ -45,12 +94,37 @@ func (d Duration) MarshalText() ([]byte, error) {
 	return []byte(time.Duration(d).String()), nil
 }
 
+// Size is a number of bytes written in a human readable form like 64MB.
+type Size uint64
+
+func (s *Size) UnmarshalText(text []byte) error {
+	size, err := humanize.ParseBytes(string(text))
+	if err != nil {
+		return err
+	}
+	*s = Size(size)
+	return nil
+}
+
+func (s Size) MarshalText() ([]byte, error) {
+	return []byte(humanize.Bytes(uint64(s))), nil
+}
+
 type FileViewConfig struct {
 	OpenFilesWrap   bool `toml:"open_files_wrap"`
 	ShowLineNumbers bool `toml:"show_line_numbers"`
 	WordWrap        bool `toml:"word_wrap"`
+	// LargeFileSize is the file size from which files are opened read-only and loaded lazily, 0 disables it
+	LargeFileSize Size `toml:"large_file_size"`
 }
 
 type FileTreeConfig struct {
-	Ignored []string `toml:"ignored"`
+	Ignored       []string `toml:"ignored"`
+	Watch         bool     `toml:"watch"`
+	WatchInterval Duration `toml:"watch_interval"`
+}
+
+type RecoveryConfig struct {
+	Enabled  bool     `toml:"enabled"`
+	Interval Duration `toml:"interval"`
 }
@@ -5,6 +5,7 @@ import (
 
 	"go.gopad.dev/gopad/internal/bubbles/button"
 	"go.gopad.dev/gopad/internal/bubbles/filepicker"
+	"go.gopad.dev/gopad/internal/bubbles/groupedlist"
 	"go.gopad.dev/gopad/internal/bubbles/help"
 	"go.gopad.dev/gopad/internal/bubbles/list"
 	"go.gopad.dev/gopad/internal/bubbles/textinput"
@@ -75,10 +76,20 @@ func (k KeyMap) List() list.KeyMap {
 	}
 }
 
+func (k KeyMap) GroupedList() groupedlist.KeyMap {
+	return groupedlist.KeyMap{
+		Up:    k.Up,
+		Down:  k.Down,
+		Start: k.Start,
+		End:   k.End,
+	}
+}
+
 type EditorKeyMap struct {
 	ToggleFileTree key.Binding
 	FocusFileTree  key.Binding
 	Search         key.Binding
+	FindInFiles    key.Binding
 	OpenOutline    key.Binding
 
 	RefreshSyntaxHighlight key.Binding
@@ -86,12 +97,14 @@ type EditorKeyMap struct {
 	DebugTreeSitterNodes   key.Binding
 
 	File         EditorFileKeyMap
+	Pane         EditorPaneKeyMap
 	Navigation   EditorNavigationKeyMap
 	Selection    EditorSelectionKeyMap
 	Edit         EditorEditKeyMap
 	Code         EditorCodeKeyMap
 	Autocomplete EditorAutocompleteKeyMap
 	Diagnostic   EditorDiagnosticKeyMap
+	Hover        EditorHoverKeyMap
 
 	FileTree  FileTreeKeyMap
 	SearchBar SearchbarKeyMap
@@ -105,6 +118,7 @@ func (k EditorKeyMap) HelpView() []help.KeyMapCategory {
 				k.ToggleFileTree,
 				k.FocusFileTree,
 				k.Search,
+				k.FindInFiles,
 				k.OpenOutline,
 				emptyKeyBind,
 				k.RefreshSyntaxHighlight,
@@ -113,12 +127,14 @@ func (k EditorKeyMap) HelpView() []help.KeyMapCategory {
 			},
 		},
 		k.File.HelpView(),
+		k.Pane.HelpView(),
 		k.Navigation.HelpView(),
 		k.Selection.HelpView(),
 		k.Edit.HelpView(),
 		k.Code.HelpView(),
 		k.Autocomplete.HelpView(),
 		k.Diagnostic.HelpView(),
+		k.Hover.HelpView(),
 		k.FileTree.HelpView(),
 		k.SearchBar.HelpView(),
 	}
@@ -139,6 +155,7 @@ func (k EditorKeyMap) TextInputKeyMap() textinput.KeyMap {
 }
 
 type EditorFileKeyMap struct {
+	QuickOpen  key.Binding
 	Open       key.Binding
 	OpenFolder key.Binding
 	Close      key.Binding
@@ -156,6 +173,7 @@ func (k EditorFileKeyMap) HelpView() help.KeyMapCategory {
 	return help.KeyMapCategory{
 		Category: "Editor File",
 		Keys: []key.Binding{
+			k.QuickOpen,
 			k.Open,
 			k.OpenFolder,
 			k.Close,
@@ -171,6 +189,35 @@ func (k EditorFileKeyMap) HelpView() help.KeyMapCategory {
 	}
 }
 
+type EditorPaneKeyMap struct {
+	SplitHorizontal key.Binding
+	SplitVertical   key.Binding
+	Close           key.Binding
+
+	Grow   key.Binding
+	Shrink key.Binding
+
+	Next key.Binding
+	Prev key.Binding
+}
+
+func (k EditorPaneKeyMap) HelpView() help.KeyMapCategory {
+	return help.KeyMapCategory{
+		Category: "Editor Pane",
+		Keys: []key.Binding{
+			k.SplitHorizontal,
+			k.SplitVertical,
+			k.Close,
+			emptyKeyBind,
+			k.Grow,
+			k.Shrink,
+			emptyKeyBind,
+			k.Next,
+			k.Prev,
+		},
+	}
+}
+
 type EditorNavigationKeyMap struct {
 	CharacterLeft  key.Binding
 	CharacterRight key.Binding
@@ -225,6 +272,11 @@ type EditorSelectionKeyMap struct {
 	SelectDown  key.Binding
 
 	SelectAll key.Binding
+
+	AddCursorAbove          key.Binding
+	AddCursorBelow          key.Binding
+	AddNextOccurrence       key.Binding
+	SplitSelectionIntoLines key.Binding
 }
 
 func (k EditorSelectionKeyMap) HelpView() help.KeyMapCategory {
@@ -237,6 +289,11 @@ func (k EditorSelectionKeyMap) HelpView() help.KeyMapCategory {
 			k.SelectDown,
 			emptyKeyBind,
 			k.SelectAll,
+			emptyKeyBind,
+			k.AddCursorAbove,
+			k.AddCursorBelow,
+			k.AddNextOccurrence,
+			k.SplitSelectionIntoLines,
 		},
 	}
 }
@@ -262,6 +319,7 @@ type EditorEditKeyMap struct {
 	DeleteLine    key.Binding
 
 	ToggleComment key.Binding
+	Reindent      key.Binding
 }
 
 func (k EditorEditKeyMap) HelpView() help.KeyMapCategory {
@@ -287,6 +345,7 @@ func (k EditorEditKeyMap) HelpView() help.KeyMapCategory {
 			k.DeleteLine,
 			emptyKeyBind,
 			k.ToggleComment,
+			k.Reindent,
 		},
 	}
 }
@@ -297,6 +356,9 @@ type EditorCodeKeyMap struct {
 	ShowTypeDefinition key.Binding
 	ShowImplementation key.Binding
 	ShowReferences     key.Binding
+	RenameSymbol       key.Binding
+	CodeActions        key.Binding
+	Format             key.Binding
 }
 
 func (k EditorCodeKeyMap) HelpView() help.KeyMapCategory {
@@ -308,6 +370,10 @@ func (k EditorCodeKeyMap) HelpView() help.KeyMapCategory {
 			k.ShowTypeDefinition,
 			k.ShowImplementation,
 			k.ShowReferences,
+			emptyKeyBind,
+			k.RenameSymbol,
+			k.CodeActions,
+			k.Format,
 		},
 	}
 }
@@ -348,6 +414,23 @@ func (k EditorDiagnosticKeyMap) HelpView() help.KeyMapCategory {
 	}
 }
 
+type EditorHoverKeyMap struct {
+	Show       key.Binding
+	ScrollUp   key.Binding
+	ScrollDown key.Binding
+}
+
+func (k EditorHoverKeyMap) HelpView() help.KeyMapCategory {
+	return help.KeyMapCategory{
+		Category: "Editor Hover",
+		Keys: []key.Binding{
+			k.Show,
+			k.ScrollUp,
+			k.ScrollDown,
+		},
+	}
+}
+
 type FileTreeKeyMap struct {
 	SelectPrev  key.Binding
 	SelectNext  key.Binding
@@ -379,6 +462,18 @@ type SearchbarKeyMap struct {
 
 	SelectResult key.Binding
 	Close        key.Binding
+
+	HistoryPrev key.Binding
+	HistoryNext key.Binding
+
+	ToggleRegex         key.Binding
+	ToggleCaseSensitive key.Binding
+	ToggleWholeWord     key.Binding
+
+	ToggleReplace key.Binding
+	SwitchInput   key.Binding
+	Replace       key.Binding
+	ReplaceAll    key.Binding
 }
 
 func (k SearchbarKeyMap) HelpView() help.KeyMapCategory {
@@ -390,6 +485,18 @@ func (k SearchbarKeyMap) HelpView() help.KeyMapCategory {
 			emptyKeyBind,
 			k.SelectResult,
 			k.Close,
+			emptyKeyBind,
+			k.HistoryPrev,
+			k.HistoryNext,
+			emptyKeyBind,
+			k.ToggleRegex,
+			k.ToggleCaseSensitive,
+			k.ToggleWholeWord,
+			emptyKeyBind,
+			k.ToggleReplace,
+			k.SwitchInput,
+			k.Replace,
+			k.ReplaceAll,
 		},
 	}
 }
@@ -482,6 +589,7 @@ type EditorKeyConfig struct {
 	ToggleFileTree string `toml:"toggle_file_tree"`
 	FocusFileTree  string `toml:"focus_file_tree"`
 	Search         string `toml:"search"`
+	FindInFiles    string `toml:"find_in_files"`
 	OpenOutline    string `toml:"open_outline"`
 
 	RefreshSyntaxHighlight string `toml:"refresh_syntax_highlight"`
@@ -489,6 +597,7 @@ type EditorKeyConfig struct {
 	DebugTreeSitterNodes   string `toml:"debug_tree_sitter_nodes"`
 
 	File struct {
+		QuickOpen  string `toml:"quick_open"`
 		Open       string `toml:"open"`
 		OpenFolder string `toml:"open_folder"`
 		Close      string `toml:"close"`
@@ -502,6 +611,18 @@ type EditorKeyConfig struct {
 		Prev string `toml:"prev"`
 	} `toml:"file"`
 
+	Pane struct {
+		SplitHorizontal string `toml:"split_horizontal"`
+		SplitVertical   string `toml:"split_vertical"`
+		Close           string `toml:"close"`
+
+		Grow   string `toml:"grow"`
+		Shrink string `toml:"shrink"`
+
+		Next string `toml:"next"`
+		Prev string `toml:"prev"`
+	} `toml:"pane"`
+
 	Navigation struct {
 		CharacterLeft  string `toml:"character_left"`
 		CharacterRight string `toml:"character_right"`
@@ -530,6 +651,11 @@ type EditorKeyConfig struct {
 		SelectDown  string `toml:"select_down"`
 
 		SelectAll string `toml:"select_all"`
+
+		AddCursorAbove          string `toml:"add_cursor_above"`
+		AddCursorBelow          string `toml:"add_cursor_below"`
+		AddNextOccurrence       string `toml:"add_next_occurrence"`
+		SplitSelectionIntoLines string `toml:"split_selection_into_lines"`
 	} `toml:"selection"`
 
 	Edit struct {
@@ -552,6 +678,7 @@ type EditorKeyConfig struct {
 		DeleteLine    string `toml:"delete_line"`
 
 		ToggleComment string `toml:"toggle_comment"`
+		Reindent      string `toml:"reindent"`
 	} `toml:"edit"`
 
 	Code struct {
@@ -560,6 +687,9 @@ type EditorKeyConfig struct {
 		ShowTypeDefinition string `toml:"show_type_definition"`
 		ShowImplementation string `toml:"show_implementation"`
 		ShowReferences     string `toml:"show_references"`
+		RenameSymbol       string `toml:"rename_symbol"`
+		CodeActions        string `toml:"code_actions"`
+		Format             string `toml:"format"`
 	} `toml:"code"`
 
 	Autocomplete struct {
@@ -575,6 +705,12 @@ type EditorKeyConfig struct {
 		Prev string `toml:"prev"`
 	} `toml:"diagnostic"`
 
+	Hover struct {
+		Show       string `toml:"show"`
+		ScrollUp   string `toml:"scroll_up"`
+		ScrollDown string `toml:"scroll_down"`
+	} `toml:"hover"`
+
 	FileTree  FileTreeKeyConfig  `toml:"file_tree"`
 	SearchBar SearchBarKeyConfig `toml:"search_bar"`
 }
@@ -593,6 +729,10 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 			key.WithKeys(k.Search),
 			key.WithHelp(k.Search, "search in file"),
 		),
+		FindInFiles: key.NewBinding(
+			key.WithKeys(k.FindInFiles),
+			key.WithHelp(k.FindInFiles, "find in files"),
+		),
 		OpenOutline: key.NewBinding(
 			key.WithKeys(k.OpenOutline),
 			key.WithHelp(k.OpenOutline, "open outline"),
@@ -612,6 +752,10 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 		),
 
 		File: EditorFileKeyMap{
+			QuickOpen: key.NewBinding(
+				key.WithKeys(k.File.QuickOpen),
+				key.WithHelp(k.File.QuickOpen, "quick open file"),
+			),
 			Open: key.NewBinding(
 				key.WithKeys(k.File.Open),
 				key.WithHelp(k.File.Open, "open file"),
@@ -651,6 +795,38 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 				key.WithHelp(k.File.Prev, "prev file"),
 			),
 		},
+		Pane: EditorPaneKeyMap{
+			SplitHorizontal: key.NewBinding(
+				key.WithKeys(k.Pane.SplitHorizontal),
+				key.WithHelp(k.Pane.SplitHorizontal, "split pane horizontally"),
+			),
+			SplitVertical: key.NewBinding(
+				key.WithKeys(k.Pane.SplitVertical),
+				key.WithHelp(k.Pane.SplitVertical, "split pane vertically"),
+			),
+			Close: key.NewBinding(
+				key.WithKeys(k.Pane.Close),
+				key.WithHelp(k.Pane.Close, "close pane"),
+			),
+
+			Grow: key.NewBinding(
+				key.WithKeys(k.Pane.Grow),
+				key.WithHelp(k.Pane.Grow, "grow pane"),
+			),
+			Shrink: key.NewBinding(
+				key.WithKeys(k.Pane.Shrink),
+				key.WithHelp(k.Pane.Shrink, "shrink pane"),
+			),
+
+			Next: key.NewBinding(
+				key.WithKeys(k.Pane.Next),
+				key.WithHelp(k.Pane.Next, "next pane"),
+			),
+			Prev: key.NewBinding(
+				key.WithKeys(k.Pane.Prev),
+				key.WithHelp(k.Pane.Prev, "prev pane"),
+			),
+		},
 		Navigation: EditorNavigationKeyMap{
 			CharacterLeft: key.NewBinding(
 				key.WithKeys(k.Navigation.CharacterLeft),
@@ -738,6 +914,23 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 				key.WithKeys(k.Selection.SelectAll),
 				key.WithHelp(k.Selection.SelectAll, "select all"),
 			),
+
+			AddCursorAbove: key.NewBinding(
+				key.WithKeys(k.Selection.AddCursorAbove),
+				key.WithHelp(k.Selection.AddCursorAbove, "add cursor above"),
+			),
+			AddCursorBelow: key.NewBinding(
+				key.WithKeys(k.Selection.AddCursorBelow),
+				key.WithHelp(k.Selection.AddCursorBelow, "add cursor below"),
+			),
+			AddNextOccurrence: key.NewBinding(
+				key.WithKeys(k.Selection.AddNextOccurrence),
+				key.WithHelp(k.Selection.AddNextOccurrence, "add next occurrence"),
+			),
+			SplitSelectionIntoLines: key.NewBinding(
+				key.WithKeys(k.Selection.SplitSelectionIntoLines),
+				key.WithHelp(k.Selection.SplitSelectionIntoLines, "split selection into lines"),
+			),
 		},
 		Edit: EditorEditKeyMap{
 			Tab: key.NewBinding(
@@ -804,6 +997,10 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 				key.WithKeys(k.Edit.ToggleComment),
 				key.WithHelp(k.Edit.ToggleComment, "toggle comment"),
 			),
+			Reindent: key.NewBinding(
+				key.WithKeys(k.Edit.Reindent),
+				key.WithHelp(k.Edit.Reindent, "reindent selection"),
+			),
 		},
 		Code: EditorCodeKeyMap{
 			ShowDeclaration: key.NewBinding(
@@ -826,6 +1023,18 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 				key.WithKeys(k.Code.ShowReferences),
 				key.WithHelp(k.Code.ShowReferences, "show references"),
 			),
+			RenameSymbol: key.NewBinding(
+				key.WithKeys(k.Code.RenameSymbol),
+				key.WithHelp(k.Code.RenameSymbol, "rename symbol"),
+			),
+			CodeActions: key.NewBinding(
+				key.WithKeys(k.Code.CodeActions),
+				key.WithHelp(k.Code.CodeActions, "code actions"),
+			),
+			Format: key.NewBinding(
+				key.WithKeys(k.Code.Format),
+				key.WithHelp(k.Code.Format, "format file or selection"),
+			),
 		},
 		Autocomplete: EditorAutocompleteKeyMap{
 			Show: key.NewBinding(
@@ -859,6 +1068,20 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 				key.WithHelp(k.Diagnostic.Prev, "show prev diagnostic"),
 			),
 		},
+		Hover: EditorHoverKeyMap{
+			Show: key.NewBinding(
+				key.WithKeys(k.Hover.Show),
+				key.WithHelp(k.Hover.Show, "show hover"),
+			),
+			ScrollUp: key.NewBinding(
+				key.WithKeys(k.Hover.ScrollUp),
+				key.WithHelp(k.Hover.ScrollUp, "scroll hover up"),
+			),
+			ScrollDown: key.NewBinding(
+				key.WithKeys(k.Hover.ScrollDown),
+				key.WithHelp(k.Hover.ScrollDown, "scroll hover down"),
+			),
+		},
 
 		FileTree: FileTreeKeyMap{
 			SelectPrev: key.NewBinding(
@@ -903,6 +1126,42 @@ func (k EditorKeyConfig) KeyMap() EditorKeyMap {
 				key.WithKeys(k.SearchBar.Close),
 				key.WithHelp(k.SearchBar.Close, "close search"),
 			),
+			HistoryPrev: key.NewBinding(
+				key.WithKeys(k.SearchBar.HistoryPrev),
+				key.WithHelp(k.SearchBar.HistoryPrev, "previous search"),
+			),
+			HistoryNext: key.NewBinding(
+				key.WithKeys(k.SearchBar.HistoryNext),
+				key.WithHelp(k.SearchBar.HistoryNext, "next search"),
+			),
+			ToggleRegex: key.NewBinding(
+				key.WithKeys(k.SearchBar.ToggleRegex),
+				key.WithHelp(k.SearchBar.ToggleRegex, "toggle regex"),
+			),
+			ToggleCaseSensitive: key.NewBinding(
+				key.WithKeys(k.SearchBar.ToggleCaseSensitive),
+				key.WithHelp(k.SearchBar.ToggleCaseSensitive, "toggle case sensitive"),
+			),
+			ToggleWholeWord: key.NewBinding(
+				key.WithKeys(k.SearchBar.ToggleWholeWord),
+				key.WithHelp(k.SearchBar.ToggleWholeWord, "toggle whole word"),
+			),
+			ToggleReplace: key.NewBinding(
+				key.WithKeys(k.SearchBar.ToggleReplace),
+				key.WithHelp(k.SearchBar.ToggleReplace, "toggle replace"),
+			),
+			SwitchInput: key.NewBinding(
+				key.WithKeys(k.SearchBar.SwitchInput),
+				key.WithHelp(k.SearchBar.SwitchInput, "switch search/replace"),
+			),
+			Replace: key.NewBinding(
+				key.WithKeys(k.SearchBar.Replace),
+				key.WithHelp(k.SearchBar.Replace, "replace"),
+			),
+			ReplaceAll: key.NewBinding(
+				key.WithKeys(k.SearchBar.ReplaceAll),
+				key.WithHelp(k.SearchBar.ReplaceAll, "replace all"),
+			),
 		},
 	}
 }
@@ -917,10 +1176,19 @@ type FileTreeKeyConfig struct {
 }
 
 type SearchBarKeyConfig struct {
-	SelectPrev   string `toml:"select_prev"`
-	SelectNext   string `toml:"select_next"`
-	SelectResult string `toml:"select_result"`
-	Close        string `toml:"close"`
+	SelectPrev          string `toml:"select_prev"`
+	SelectNext          string `toml:"select_next"`
+	SelectResult        string `toml:"select_result"`
+	Close               string `toml:"close"`
+	HistoryPrev         string `toml:"history_prev"`
+	HistoryNext         string `toml:"history_next"`
+	ToggleRegex         string `toml:"toggle_regex"`
+	ToggleCaseSensitive string `toml:"toggle_case_sensitive"`
+	ToggleWholeWord     string `toml:"toggle_whole_word"`
+	ToggleReplace       string `toml:"toggle_replace"`
+	SwitchInput         string `toml:"switch_input"`
+	Replace             string `toml:"replace"`
+	ReplaceAll          string `toml:"replace_all"`
 }
 
 type FilePickerKeyConfig struct {
@@ -45,8 +45,15 @@ type LanguageServerConfig struct {
 type LanguageServerFeature string
 
 const (
-	LanguageServerFeatureCompletion     LanguageServerFeature = "completion"
-	LanguageServerFeatureDiagnostics    LanguageServerFeature = "diagnostics"
-	LanguageServerFeatureInlayHints     LanguageServerFeature = "inlay_hints"
-	LanguageServerFeatureGoToDefinition LanguageServerFeature = "go_to_definition"
+	LanguageServerFeatureCompletion         LanguageServerFeature = "completion"
+	LanguageServerFeatureDiagnostics        LanguageServerFeature = "diagnostics"
+	LanguageServerFeatureInlayHints         LanguageServerFeature = "inlay_hints"
+	LanguageServerFeatureGoToDefinition     LanguageServerFeature = "go_to_definition"
+	LanguageServerFeatureGoToImplementation LanguageServerFeature = "go_to_implementation"
+	LanguageServerFeatureFindReferences     LanguageServerFeature = "find_references"
+	LanguageServerFeatureRename             LanguageServerFeature = "rename"
+	LanguageServerFeatureCodeActions        LanguageServerFeature = "code_actions"
+	LanguageServerFeatureHover              LanguageServerFeature = "hover"
+	LanguageServerFeatureSignatureHelp      LanguageServerFeature = "signature_help"
+	LanguageServerFeatureFormatting         LanguageServerFeature = "formatting"
 )
@@ -45,6 +45,7 @@ func (l LanguageConfigs) filter() LanguageConfigs {
 }
 
 type LanguageConfig struct {
+	LanguageID         string                     `toml:"language_id"`
 	AltNames           []string                   `toml:"alt_names"`
 	MIMETypes          []string                   `toml:"mime_types"`
 	FileTypes          []string                   `toml:"file_types"`
@@ -52,9 +53,30 @@ type LanguageConfig struct {
 	LineCommentTokens  []string                   `toml:"line_comment_tokens"`
 	BlockCommentTokens []buffer.BlockCommentToken `toml:"block_comment_tokens"`
 	AutoPairs          []LanguageAutoPairs        `toml:"auto_pairs"`
+	FormatOnSave       bool                       `toml:"format_on_save"`
+	Formatter          *FormatterConfig           `toml:"formatter"`
+	Editor             *LanguageEditorConfig      `toml:"editor"`
 	Grammar            *GrammarConfig             `toml:"grammar"`
 }
 
+// LanguageEditorConfig overrides the editor config for files of a language. Unset values fall back to the editor config.
+type LanguageEditorConfig struct {
+	TabSize                *int         `toml:"tab_size"`
+	IndentStyle            *IndentStyle `toml:"indent_style"`
+	IndentSize             *int         `toml:"indent_size"`
+	EndOfLine              *string      `toml:"end_of_line"`
+	Charset                *string      `toml:"charset"`
+	TrimTrailingWhitespace *bool        `toml:"trim_trailing_whitespace"`
+	InsertFinalNewline     *bool        `toml:"insert_final_newline"`
+}
+
+// FormatterConfig is an external formatter command which reads the file from stdin and writes the formatted file to stdout.
+// The placeholder {file} in the args is replaced with the file path.
+type FormatterConfig struct {
+	Command string   `toml:"command"`
+	Args    []string `toml:"args"`
+}
+
 type LanguageAutoPairs struct {
 	Open  string `toml:"open"`
 	Close string `toml:"close"`
@@ -104,14 +104,17 @@ type FileTreeStyles struct {
 }
 
 type SearchBarStyles struct {
-	Style       lipgloss.Style
-	ResultStyle lipgloss.Style
+	Style               lipgloss.Style
+	ResultStyle         lipgloss.Style
+	OptionStyle         lipgloss.Style
+	SelectedOptionStyle lipgloss.Style
 }
 
 type FileViewStyles struct {
-	Style       lipgloss.Style
-	EmptyStyle  lipgloss.Style
-	BorderStyle lipgloss.Style
+	Style            lipgloss.Style
+	EmptyStyle       lipgloss.Style
+	BorderStyle      lipgloss.Style
+	SplitBorderStyle lipgloss.Style
 
 	LineStyle       lipgloss.Style
 	LinePrefixStyle lipgloss.Style
@@ -123,6 +126,9 @@ type FileViewStyles struct {
 
 	SelectionStyle lipgloss.Style
 	InlayHintStyle lipgloss.Style
+
+	DiffInsertStyle lipgloss.Style
+	DiffDeleteStyle lipgloss.Style
 }
 
 type CodeBarStyles struct {
@@ -180,7 +186,8 @@ type DiagnosticStyles struct {
 }
 
 type DocumentationStyles struct {
-	Style lipgloss.Style
+	Style                lipgloss.Style
+	ActiveParameterStyle lipgloss.Style
 }
 
 type AutocompleteStyles struct {
@@ -58,13 +58,16 @@ func (c RawThemeConfig) Theme() ThemeConfig {
 				EntrySelectedUnfocusedStyle: c.UI.FileTree.SelectedEntryUnfocused.Style(colors),
 			},
 			SearchBar: SearchBarStyles{
-				Style:       lipgloss.NewStyle().Padding(0, 2),
-				ResultStyle: lipgloss.NewStyle().Padding(0, 1),
+				Style:               lipgloss.NewStyle().Padding(0, 2),
+				ResultStyle:         lipgloss.NewStyle().Padding(0, 1),
+				OptionStyle:         c.UI.Menu.Entry.Style(colors).Padding(0, 1),
+				SelectedOptionStyle: c.UI.Menu.SelectedEntry.Style(colors).Padding(0, 1),
 			},
 			FileView: FileViewStyles{
 				Style:                  c.UI.FileView.Style.Style(colors),
 				EmptyStyle:             c.UI.FileView.Empty.Style(colors).Align(lipgloss.Center, lipgloss.Center),
 				BorderStyle:            c.UI.FileView.Border.Style(colors).Border(lipgloss.NormalBorder(), false, false, false, true),
+				SplitBorderStyle:       c.UI.FileView.Border.Style(colors).Border(lipgloss.NormalBorder(), true, false, false, false),
 				LineStyle:              c.UI.FileView.Line.Style(colors),
 				LinePrefixStyle:        c.UI.FileView.LinePrefix.Style(colors).Padding(0, 1),
 				LineCharStyle:          c.UI.FileView.LineChar.Style(colors),
@@ -73,13 +76,16 @@ func (c RawThemeConfig) Theme() ThemeConfig {
 				CurrentLineCharStyle:   c.UI.FileView.CurrentLineChar.Style(colors),
 				SelectionStyle:         c.UI.FileView.Selection.Style(colors),
 				InlayHintStyle:         c.UI.FileView.InlayHint.Style(colors),
+				DiffInsertStyle:        c.UI.FileView.DiffInsert.Style(colors),
+				DiffDeleteStyle:        c.UI.FileView.DiffDelete.Style(colors),
 			},
 			CodeBar: CodeBarStyles{
 				Style: c.UI.CodeBar.Style.Style(colors).Padding(0, 1),
 			},
 
 			Documentation: DocumentationStyles{
-				Style: c.UI.Overlay.Style.Style(colors).Padding(0, 1),
+				Style:                c.UI.Overlay.Style.Style(colors).Padding(0, 1),
+				ActiveParameterStyle: c.UI.Overlay.Style.Style(colors).Bold(true).Underline(true),
 			},
 			Autocomplete: AutocompleteStyles{
 				Style:             c.UI.Overlay.Style.Style(colors).Padding(0, 1),
@@ -144,6 +150,7 @@ func (c RawThemeConfig) Theme() ThemeConfig {
 				ItemSelectedStyle: c.UI.Menu.SelectedEntry.Style(colors).Padding(0, 1),
 
 				ItemDescriptionStyle: lipgloss.NewStyle(),
+				ItemMatchStyle:       c.UI.Menu.Match.Style(colors),
 			},
 		},
 
@@ -275,6 +282,7 @@ type MenuUIConfig struct {
 	Entry                  Style `toml:"entry"`
 	SelectedEntry          Style `toml:"selected_entry"`
 	SelectedEntryUnfocused Style `toml:"selected_entry_unfocused"`
+	Match                  Style `toml:"match"`
 }
 
 type OverlayUIConfig struct {
@@ -309,6 +317,9 @@ type FileViewUIConfig struct {
 
 	Selection Style `toml:"selection"`
 	InlayHint Style `toml:"inlay_hint"`
+
+	DiffInsert Style `toml:"diff_insert"`
+	DiffDelete Style `toml:"diff_delete"`
 }
 
 type FilePickerUIConfig struct {
@@ -0,0 +1,149 @@
+package editor
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/editormsg"
+	"go.gopad.dev/gopad/gopad/recovery"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+)
+
+const defaultRecoveryInterval = 5 * time.Second
+
+type recoveryMsg struct{}
+
+func recoveryTick() tea.Cmd {
+	interval := time.Duration(config.Gopad.Recovery.Interval)
+	if interval <= 0 {
+		interval = defaultRecoveryInterval
+	}
+
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return recoveryMsg{}
+	})
+}
+
+// writeRecovery writes the recovery files of all files with unsaved changes which changed since the last run.
+// Recovery files of saved or closed files are removed.
+func (e *Editor) writeRecovery() tea.Cmd {
+	var (
+		writes  []recovery.File
+		removes []string
+	)
+	now := time.Now()
+	for _, f := range e.files {
+		if !f.Dirty() {
+			continue
+		}
+		if version, ok := e.recovered[f.Name()]; ok && version == f.Version() {
+			continue
+		}
+		e.recovered[f.Name()] = f.Version()
+		writes = append(writes, recovery.File{
+			Name:       f.Name(),
+			Version:    f.Version(),
+			Encoding:   f.Encoding(),
+			LineEnding: f.LineEnding(),
+			Time:       now,
+			Content:    f.Buffer().Bytes(),
+		})
+	}
+
+	for name := range e.recovered {
+		if f := e.FileByName(name); f == nil || !f.Dirty() {
+			delete(e.recovered, name)
+			removes = append(removes, name)
+		}
+	}
+
+	if len(writes) == 0 && len(removes) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		for _, f := range writes {
+			if err := recovery.Write(f); err != nil {
+				log.Printf("error writing recovery file for %s: %s\n", f.Name, err)
+			}
+		}
+		for _, name := range removes {
+			if err := recovery.Remove(name); err != nil {
+				log.Printf("error removing recovery file for %s: %s\n", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// DiscardRecovery removes all recovery files written by the editor, it is used when gopad is closed on purpose.
+func (e *Editor) DiscardRecovery() tea.Cmd {
+	var names []string
+	for name := range e.recovered {
+		names = append(names, name)
+	}
+	clear(e.recovered)
+
+	return func() tea.Msg {
+		for _, name := range names {
+			if err := recovery.Remove(name); err != nil {
+				log.Printf("error removing recovery file for %s: %s\n", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// recoverFile opens the file and replaces its content with the content of the recovery file.
+func (e *Editor) recoverFile(name string) tea.Cmd {
+	r, err := recovery.Load(name)
+	if err != nil {
+		return notifications.Addf("error while recovering file %s: %s", name, err)
+	}
+	b, err := r.Buffer()
+	if err != nil {
+		return notifications.Addf("error while recovering file %s: %s", name, err)
+	}
+
+	var cmds []tea.Cmd
+	if e.FileByName(name) == nil {
+		var cmd tea.Cmd
+		if _, err = os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			cmd, err = e.CreateFile(name)
+		} else {
+			cmd, err = e.OpenFile(name)
+		}
+		if err != nil {
+			return notifications.Addf("error while opening file %s: %s", name, err)
+		}
+		cmds = append(cmds, cmd)
+		e.addRecentFile(name)
+	}
+	e.SetFileByName(name)
+
+	f := e.File()
+	cmds = append(cmds,
+		f.SetContent(b),
+		notifications.Addf("file %s recovered", name),
+		editormsg.Focus(editormsg.ModelFile),
+	)
+
+	// the recovery file is kept until the file is saved
+	if config.Gopad.Recovery.Enabled {
+		e.recovered[name] = -1
+	} else {
+		cmds = append(cmds, func() tea.Msg {
+			if err := recovery.Remove(name); err != nil {
+				log.Printf("error removing recovery file for %s: %s\n", name, err)
+			}
+			return nil
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
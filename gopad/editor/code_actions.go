@@ -0,0 +1,162 @@
+package editor
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"go.gopad.dev/gopad/internal/bubbles/key"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/internal/bubbles/list"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/bubbles/textinput"
+)
+
+type codeActionItem struct {
+	ls.CodeAction
+}
+
+func (c codeActionItem) Title() string {
+	return c.CodeAction.Title
+}
+
+func (c codeActionItem) Description() string {
+	description := c.Server
+	if c.Kind != "" {
+		description = c.Kind + " - " + description
+	}
+	if c.Disabled != "" {
+		description += " (disabled: " + c.Disabled + ")"
+	}
+	return description
+}
+
+func (c codeActionItem) FilterValue() string {
+	return c.CodeAction.Title + " " + c.Kind
+}
+
+const CodeActionsOverlayID = "editor.code_actions"
+
+var _ overlay.Overlay = (*CodeActionsOverlay)(nil)
+
+func NewCodeActionsOverlay(f *file.File) CodeActionsOverlay {
+	l := config.NewList[codeActionItem](nil)
+	l.TextInput.Placeholder = "Search code actions..."
+	l.Focus()
+
+	return CodeActionsOverlay{
+		f: f,
+		l: l,
+	}
+}
+
+type CodeActionsOverlay struct {
+	f     *file.File
+	items []codeActionItem
+	l     list.Model[codeActionItem]
+}
+
+func (o CodeActionsOverlay) ID() string {
+	return CodeActionsOverlayID
+}
+
+func (o CodeActionsOverlay) Position() (lipgloss.Position, lipgloss.Position) {
+	return lipgloss.Center, lipgloss.Top
+}
+
+func (o CodeActionsOverlay) Margin() (int, int) {
+	return 0, 2
+}
+
+func (o CodeActionsOverlay) Title() string {
+	return "Code Actions"
+}
+
+func (o CodeActionsOverlay) Init() (overlay.Overlay, tea.Cmd) {
+	return o, tea.Sequence(
+		textinput.Blink,
+		o.f.GetCodeActions(),
+	)
+}
+
+func (o CodeActionsOverlay) apply() tea.Cmd {
+	item := o.l.Selected()
+	if item.Disabled != "" {
+		return notifications.Addf("Code action %q is disabled: %s", item.CodeAction.Title, item.Disabled)
+	}
+
+	cmds := []tea.Cmd{overlay.Close(CodeActionsOverlayID)}
+	if item.Edit != nil {
+		cmds = append(cmds, ls.ApplyWorkspaceEdit(*item.Edit, nil))
+	}
+	if item.Command != nil {
+		cmds = append(cmds, ls.ExecuteCommand(item.Server, *item.Command))
+	}
+
+	return tea.Sequence(cmds...)
+}
+
+func (o CodeActionsOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ls.UpdateCodeActionsMsg:
+		if msg.Name != o.f.Name() {
+			return o, nil
+		}
+		for _, action := range msg.Actions {
+			o.items = append(o.items, codeActionItem{CodeAction: action})
+		}
+		// preferred actions first, disabled actions last
+		slices.SortStableFunc(o.items, func(a, b codeActionItem) int {
+			if (a.Disabled == "") != (b.Disabled == "") {
+				if a.Disabled == "" {
+					return -1
+				}
+				return 1
+			}
+			if a.IsPreferred != b.IsPreferred {
+				if a.IsPreferred {
+					return -1
+				}
+				return 1
+			}
+			return 0
+		})
+		o.l.SetItems(o.items)
+		return o, nil
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, config.Keys.Cancel):
+			return o, overlay.Close(CodeActionsOverlayID)
+		case key.Matches(msg, config.Keys.OK):
+			if len(o.l.Items()) == 0 {
+				return o, nil
+			}
+			return o, o.apply()
+		}
+	}
+
+	var cmd tea.Cmd
+	o.l, cmd = o.l.Update(msg)
+
+	if o.l.Clicked() {
+		return o, o.apply()
+	}
+
+	return o, cmd
+}
+
+func (o CodeActionsOverlay) View(width int, height int) string {
+	style := config.Theme.UI.Overlay.RunOverlayStyle
+	width /= 2
+	width -= style.GetHorizontalFrameSize()
+	if width > 0 {
+		o.l.SetWidth(width)
+	}
+
+	o.l.SetHeight(height - style.GetVerticalFrameSize() - 2)
+	return o.l.View()
+}
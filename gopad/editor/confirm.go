@@ -0,0 +1,107 @@
+package editor
+
+import (
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"go.gopad.dev/gopad/internal/bubbles/key"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/internal/bubbles/button"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+)
+
+var _ overlay.Overlay = (*ConfirmOverlay)(nil)
+
+// NewConfirmOverlay creates a dialog showing the message above a row of buttons, the button at focus is focused.
+func NewConfirmOverlay(id string, title string, message string, focus int, buttons ...button.Model) ConfirmOverlay {
+	o := ConfirmOverlay{
+		id:      id,
+		title:   title,
+		message: message,
+		buttons: buttons,
+	}
+	o.setFocus(focus)
+
+	return o
+}
+
+// ConfirmOverlay asks the user to choose one of its buttons, cancel closes it.
+type ConfirmOverlay struct {
+	id      string
+	title   string
+	message string
+
+	buttons []button.Model
+	focus   int
+}
+
+func (o ConfirmOverlay) ID() string {
+	return o.id
+}
+
+func (o ConfirmOverlay) Position() (lipgloss.Position, lipgloss.Position) {
+	return lipgloss.Center, lipgloss.Center
+}
+
+func (o ConfirmOverlay) Margin() (int, int) {
+	return 0, 0
+}
+
+func (o ConfirmOverlay) Title() string {
+	return o.title
+}
+
+func (o ConfirmOverlay) Init() (overlay.Overlay, tea.Cmd) {
+	return o, nil
+}
+
+func (o ConfirmOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, config.Keys.Cancel):
+			return o, overlay.Close(o.id)
+		case key.Matches(msg, config.Keys.Left):
+			o.setFocus(o.focus - 1)
+			return o, nil
+		case key.Matches(msg, config.Keys.Right):
+			o.setFocus(o.focus + 1)
+			return o, nil
+		}
+	}
+
+	for i := range o.buttons {
+		var cmd tea.Cmd
+		o.buttons[i], cmd = o.buttons[i].Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return o, tea.Batch(cmds...)
+}
+
+func (o *ConfirmOverlay) setFocus(focus int) {
+	o.focus = max(0, min(focus, len(o.buttons)-1))
+	for i := range o.buttons {
+		if i == o.focus {
+			o.buttons[i].Focus()
+		} else {
+			o.buttons[i].Blur()
+		}
+	}
+}
+
+func (o ConfirmOverlay) View(width int, height int) string {
+	buttons := make([]string, len(o.buttons))
+	for i, b := range o.buttons {
+		buttons[i] = b.View()
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center,
+		lipgloss.NewStyle().MarginBottom(1).Align(lipgloss.Center).Render(o.message),
+		lipgloss.JoinHorizontal(lipgloss.Center, buttons...),
+	)
+}
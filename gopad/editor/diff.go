@@ -0,0 +1,112 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"go.gopad.dev/gopad/internal/bubbles/key"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/diff"
+)
+
+const DiffOverlayID = "editor.diff"
+
+var _ overlay.Overlay = (*DiffOverlay)(nil)
+
+func NewDiffOverlay(name string, lines []diff.Line) DiffOverlay {
+	return DiffOverlay{
+		name:  name,
+		lines: lines,
+	}
+}
+
+// DiffOverlay shows the line diff between the file on disk and the open file.
+type DiffOverlay struct {
+	name   string
+	lines  []diff.Line
+	offset int
+}
+
+func (o DiffOverlay) ID() string {
+	return DiffOverlayID
+}
+
+func (o DiffOverlay) Position() (lipgloss.Position, lipgloss.Position) {
+	return lipgloss.Center, lipgloss.Center
+}
+
+func (o DiffOverlay) Margin() (int, int) {
+	return 0, 0
+}
+
+func (o DiffOverlay) Title() string {
+	return fmt.Sprintf("Disk → %s", filepath.Base(o.name))
+}
+
+func (o DiffOverlay) Init() (overlay.Overlay, tea.Cmd) {
+	return o, nil
+}
+
+func (o DiffOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, config.Keys.Cancel), key.Matches(msg, config.Keys.OK):
+			return o, overlay.Close(DiffOverlayID)
+		case key.Matches(msg, config.Keys.Up):
+			o.offset = max(0, o.offset-1)
+			return o, nil
+		case key.Matches(msg, config.Keys.Down):
+			o.offset = max(0, min(o.offset+1, len(o.lines)-1))
+			return o, nil
+		case key.Matches(msg, config.Keys.Editor.Navigation.PageUp):
+			o.offset = max(0, o.offset-pageSize)
+			return o, nil
+		case key.Matches(msg, config.Keys.Editor.Navigation.PageDown):
+			o.offset = max(0, min(o.offset+pageSize, len(o.lines)-1))
+			return o, nil
+		}
+	case tea.MouseWheelMsg:
+		switch msg.Button {
+		case tea.MouseWheelUp:
+			o.offset = max(0, o.offset-moveSize)
+		case tea.MouseWheelDown:
+			o.offset = max(0, min(o.offset+moveSize, len(o.lines)-1))
+		}
+		return o, nil
+	}
+
+	return o, nil
+}
+
+func (o DiffOverlay) View(width int, height int) string {
+	width /= 2
+	height = max(1, height*3/4)
+
+	if len(o.lines) == 0 {
+		return "No changes"
+	}
+
+	styles := config.Theme.UI.FileView
+	var lines []string
+	for _, line := range o.lines[o.offset:min(o.offset+height, len(o.lines))] {
+		text := strings.ReplaceAll(line.Text, "\t", strings.Repeat(" ", config.Gopad.Editor.TabSize))
+		switch line.Kind {
+		case diff.KindInsert:
+			text = styles.DiffInsertStyle.Render(ansi.Truncate("+ "+text, width, "…"))
+		case diff.KindDelete:
+			text = styles.DiffDeleteStyle.Render(ansi.Truncate("- "+text, width, "…"))
+		default:
+			text = ansi.Truncate("  "+text, width, "…")
+		}
+		lines = append(lines, text)
+	}
+
+	return lipgloss.NewStyle().Width(width).Align(lipgloss.Left).Render(strings.Join(lines, "\n"))
+}
@@ -2,6 +2,7 @@ package editor
 
 import (
 	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
 	"log"
@@ -26,10 +27,12 @@ import (
 	"go.gopad.dev/gopad/gopad/editor/filetree"
 	"go.gopad.dev/gopad/gopad/editor/searchbar"
 	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/gopad/session"
 	"go.gopad.dev/gopad/internal/bubbles"
 	"go.gopad.dev/gopad/internal/bubbles/mouse"
 	"go.gopad.dev/gopad/internal/bubbles/notifications"
 	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/watcher"
 )
 
 const (
@@ -41,15 +44,20 @@ const (
 	ZoneFileEncoding   = "file.encoding"
 	ZoneFileGoTo       = "file.goto"
 	ZoneFilePrefix     = "file:"
+	ZonePanePrefix     = "pane:"
 )
 
-func NewEditor(workspace string, args []string) (Editor, error) {
+func NewEditor(workspace string, args []string, s *session.Session) (Editor, error) {
 	e := Editor{
 		args:      args,
+		session:   s,
 		searchBar: searchbar.New(),
 		fileTree:  filetree.New(),
 		workspace: workspace,
+		recovered: map[string]int32{},
 	}
+	e.layout = newPane()
+	e.pane = e.layout
 
 	if workspace != "" {
 		if err := e.fileTree.Open(workspace); err != nil {
@@ -61,15 +69,22 @@ func NewEditor(workspace string, args []string) (Editor, error) {
 }
 
 type Editor struct {
-	fileTree         filetree.Model
-	args             []string
-	workspace        string
-	searchBar        searchbar.Model
-	files            []*file.File
-	activeFile       int
-	activeFileOffset int
-	focus            bool
-	treeSitterDebug  bool
+	fileTree        filetree.Model
+	args            []string
+	workspace       string
+	searchBar       searchbar.Model
+	files           []*file.File
+	layout          *pane
+	pane            *pane
+	focus           bool
+	treeSitterDebug bool
+	recentFiles     []string
+	watchID         int
+	snapshot        watcher.Snapshot
+	// recovered holds the buffer versions of the written recovery files
+	recovered map[string]int32
+	// session is restored on init
+	session *session.Session
 }
 
 func (e Editor) Init() (Editor, tea.Cmd) {
@@ -79,6 +94,15 @@ func (e Editor) Init() (Editor, tea.Cmd) {
 		e.fileTree.Show()
 		cmds = append(cmds, ls.WorkspaceOpened(e.workspace))
 	}
+	cmds = append(cmds, e.startWatch())
+	if config.Gopad.Recovery.Enabled {
+		cmds = append(cmds, recoveryTick())
+	}
+
+	if e.session != nil {
+		cmds = append(cmds, e.restoreSession(*e.session))
+		e.session = nil
+	}
 
 	for _, arg := range e.args {
 		stat, err := os.Stat(arg)
@@ -117,6 +141,19 @@ func (e Editor) Workspace() string {
 	return e.workspace
 }
 
+// RecentFiles returns the recently opened files starting with the most recent one.
+func (e Editor) RecentFiles() []string {
+	return e.recentFiles
+}
+
+func (e *Editor) addRecentFile(name string) {
+	e.recentFiles = slices.DeleteFunc(e.recentFiles, func(recentName string) bool {
+		return recentName == name
+	})
+	e.recentFiles = slices.Insert(e.recentFiles, 0, name)
+	e.recentFiles = e.recentFiles[:min(len(e.recentFiles), maxRecentFiles)]
+}
+
 func (e *Editor) Focused() bool {
 	return e.focus
 }
@@ -124,11 +161,21 @@ func (e *Editor) Focused() bool {
 func (e *Editor) Focus() tea.Cmd {
 	e.focus = true
 
-	if len(e.files) == 0 {
+	f := e.File()
+	if f == nil {
 		return nil
 	}
 
-	return e.files[e.activeFile].Focus()
+	return f.Focus()
+}
+
+// RefreshStyles applies the styles of the current theme to the open files and the search bar.
+// Everything else reads the theme when it is rendered.
+func (e *Editor) RefreshStyles() {
+	for _, f := range e.files {
+		f.RefreshStyles()
+	}
+	e.searchBar.RefreshStyles()
 }
 
 func (e *Editor) Blur() {
@@ -154,18 +201,24 @@ func (e *Editor) CreateFile(name string) (tea.Cmd, error) {
 		return nil, nil
 	}
 
-	buff, err := buffer.New(name, bytes.NewReader(nil), "utf-8", buffer.LineEndingLF, false)
+	cfg := file.EditorConfigForName(name)
+	lineEnding := buffer.ParseLineEnding(cfg.EndOfLine)
+	if lineEnding == buffer.LineEndingAuto {
+		lineEnding = buffer.LineEndingLF
+	}
+	buff, err := buffer.New(name, bytes.NewReader(nil), cmp.Or(cfg.Charset, "utf-8"), lineEnding, false)
 	if err != nil {
 		return nil, err
 	}
 	f := file.NewFileWithBuffer(buff, file.ModeWrite)
 
 	e.files = append(e.files, f)
+	e.addFile(f)
 
 	cmds := []tea.Cmd{
 		tea.Sequence(
 			ls.FileCreated(f.Name(), f.Buffer().Bytes()),
-			ls.FileOpened(f.Name(), f.Buffer().Version(), f.Buffer().Bytes()),
+			ls.FileOpened(f.Name(), f.LanguageID(), f.Buffer().Version(), f.Buffer().Copy()),
 			ls.GetInlayHint(f.Name(), f.Version(), f.Range()),
 		),
 	}
@@ -189,9 +242,18 @@ func (e *Editor) OpenFile(name string) (tea.Cmd, error) {
 		return nil, err
 	}
 	e.files = append(e.files, f)
+	e.addFile(f)
+
+	// large files are neither parsed nor sent to language servers
+	if f.LargeFile() {
+		if f.Loading() {
+			return file.LoadLazy(f.Name(), buffer.LineEndingAuto), nil
+		}
+		return nil, nil
+	}
 
 	cmds := []tea.Cmd{
-		ls.FileOpened(f.Name(), f.Buffer().Version(), f.Buffer().Bytes()),
+		ls.FileOpened(f.Name(), f.LanguageID(), f.Buffer().Version(), f.Buffer().Copy()),
 		ls.GetInlayHint(f.Name(), f.Version(), f.Range()),
 	}
 
@@ -202,16 +264,36 @@ func (e *Editor) OpenFile(name string) (tea.Cmd, error) {
 	return tea.Batch(cmds...), nil
 }
 
-func (e *Editor) SaveFile(name string) (tea.Cmd, error) {
+// SaveFile saves the file, if overwrite is false saving fails with buffer.ErrModifiedOnDisk if the file changed on disk.
+func (e *Editor) SaveFile(name string, overwrite bool) (tea.Cmd, error) {
 	f := e.FileByName(name)
 	if f == nil {
 		return nil, nil
 	}
-	if err := f.Buffer().Save(); err != nil {
-		return nil, err
+	cmd := f.Normalize()
+	save := f.Buffer().Save
+	if overwrite {
+		save = f.Buffer().Overwrite
 	}
+	if err := save(); err != nil {
+		return cmd, err
+	}
+
+	// the language servers have to receive the normalization changes before the save
+	return tea.Sequence(cmd, ls.FileSaved(f.Name(), f.Buffer().Bytes())), nil
+}
 
-	return ls.FileSaved(f.Name(), f.Buffer().Bytes()), nil
+// saveFile saves the file and reports the result as notification.
+// Files which changed on disk are only overwritten after asking.
+func (e *Editor) saveFile(name string, overwrite bool) tea.Cmd {
+	cmd, err := e.SaveFile(name, overwrite)
+	if errors.Is(err, buffer.ErrModifiedOnDisk) {
+		return tea.Batch(cmd, overlay.Open(NewOverwriteOverlay(name)))
+	}
+	if err != nil {
+		return tea.Batch(cmd, notifications.Add(fmt.Sprintf("error while saving file %s: %s", name, err.Error())))
+	}
+	return tea.Batch(cmd, notifications.Add(fmt.Sprintf("file %s saved", name)))
 }
 
 func (e *Editor) RenameFile(oldName string, newName string) (tea.Cmd, error) {
@@ -223,11 +305,13 @@ func (e *Editor) RenameFile(oldName string, newName string) (tea.Cmd, error) {
 	if f == nil {
 		return nil, nil
 	}
+	// normalize like SaveFile does, so the changes are recorded before the buffer is saved under the new name
+	cmd := f.Normalize()
 	if err := f.Buffer().Rename(newName); err != nil {
-		return nil, err
+		return cmd, err
 	}
 
-	return ls.FileRenamed(f.Name(), newName), nil
+	return tea.Sequence(cmd, ls.FileRenamed(oldName, newName)), nil
 }
 
 func (e *Editor) CloseFile(name string) (tea.Cmd, error) {
@@ -240,13 +324,19 @@ func (e *Editor) CloseFile(name string) (tea.Cmd, error) {
 
 	f := e.files[index]
 	e.files = slices.Delete(e.files, index, index+1)
-	e.activeFile = min(e.activeFile, len(e.files)-1)
-	if len(e.files) > 0 {
-		e.files[e.activeFile].Focus()
+	e.removeFile(f)
+	if active := e.File(); active != nil {
+		active.Focus()
 	} else {
 		e.fileTree.Focus()
 	}
+	if err := f.Buffer().Close(); err != nil {
+		log.Printf("error while closing file %s: %s\n", name, err)
+	}
 
+	if f.LargeFile() {
+		return nil, nil
+	}
 	return ls.FileClosed(f.Name()), nil
 }
 
@@ -259,14 +349,18 @@ func (e *Editor) DeleteFile(name string) (tea.Cmd, error) {
 	}
 
 	f := e.files[index]
+	// open files can't be deleted on windows
+	if err := f.Buffer().Close(); err != nil {
+		return nil, err
+	}
 	if err := f.Buffer().Delete(); err != nil {
 		return nil, err
 	}
 
-	e.files = slices.Delete(e.files, e.activeFile, e.activeFile+1)
-	e.activeFile = min(e.activeFile, len(e.files)-1)
-	if len(e.files) > 0 {
-		e.files[e.activeFile].Focus()
+	e.files = slices.Delete(e.files, index, index+1)
+	e.removeFile(f)
+	if active := e.File(); active != nil {
+		active.Focus()
 	} else {
 		e.fileTree.Focus()
 	}
@@ -274,24 +368,87 @@ func (e *Editor) DeleteFile(name string) (tea.Cmd, error) {
 	return ls.FileDeleted(f.Name()), nil
 }
 
-func (e *Editor) File() *file.File {
-	if len(e.files) == 0 {
-		return nil
+// ApplyWorkspaceEdit applies the edits to the open files and saves the edits to files which are not open.
+// All edits are checked first, nothing is changed if the version of an open file doesn't match or an edit is invalid.
+// If a file which is not open can't be saved, the files saved before are restored.
+func (e *Editor) ApplyWorkspaceEdit(edit ls.WorkspaceEdit) (tea.Cmd, error) {
+	type diskEdit struct {
+		b    *buffer.Buffer
+		orig *buffer.Buffer
 	}
-	return e.files[e.activeFile]
+
+	var (
+		files     []*file.File
+		fileEdits [][]ls.TextEdit
+		diskEdits []diskEdit
+	)
+	for _, fileEdit := range edit.Files {
+		if f := e.FileByName(fileEdit.Name); f != nil {
+			if fileEdit.Version != nil && *fileEdit.Version != f.Version() {
+				return nil, fmt.Errorf("file %s changed since the edit was made", fileEdit.Name)
+			}
+			if err := file.CheckTextEdits(f.Buffer(), fileEdit.Edits); err != nil {
+				return nil, fmt.Errorf("invalid edit for file %s: %w", fileEdit.Name, err)
+			}
+			files = append(files, f)
+			fileEdits = append(fileEdits, fileEdit.Edits)
+			continue
+		}
+
+		// the version only applies to open files, edits for other files are checked against the file on disk
+		b, err := buffer.NewFromFile(fileEdit.Name, "UTF-8", buffer.LineEndingAuto)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file %s: %w", fileEdit.Name, err)
+		}
+		if err = file.CheckTextEdits(b, fileEdit.Edits); err != nil {
+			return nil, fmt.Errorf("invalid edit for file %s: %w", fileEdit.Name, err)
+		}
+		orig := b.Copy()
+		file.ApplyTextEdits(b, fileEdit.Edits)
+		diskEdits = append(diskEdits, diskEdit{b: b, orig: orig})
+	}
+
+	for i, d := range diskEdits {
+		if err := d.b.Save(); err != nil {
+			for _, saved := range diskEdits[:i] {
+				if restoreErr := saved.orig.Overwrite(); restoreErr != nil {
+					log.Printf("failed to restore file %s: %s", saved.orig.Name(), restoreErr)
+				}
+			}
+			return nil, fmt.Errorf("error saving file %s: %w", d.b.Name(), err)
+		}
+	}
+
+	cmds := make([]tea.Cmd, 0, len(files))
+	for i, f := range files {
+		cmds = append(cmds, f.ApplyTextEdits(fileEdits[i]))
+	}
+	return tea.Batch(cmds...), nil
 }
 
+// File returns the active file of the active pane.
+func (e *Editor) File() *file.File {
+	return e.pane.file()
+}
+
+// SetFile sets the active file of the active pane.
 func (e *Editor) SetFile(index int) {
-	e.activeFile = index
+	if index < 0 || index >= len(e.pane.files) {
+		return
+	}
+	e.saveCursor()
+	e.pane.activeFile = index
+	e.loadCursor()
 }
 
+// SetFileByName shows the open file in the active pane.
 func (e *Editor) SetFileByName(name string) {
-	for i, f := range e.files {
-		if f.Name() == name {
-			e.activeFile = i
-			return
-		}
+	f := e.FileByName(name)
+	if f == nil {
+		return
 	}
+	e.addFile(f)
+	e.SetFile(slices.Index(e.pane.files, f))
 }
 
 func (e *Editor) FileByName(name string) *file.File {
@@ -342,17 +499,12 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 		}
 		f.SetInlayHint(msg.Version, msg.Hints)
 		return e, tea.Batch(cmds...)
-	case file.UpdateMatchesMsg:
-		f := e.FileByName(msg.Name)
-		if f == nil {
-			return e, tea.Batch(cmds...)
-		}
-		f.SetMatches(msg.Version, msg.Matches)
-		return e, tea.Batch(cmds...)
 	case ls.RefreshInlayHintMsg:
 		// refresh inlay hints for all open files
 		for _, f := range e.files {
-			cmds = append(cmds, ls.GetInlayHint(f.Name(), f.Version(), f.Range()))
+			if !f.LargeFile() {
+				cmds = append(cmds, ls.GetInlayHint(f.Name(), f.Version(), f.Range()))
+			}
 		}
 		return e, tea.Batch(cmds...)
 	case ls.UpdateDefinitionMsg:
@@ -362,6 +514,64 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 		}
 		cmds = append(cmds, f.SetDefinitions(msg.Definitions))
 		return e, tea.Batch(cmds...)
+	case ls.UpdateImplementationMsg:
+		locations := make([]location, 0, len(msg.Implementations))
+		for _, implementation := range msg.Implementations {
+			locations = append(locations, location(implementation))
+		}
+		cmds = append(cmds, e.showLocations("Implementations", "No implementation found", locations, true))
+		return e, tea.Batch(cmds...)
+	case ls.UpdateReferencesMsg:
+		locations := make([]location, 0, len(msg.References))
+		for _, reference := range msg.References {
+			locations = append(locations, location(reference))
+		}
+		cmds = append(cmds, e.showLocations("References", "No references found", locations, false))
+		return e, tea.Batch(cmds...)
+	case file.HoverDwellMsg:
+		if f := e.FileByName(msg.Name); f != nil {
+			cmds = append(cmds, f.Hover().Dwelled(msg))
+		}
+		return e, tea.Batch(cmds...)
+	case ls.UpdateSignatureHelpMsg:
+		if f := e.FileByName(msg.Name); f != nil {
+			f.SignatureHelp().SetSignatureHelp(msg.Help)
+		}
+		return e, tea.Batch(cmds...)
+	case ls.UpdateHoverMsg:
+		if f := e.FileByName(msg.Name); f != nil {
+			f.Hover().SetHover(msg.Row, msg.Col, msg.Hover)
+		}
+		return e, tea.Batch(cmds...)
+	case ls.UpdatePrepareRenameMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		if msg.Range == nil {
+			cmds = append(cmds, notifications.Add("Symbol can't be renamed"))
+			return e, tea.Batch(cmds...)
+		}
+
+		r := *msg.Range
+		if r.Start == r.End {
+			r = f.WordRange(msg.Row, msg.Col)
+		}
+		symbol := string(f.Buffer().BytesRange(r.Start, r.End))
+		cmds = append(cmds, overlay.Open(NewRenameSymbolOverlay(msg.Name, msg.Row, msg.Col, symbol)))
+		return e, tea.Batch(cmds...)
+	case ls.ApplyWorkspaceEditMsg:
+		cmd, err := e.ApplyWorkspaceEdit(msg.Edit)
+		if msg.Result != nil {
+			msg.Result <- err
+		}
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if err != nil {
+			cmds = append(cmds, notifications.Addf("error while applying edit: %s", err))
+		}
+		return e, tea.Batch(cmds...)
 	case file.OpenDirMsg:
 		e.fileTree.Show()
 		e.fileTree.Focus()
@@ -382,7 +592,74 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 		}
 		e.workspace = msg.Name
 		wCmds = append(wCmds, ls.WorkspaceOpened(msg.Name))
+		cmds = append(cmds, e.startWatch())
 		return e, tea.Batch(append(cmds, tea.Sequence(wCmds...))...)
+	case watchMsg:
+		if msg.id != e.watchID {
+			return e, tea.Batch(cmds...)
+		}
+		cmds = append(cmds, e.updateSnapshot(msg.snapshot), e.watch())
+		return e, tea.Batch(cmds...)
+	case fileModifiedMsg:
+		cmds = append(cmds, e.fileModified(msg))
+		return e, tea.Batch(cmds...)
+	case recoveryMsg:
+		cmds = append(cmds, e.writeRecovery(), recoveryTick())
+		return e, tea.Batch(cmds...)
+	case file.RecoverFileMsg:
+		cmds = append(cmds, e.recoverFile(msg.Name))
+		return e, tea.Batch(cmds...)
+	case file.LazyLoadedMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			if msg.Buffer != nil {
+				_ = msg.Buffer.Close()
+			}
+			return e, tea.Batch(cmds...)
+		}
+		if msg.Err != nil {
+			cmds = append(cmds, notifications.Addf("error while loading file %s: %s", msg.Name, msg.Err))
+			return e, tea.Batch(cmds...)
+		}
+		f.SetLazyBuffer(msg.Buffer)
+		return e, tea.Batch(cmds...)
+	case file.ReloadFileMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		cmd, err := f.Reload()
+		if err != nil {
+			cmds = append(cmds, notifications.Addf("error while reloading file %s: %s", msg.Name, err))
+			return e, tea.Batch(cmds...)
+		}
+		cmds = append(cmds, cmd, notifications.Addf("file %s reloaded", msg.Name))
+		return e, tea.Batch(cmds...)
+	case file.KeepFileMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		// take over the checksum of the file on disk so the change is only reported once
+		checksum, err := f.Buffer().DiskChecksum()
+		if err != nil {
+			cmds = append(cmds, notifications.Addf("error while checking file %s: %s", msg.Name, err))
+			return e, tea.Batch(cmds...)
+		}
+		f.Buffer().SetChecksum(checksum)
+		return e, tea.Batch(cmds...)
+	case file.DiffFileMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		lines, err := f.DiskDiff()
+		if err != nil {
+			cmds = append(cmds, notifications.Addf("error while comparing file %s: %s", msg.Name, err))
+			return e, tea.Batch(cmds...)
+		}
+		cmds = append(cmds, overlay.Open(NewDiffOverlay(msg.Name, lines)))
+		return e, tea.Batch(cmds...)
 	case file.OpenFileMsg:
 		cmd, err := e.OpenFile(msg.Name)
 		if err != nil {
@@ -397,20 +674,73 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 			editormsg.Focus(editormsg.ModelFile),
 		)
 		e.SetFileByName(msg.Name)
+		e.addRecentFile(msg.Name)
 		if msg.Position != nil {
 			e.File().SetCursor(msg.Position.Row, msg.Position.Col)
 		}
 		return e, tea.Batch(cmds...)
 	case file.SaveFileMsg:
-		cmd, err := e.SaveFile(msg.Name)
-		if err != nil {
-			cmds = append(cmds, notifications.Add(fmt.Sprintf("error while saving file %s: %s", msg.Name, err.Error())))
+		if f := e.FileByName(msg.Name); f != nil && f.FormatOnSave() {
+			cmds = append(cmds, f.Format(true))
 			return e, tea.Batch(cmds...)
 		}
-		if cmd != nil {
-			cmds = append(cmds, cmd)
+		cmds = append(cmds, e.saveFile(msg.Name, false))
+		return e, tea.Batch(cmds...)
+	case file.OverwriteFileMsg:
+		cmds = append(cmds, e.saveFile(msg.Name, true))
+		return e, tea.Batch(cmds...)
+	case ls.UpdateFormatMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		var cmd tea.Cmd
+		if msg.Err != nil {
+			cmds = append(cmds, notifications.Addf("error formatting file %s: %s", msg.Name, msg.Err))
+		} else if f.Version() != msg.Version {
+			cmds = append(cmds, notifications.Addf("file %s changed while formatting", msg.Name))
+		} else {
+			cmd = f.ApplyTextEdits(msg.Edits)
+		}
+		if msg.Save {
+			// the language servers have to receive the changes before the save
+			cmd = tea.Sequence(cmd, e.saveFile(msg.Name, false))
+		}
+		cmds = append(cmds, cmd)
+		return e, tea.Batch(cmds...)
+	case ls.FormatUnsupportedMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		if msg.Range == nil && f.HasExternalFormatter() {
+			cmds = append(cmds, f.FormatExternal(msg.Save))
+			return e, tea.Batch(cmds...)
+		}
+		if msg.Save {
+			cmds = append(cmds, e.saveFile(msg.Name, false))
+			return e, tea.Batch(cmds...)
 		}
-		cmds = append(cmds, notifications.Add(fmt.Sprintf("file %s saved", msg.Name)))
+		cmds = append(cmds, notifications.Addf("no formatter available for file %s", msg.Name))
+		return e, tea.Batch(cmds...)
+	case file.FormattedMsg:
+		f := e.FileByName(msg.Name)
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		var cmd tea.Cmd
+		if msg.Err != nil {
+			cmds = append(cmds, notifications.Addf("error formatting file %s: %s", msg.Name, msg.Err))
+		} else if f.Version() != msg.Version {
+			cmds = append(cmds, notifications.Addf("file %s changed while formatting", msg.Name))
+		} else {
+			cmd = f.ApplyFormatted(msg.Text)
+		}
+		if msg.Save {
+			// the language servers have to receive the changes before the save
+			cmd = tea.Sequence(cmd, e.saveFile(msg.Name, false))
+		}
+		cmds = append(cmds, cmd)
 		return e, tea.Batch(cmds...)
 	case file.CloseFileMsg:
 		cmd, err := e.CloseFile(msg.Name)
@@ -457,8 +787,21 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 	case searchbar.SearchMsg:
 		f := e.File()
 		if f != nil {
-			cmds = append(cmds, searchbar.ExecSearch(msg.Term, f.Buffer().Copy()))
+			cmds = append(cmds, searchbar.ExecSearch(msg.Term, msg.Options, f.Buffer().Copy()))
+		}
+		return e, tea.Batch(cmds...)
+	case searchbar.ReplaceMsg:
+		f := e.File()
+		if f == nil {
+			return e, tea.Batch(cmds...)
+		}
+		re, err := searchbar.Pattern(msg.Term, msg.Options)
+		if err != nil {
+			cmds = append(cmds, notifications.Add(err.Error()))
+			return e, tea.Batch(cmds...)
 		}
+		edits := searchbar.ReplaceEdits(re, msg.Options, msg.Replacement, f.Buffer(), msg.Result)
+		cmds = append(cmds, f.ApplyTextEdits(edits), searchbar.Search(msg.Term, msg.Options))
 		return e, tea.Batch(cmds...)
 	case editormsg.FocusMsg:
 		cmds = append(cmds, e.Focus())
@@ -486,6 +829,17 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 			}
 		}
 		return e, tea.Batch(cmds...)
+	case tea.MouseClickMsg:
+		// only inactive panes are marked, clicking them makes them active
+		for _, z := range zone.GetPrefix(ZonePanePrefix) {
+			if mouse.MatchesZone(msg, z, tea.MouseLeft) {
+				i, _ := strconv.Atoi(strings.TrimPrefix(z.ID(), ZonePanePrefix))
+				if leaves := e.layout.leaves(); i < len(leaves) {
+					cmds = append(cmds, e.setPane(leaves[i]))
+				}
+				return e, tea.Batch(cmds...)
+			}
+		}
 	case tea.KeyPressMsg:
 		switch {
 		case key.Matches(msg, config.Keys.Editor.ToggleFileTree):
@@ -505,6 +859,31 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 			return e, tea.Batch(cmds...)
 		case key.Matches(msg, config.Keys.Editor.File.New):
 			return e, overlay.Open(NewNewOverlay())
+		case key.Matches(msg, config.Keys.Editor.Pane.SplitHorizontal):
+			return e, e.splitPane(false)
+		case key.Matches(msg, config.Keys.Editor.Pane.SplitVertical):
+			return e, e.splitPane(true)
+		case key.Matches(msg, config.Keys.Editor.Pane.Close):
+			return e, e.closePane()
+		case key.Matches(msg, config.Keys.Editor.Pane.Grow):
+			e.resizePane(paneResizeStep)
+			return e, nil
+		case key.Matches(msg, config.Keys.Editor.Pane.Shrink):
+			e.resizePane(-paneResizeStep)
+			return e, nil
+		case key.Matches(msg, config.Keys.Editor.Pane.Next):
+			return e, e.focusPane(1)
+		case key.Matches(msg, config.Keys.Editor.Pane.Prev):
+			return e, e.focusPane(-1)
+		case key.Matches(msg, config.Keys.Editor.FindInFiles):
+			if e.workspace == "" {
+				return e, notifications.Add("no folder open")
+			}
+			buffers := make(map[string]*buffer.Buffer, len(e.files))
+			for _, f := range e.files {
+				buffers[f.Name()] = f.Buffer().Copy()
+			}
+			return e, overlay.Open(NewFindInFilesOverlay(e.workspace, buffers))
 		case key.Matches(msg, config.Keys.Editor.Search):
 			if !e.searchBar.Visible() {
 				e.searchBar.Show()
@@ -543,25 +922,25 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.PasteMsg:
-		s := f.Selection()
-		if s != nil {
-			log.Println("paste msg", msg)
-			f.Replace(s.Start.Row, s.Start.Col, s.End.Row, s.End.Col, []byte(msg))
-			f.ResetMark()
-		} else {
-			f.Insert([]byte(msg))
-		}
+		cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+			if s := f.Selection(); s != nil {
+				cmd := f.Replace(s.Start.Row, s.Start.Col, s.End.Row, s.End.Col, []byte(msg))
+				f.ResetMark()
+				return cmd
+			}
+			return f.Insert([]byte(msg))
+		}))
 		return e, tea.Batch(cmds...)
 
 	case file.PasteMsg:
-		s := f.Selection()
-		if s != nil {
-			log.Println("paste msg", msg)
-			f.Replace(s.Start.Row, s.Start.Col, s.End.Row, s.End.Col, msg)
-			f.ResetMark()
-		} else {
-			f.Insert(msg)
-		}
+		cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+			if s := f.Selection(); s != nil {
+				cmd := f.Replace(s.Start.Row, s.Start.Col, s.End.Row, s.End.Col, msg)
+				f.ResetMark()
+				return cmd
+			}
+			return f.Insert(msg)
+		}))
 		return e, tea.Batch(cmds...)
 	case file.CutMsg:
 		s := buffer.Range(msg)
@@ -581,27 +960,40 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 		cmds = append(cmds, overlay.Open(NewRenameOverlay(f.Name())))
 	case file.RenameFileMsg:
 		cmd, err := e.RenameFile(f.Name(), msg.Name)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		if err != nil {
 			cmds = append(cmds, notifications.Add(fmt.Sprintf("error while renamed file %s: %s", f.Name(), err.Error())))
 			return e, tea.Batch(cmds...)
 		}
-		if cmd != nil {
-			cmds = append(cmds, cmd)
-		}
 		cmds = append(cmds, notifications.Add(fmt.Sprintf("file %s renamed to %s", f.Name(), msg.Name)))
 	case file.SetLanguageMsg:
+		languageID := f.LanguageID()
 		f.SetLanguage(msg.Language)
 		f.ClearDiagnosticsByType(ls.DiagnosticTypeTreeSitter)
 
 		if cmd = f.InitTree(); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		// reopen the file so language servers pick up the new language id
+		if languageID != f.LanguageID() && !f.LargeFile() {
+			cmds = append(cmds, tea.Sequence(
+				ls.FileClosed(f.Name()),
+				ls.FileOpened(f.Name(), f.LanguageID(), f.Version(), f.Buffer().Copy()),
+			))
+		}
 		return e, tea.Batch(cmds...)
 	case file.SaveMsg:
 		if f.Dirty() {
 			cmds = append(cmds, file.SaveFile(f.Name()))
 		}
 	case file.CloseMsg:
+		// the file stays open if it is shown in another pane
+		if e.showing(f, e.pane) {
+			cmds = append(cmds, e.closeTab(f))
+			return e, tea.Batch(cmds...)
+		}
 		if f.Dirty() {
 			return e, overlay.Open(NewCloseOverlay([]string{f.Name()}))
 		}
@@ -645,6 +1037,7 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 			switch {
 			case mouse.MatchesZone(msg, z, tea.MouseLeft):
 				row, _ := strconv.Atoi(strings.TrimPrefix(z.ID(), file.ZoneFileLineNumberPrefix))
+				f.ResetCursors()
 				f.SetCursor(row, -1)
 				f.SetMark(f.Cursor())
 				overwriteCursorBlink = true
@@ -683,6 +1076,7 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 				}
 
 				row, col := f.GetFileZoneCursorPos(msg, z)
+				f.ResetCursors()
 				f.SetCursor(row, col)
 				if s := f.Selection(); s == nil || s.Zero() {
 					f.ResetMark()
@@ -745,7 +1139,11 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 				}
 
 				i, _ := strconv.Atoi(strings.TrimPrefix(z.ID(), ZoneFilePrefix))
-				cmds = append(cmds, file.CloseFile(e.files[i].Name()))
+				if tab := e.pane.files[i]; e.showing(tab, e.pane) {
+					cmds = append(cmds, e.closeTab(tab))
+				} else {
+					cmds = append(cmds, file.CloseFile(tab.Name()))
+				}
 				return e, tea.Batch(cmds...)
 			}
 		}
@@ -776,7 +1174,29 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 				return e, tea.Batch(cmds...)
 			}
 		}
+
+		if mouse.Matches(msg, file.ZoneFileHover, tea.MouseNone) {
+			return e, tea.Batch(cmds...)
+		}
+		for _, z := range zone.GetPrefix(file.ZoneFileLinePrefix) {
+			switch {
+			case mouse.MatchesZone(msg, z, tea.MouseNone):
+				row, col := f.GetFileZoneCursorPos(msg, z)
+				cmds = append(cmds, f.Hover().Dwell(row, col))
+				return e, tea.Batch(cmds...)
+			}
+		}
+		f.Hover().MouseLeave()
 	case tea.MouseWheelMsg:
+		switch {
+		case mouse.Matches(msg, file.ZoneFileHover, tea.MouseWheelUp):
+			f.Hover().ScrollUp()
+			return e, tea.Batch(cmds...)
+		case mouse.Matches(msg, file.ZoneFileHover, tea.MouseWheelDown):
+			f.Hover().ScrollDown()
+			return e, tea.Batch(cmds...)
+		}
+
 		for _, z := range append(zone.GetPrefix(file.ZoneFileLinePrefix), zone.GetPrefix(file.ZoneFileLineNumberPrefix)...) {
 			switch {
 			case mouse.MatchesZone(msg, z, tea.MouseWheelLeft), mouse.MatchesZone(msg, z, tea.MouseWheelDown, tea.ModShift):
@@ -799,13 +1219,42 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 		}
 	case tea.KeyPressMsg:
 		if e.focus {
+			if f.Hover().Visible() && !key.Matches(msg, config.Keys.Editor.Hover.ScrollUp, config.Keys.Editor.Hover.ScrollDown) {
+				f.Hover().Hide()
+				if key.Matches(msg, config.Keys.Cancel) {
+					return e, tea.Batch(cmds...)
+				}
+			}
+
+			if f.Mode() == file.ModeReadOnly && isEditKey(msg) {
+				cmds = append(cmds, notifications.Add("file is read-only"))
+				return e, tea.Batch(cmds...)
+			}
+			if f.LargeFile() && isLanguageServerKey(msg) {
+				cmds = append(cmds, notifications.Add("language servers are disabled for large files"))
+				return e, tea.Batch(cmds...)
+			}
+
 			switch {
+			case key.Matches(msg, config.Keys.Editor.Hover.Show):
+				cmds = append(cmds, f.Hover().Show())
+				return e, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.Hover.ScrollUp) && f.Hover().Visible():
+				f.Hover().ScrollUp()
+				return e, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.Hover.ScrollDown) && f.Hover().Visible():
+				f.Hover().ScrollDown()
+				return e, tea.Batch(cmds...)
 			case key.Matches(msg, config.Keys.Editor.Autocomplete.Show):
 				row, col := f.Cursor()
 				cmds = append(cmds, ls.GetAutocompletion(f.Name(), row, col))
 				return e, tea.Batch(cmds...)
 			case key.Matches(msg, config.Keys.Cancel) && f.Autocomplete().Visible():
 				f.Autocomplete().ClearCompletions()
+			case key.Matches(msg, config.Keys.Cancel) && f.SignatureHelp().Visible():
+				f.SignatureHelp().Hide()
+			case key.Matches(msg, config.Keys.Cancel) && f.Cursors() > 1:
+				f.ResetCursors()
 			case key.Matches(msg, config.Keys.Editor.Autocomplete.Next) && f.Autocomplete().Visible():
 				f.Autocomplete().Next()
 			case key.Matches(msg, config.Keys.Editor.Autocomplete.Prev) && f.Autocomplete().Visible():
@@ -849,7 +1298,8 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 				debugFile := file.NewFileWithBuffer(buff, file.ModeReadOnly)
 
 				e.files = append(e.files, debugFile)
-				e.activeFile = len(e.files) - 1
+				e.addFile(debugFile)
+				e.SetFile(len(e.pane.files) - 1)
 			case key.Matches(msg, config.Keys.Editor.Diagnostic.Show):
 				f.ShowCurrentDiagnostic()
 			case key.Matches(msg, config.Keys.Cancel) && f.ShowsCurrentDiagnostic():
@@ -864,24 +1314,31 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 				cmds = append(cmds, f.ShowTypeDefinitions())
 				return e, tea.Batch(cmds...)
 			case key.Matches(msg, config.Keys.Editor.Code.ShowImplementation):
-				// cmds = append(cmds, f.ShowImplementations())
-				// return e, tea.Batch(cmds...)
+				cmds = append(cmds, f.ShowImplementations())
+				return e, tea.Batch(cmds...)
 			case key.Matches(msg, config.Keys.Editor.Code.ShowReferences):
-				// cmds = append(cmds, f.ShowReferences())
-				// return e, tea.Batch(cmds...)
+				cmds = append(cmds, f.ShowReferences())
+				return e, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.Code.RenameSymbol):
+				cmds = append(cmds, f.RenameSymbol())
+				return e, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.Code.Format):
+				cmds = append(cmds, f.FormatSelection())
+				return e, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.Code.CodeActions):
+				cmds = append(cmds, overlay.Open(NewCodeActionsOverlay(f)))
+				return e, tea.Batch(cmds...)
 			case key.Matches(msg, config.Keys.Editor.OpenOutline):
 				cmds = append(cmds, overlay.Open(NewOutlineOverlay(f)))
 			case key.Matches(msg, config.Keys.Editor.File.Next):
-				if e.activeFile < len(e.files)-1 {
-					e.activeFile++
-					f.Blur()
-					cmds = append(cmds, e.files[e.activeFile].Focus())
+				if e.pane.activeFile < len(e.pane.files)-1 {
+					e.SetFile(e.pane.activeFile + 1)
+					cmds = append(cmds, e.File().Focus())
 				}
 			case key.Matches(msg, config.Keys.Editor.File.Prev):
-				if e.activeFile > 0 {
-					e.activeFile--
-					f.Blur()
-					cmds = append(cmds, e.files[e.activeFile].Focus())
+				if e.pane.activeFile > 0 {
+					e.SetFile(e.pane.activeFile - 1)
+					cmds = append(cmds, e.File().Focus())
 				}
 			case key.Matches(msg, config.Keys.Editor.File.Close):
 				cmds = append(cmds, file.Close)
@@ -891,41 +1348,45 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 			case key.Matches(msg, config.Keys.Editor.File.Rename):
 				cmds = append(cmds, overlay.Open(NewRenameOverlay(f.Name())))
 			case key.Matches(msg, config.Keys.Editor.Navigation.LineUp):
-				f.MoveCursorUp(moveSize)
+				f.MoveCursors(func() { f.MoveCursorUp(moveSize) })
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.LineDown):
-				f.MoveCursorDown(moveSize)
+				f.MoveCursors(func() { f.MoveCursorDown(moveSize) })
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.CharacterLeft):
-				f.MoveCursorLeft(moveSize)
+				f.MoveCursors(func() { f.MoveCursorLeft(moveSize) })
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.CharacterRight):
-				f.MoveCursorRight(moveSize)
+				f.MoveCursors(func() { f.MoveCursorRight(moveSize) })
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.WordUp):
-				f.MoveCursorWordUp()
+				f.MoveCursors(func() { f.MoveCursorWordUp() })
 			case key.Matches(msg, config.Keys.Editor.Navigation.WordDown):
-				f.MoveCursorWordDown()
+				f.MoveCursors(func() { f.MoveCursorWordDown() })
 			case key.Matches(msg, config.Keys.Editor.Navigation.WordLeft):
-				f.SetCursor(f.NextWordLeft())
+				f.MoveCursors(func() { f.SetCursor(f.NextWordLeft()) })
 			case key.Matches(msg, config.Keys.Editor.Navigation.WordRight):
-				f.SetCursor(f.NextWordRight())
+				f.MoveCursors(func() { f.SetCursor(f.NextWordRight()) })
 			case key.Matches(msg, config.Keys.Editor.Navigation.PageUp):
-				f.MoveCursorUp(pageSize)
+				f.MoveCursors(func() { f.MoveCursorUp(pageSize) })
 			case key.Matches(msg, config.Keys.Editor.Navigation.PageDown):
-				f.MoveCursorDown(pageSize)
+				f.MoveCursors(func() { f.MoveCursorDown(pageSize) })
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.LineStart):
-				f.SetCursor(-1, 0)
+				f.MoveCursors(func() { f.SetCursor(-1, 0) })
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.LineEnd):
-				cursorRow, _ := f.Cursor()
-				f.SetCursor(-1, f.Buffer().LineLen(cursorRow))
+				f.MoveCursors(func() {
+					cursorRow, _ := f.Cursor()
+					f.SetCursor(-1, f.Buffer().LineLen(cursorRow))
+				})
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.FileStart):
+				f.ResetCursors()
 				f.SetCursor(0, -1)
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.FileEnd):
+				f.ResetCursors()
 				f.SetCursor(f.Buffer().LinesLen(), -1)
 				cmds = append(cmds, f.Autocomplete().Update())
 			case key.Matches(msg, config.Keys.Editor.Navigation.GoTo):
@@ -943,154 +1404,192 @@ func (e Editor) Update(msg tea.Msg) (Editor, tea.Cmd) {
 				if s != nil {
 					cmds = append(cmds, file.Cut(*s, f.SelectionBytes()))
 				}
+			case key.Matches(msg, config.Keys.Editor.Edit.Undo):
+				f.Autocomplete().ClearCompletions()
+				cmds = append(cmds, f.Undo())
+			case key.Matches(msg, config.Keys.Editor.Edit.Redo):
+				f.Autocomplete().ClearCompletions()
+				cmds = append(cmds, f.Redo())
 			case key.Matches(msg, config.Keys.Editor.Selection.SelectLeft):
-				f.SelectLeft(moveSize)
+				f.MoveCursors(func() { f.SelectLeft(moveSize) })
 			case key.Matches(msg, config.Keys.Editor.Selection.SelectRight):
-				f.SelectRight(moveSize)
+				f.MoveCursors(func() { f.SelectRight(moveSize) })
 			case key.Matches(msg, config.Keys.Editor.Selection.SelectUp):
-				f.SelectUp(moveSize)
+				f.MoveCursors(func() { f.SelectUp(moveSize) })
 			case key.Matches(msg, config.Keys.Editor.Selection.SelectDown):
-				f.SelectDown(moveSize)
+				f.MoveCursors(func() { f.SelectDown(moveSize) })
 			case key.Matches(msg, config.Keys.Editor.Selection.SelectAll):
+				f.ResetCursors()
 				f.SelectAll()
+			case key.Matches(msg, config.Keys.Editor.Selection.AddCursorAbove):
+				f.AddCursorAbove()
+			case key.Matches(msg, config.Keys.Editor.Selection.AddCursorBelow):
+				f.AddCursorBelow()
+			case key.Matches(msg, config.Keys.Editor.Selection.AddNextOccurrence):
+				f.AddNextOccurrence()
+			case key.Matches(msg, config.Keys.Editor.Selection.SplitSelectionIntoLines):
+				f.SplitSelectionIntoLines()
 
 			case key.Matches(msg, config.Keys.Editor.File.Save):
 				cmds = append(cmds, file.SaveFile(f.Name()))
 			case key.Matches(msg, config.Keys.Editor.Edit.Tab):
-				cmds = append(cmds, f.InsertRunes([]rune{'\t'}))
+				cmds = append(cmds, f.ForEachCursor(f.InsertTab))
 			case key.Matches(msg, config.Keys.Editor.Edit.RemoveTab):
-				cmds = append(cmds, f.RemoveTab())
+				cmds = append(cmds, f.ForEachCursor(f.RemoveTab))
 			case key.Matches(msg, config.Keys.Editor.Edit.Newline):
-				f.ResetMark()
-				cmds = append(cmds, f.InsertNewLine(), f.Autocomplete().Update())
-			case key.Matches(msg, config.Keys.Editor.Edit.DeleteRight):
-				s := f.Selection()
-				if s != nil {
-					cmds = append(cmds, f.DeleteRange(s.Start, s.End))
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
 					f.ResetMark()
-				} else {
-					cmds = append(cmds, f.DeleteAfter(1))
-				}
+					return f.InsertNewLine()
+				}), f.Autocomplete().Update())
+			case key.Matches(msg, config.Keys.Editor.Edit.DeleteRight):
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+					var cmds []tea.Cmd
+					s := f.Selection()
+					if s != nil {
+						cmds = append(cmds, f.DeleteRange(s.Start, s.End))
+						f.ResetMark()
+					} else {
+						cmds = append(cmds, f.DeleteAfter(1))
+					}
+					return tea.Batch(cmds...)
+				}))
+				cmds = append(cmds, f.SignatureHelp().Update(""))
 			case key.Matches(msg, config.Keys.Editor.Edit.DeleteLeft):
-				s := f.Selection()
-				if s != nil {
-					cmds = append(cmds, f.DeleteRange(s.Start, s.End))
-					f.ResetMark()
-				} else {
-					row, col := f.Cursor()
-					toDelete := f.Buffer().Line(row).RuneBytes(col - 1)
-					cmds = append(cmds, f.DeleteBefore(1))
-					if lang := f.Language(); lang != nil && len(lang.Config.AutoPairs) > 0 {
-						row, col = f.Cursor()
-
-						fileTree := f.Tree()
-						if fileTree != nil {
-							tree := fileTree.FindTree(buffer.Position{
-								Row: row,
-								Col: col,
-							})
-							if tree != nil {
-								node := tree.Tree.RootNode().DescendantForRange(sitter.Point{
-									Row:    uint32(row),
-									Column: uint32(col),
-								},
-									sitter.Point{
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+					var cmds []tea.Cmd
+					s := f.Selection()
+					if s != nil {
+						cmds = append(cmds, f.DeleteRange(s.Start, s.End))
+						f.ResetMark()
+					} else {
+						row, col := f.Cursor()
+						toDelete := f.Buffer().Line(row).RuneBytes(col - 1)
+						cmds = append(cmds, f.DeleteBefore(1))
+						if lang := f.Language(); lang != nil && len(lang.Config.AutoPairs) > 0 {
+							row, col = f.Cursor()
+
+							fileTree := f.Tree()
+							if fileTree != nil {
+								tree := fileTree.FindTree(buffer.Position{
+									Row: row,
+									Col: col,
+								})
+								if tree != nil {
+									node := tree.Tree.RootNode().DescendantForRange(sitter.Point{
 										Row:    uint32(row),
 										Column: uint32(col),
 									},
-								)
-								if node != nil && node.Type() == "string" {
-									log.Println("IN STRING")
+										sitter.Point{
+											Row:    uint32(row),
+											Column: uint32(col),
+										},
+									)
+									if node != nil && node.Type() == "string" {
+										log.Println("IN STRING")
+									}
 								}
 							}
-						}
 
-						for _, pair := range lang.Config.AutoPairs {
-							if string(toDelete) != pair.Open {
-								continue
-							}
-							closeWidth := ansi.StringWidth(pair.Close)
-							behindCursor := f.Buffer().BytesRange(
-								buffer.Position{
-									Row: row,
-									Col: col,
-								},
-								buffer.Position{
-									Row: row,
-									Col: col + closeWidth,
-								},
-							)
-							if string(behindCursor) == pair.Close {
-								cmds = append(cmds, f.Replace(row, col, row, col+closeWidth, nil))
-								break
+							for _, pair := range lang.Config.AutoPairs {
+								if string(toDelete) != pair.Open {
+									continue
+								}
+								closeWidth := ansi.StringWidth(pair.Close)
+								behindCursor := f.Buffer().BytesRange(
+									buffer.Position{
+										Row: row,
+										Col: col,
+									},
+									buffer.Position{
+										Row: row,
+										Col: col + closeWidth,
+									},
+								)
+								if string(behindCursor) == pair.Close {
+									cmds = append(cmds, f.Replace(row, col, row, col+closeWidth, nil))
+									break
+								}
 							}
 						}
 					}
-				}
+					return tea.Batch(cmds...)
+				}))
+				cmds = append(cmds, f.SignatureHelp().Update(""))
 			case key.Matches(msg, config.Keys.Editor.Edit.DuplicateLine):
-				s := f.Selection()
-				if s != nil {
-					cmds = append(cmds, f.Insert(f.SelectionBytes()))
-					f.ResetMark()
-				} else {
-					cmds = append(cmds, f.DuplicateLine())
-				}
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+					if s := f.Selection(); s != nil {
+						cmd := f.Insert(f.SelectionBytes())
+						f.ResetMark()
+						return cmd
+					}
+					return f.DuplicateLine()
+				}))
 			case key.Matches(msg, config.Keys.Editor.Edit.DeleteWordLeft):
-				s := f.Selection()
-				if s != nil {
-					cmds = append(cmds, f.DeleteRange(s.Start, s.End))
-					f.ResetMark()
-				} else {
-					cmds = append(cmds, f.DeleteWordLeft())
-				}
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+					if s := f.Selection(); s != nil {
+						cmd := f.DeleteRange(s.Start, s.End)
+						f.ResetMark()
+						return cmd
+					}
+					return f.DeleteWordLeft()
+				}))
 			case key.Matches(msg, config.Keys.Editor.Edit.DeleteWordRight):
-				s := f.Selection()
-				if s != nil {
-					cmds = append(cmds, f.DeleteRange(s.Start, s.End))
-					f.ResetMark()
-				} else {
-					cmds = append(cmds, f.DeleteWordRight())
-				}
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+					if s := f.Selection(); s != nil {
+						cmd := f.DeleteRange(s.Start, s.End)
+						f.ResetMark()
+						return cmd
+					}
+					return f.DeleteWordRight()
+				}))
 			case key.Matches(msg, config.Keys.Editor.Edit.DeleteLine):
-				s := f.Selection()
-				if s != nil {
-					cmds = append(cmds, f.DeleteRange(s.Start, s.End))
-					f.ResetMark()
-				} else {
-					cmds = append(cmds, f.DeleteLine())
-				}
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+					if s := f.Selection(); s != nil {
+						cmd := f.DeleteRange(s.Start, s.End)
+						f.ResetMark()
+						return cmd
+					}
+					return f.DeleteLine()
+				}))
 			case key.Matches(msg, config.Keys.Editor.Edit.ToggleComment):
-				cmds = append(cmds, f.ToggleComment())
+				cmds = append(cmds, f.ForEachCursor(f.ToggleComment))
+				overwriteCursorBlink = true
+			case key.Matches(msg, config.Keys.Editor.Edit.Reindent):
+				cmds = append(cmds, f.Reindent())
 				overwriteCursorBlink = true
 			case key.Matches(msg, config.Keys.Debug):
 				log.Println("DEBUG")
 
 			default:
 				k := msg.Key()
-				if k.Text == "" || k.Mod.Contains(tea.ModAlt) || k.Mod.Contains(tea.ModCtrl) || k.Mod.Contains(tea.ModMeta) || k.Mod.Contains(tea.ModSuper) || k.Mod.Contains(tea.ModHyper) {
+				if !isTextKey(k) {
 					break
 				}
 
 				text := []byte(k.Text)
-				if s := f.Selection(); s != nil {
-					cmds = append(cmds, f.Replace(s.Start.Row, s.Start.Col, s.End.Row, s.End.Col, text))
-					f.ResetMark()
-				} else {
-					cmds = append(cmds, f.Insert(text))
-				}
-
-				cmds = append(cmds, f.Autocomplete().Update())
+				cmds = append(cmds, f.ForEachCursor(func() tea.Cmd {
+					var cmds []tea.Cmd
+					if s := f.Selection(); s != nil {
+						cmds = append(cmds, f.Replace(s.Start.Row, s.Start.Col, s.End.Row, s.End.Col, text))
+						f.ResetMark()
+					} else {
+						cmds = append(cmds, f.Insert(text))
+					}
 
-				// handle auto pairs
-				if lang := f.Language(); lang != nil && len(lang.Config.AutoPairs) > 0 {
-					for _, pair := range lang.Config.AutoPairs {
-						if string(k.Code) == pair.Open {
-							row, col := f.Cursor()
-							cmds = append(cmds, f.InsertAt(row, col+ansi.StringWidth(pair.Open), []byte(pair.Close)))
-							break
+					// handle auto pairs
+					if lang := f.Language(); lang != nil && len(lang.Config.AutoPairs) > 0 {
+						for _, pair := range lang.Config.AutoPairs {
+							if string(k.Code) == pair.Open {
+								row, col := f.Cursor()
+								cmds = append(cmds, f.InsertAt(row, col+ansi.StringWidth(pair.Open), []byte(pair.Close)))
+								break
+							}
 						}
 					}
-				}
+					return tea.Batch(cmds...)
+				}))
+
+				cmds = append(cmds, f.Autocomplete().Update(), f.SignatureHelp().Update(k.Text))
 			}
 		}
 	}
@@ -1115,8 +1614,7 @@ func (e *Editor) View(width int, height int) string {
 		width -= lipgloss.Width(fileTree)
 	}
 
-	f := e.File()
-	if f == nil {
+	if e.layout.leaf() && e.File() == nil {
 		width -= config.Theme.UI.FileView.EmptyStyle.GetHorizontalBorderSize()
 		height -= config.Theme.UI.FileView.EmptyStyle.GetVerticalBorderSize()
 
@@ -1141,7 +1639,7 @@ func (e *Editor) View(width int, height int) string {
 		height -= lipgloss.Height(searchBar)
 	}
 
-	editor := f.View(width, height, e.fileTree.Visible(), e.treeSitterDebug)
+	editor := e.panesView(width, height, e.fileTree.Visible())
 
 	if searchBar != "" {
 		editor = lipgloss.JoinVertical(lipgloss.Left, searchBar, editor)
@@ -1154,23 +1652,23 @@ func (e *Editor) View(width int, height int) string {
 }
 
 func (e *Editor) refreshActiveFileOffset(width int, fileNames []string) {
-	if e.activeFileOffset == 0 {
+	if e.pane.fileOffset == 0 {
 		return
 	}
 
-	filesWidth := lipgloss.Width(strings.Join(fileNames[:e.activeFile], ""))
+	filesWidth := lipgloss.Width(strings.Join(fileNames[:e.pane.activeFile], ""))
 	if filesWidth < width {
-		e.activeFileOffset = 0
+		e.pane.fileOffset = 0
 	}
 
 	if filesWidth > width {
-		e.activeFileOffset = e.activeFile
+		e.pane.fileOffset = e.pane.activeFile
 	}
 
-	for i := e.activeFileOffset; i > 0; i-- {
-		filesWidth = lipgloss.Width(strings.Join(fileNames[i:e.activeFile], ""))
+	for i := e.pane.fileOffset; i > 0; i-- {
+		filesWidth = lipgloss.Width(strings.Join(fileNames[i:e.pane.activeFile], ""))
 		if filesWidth < width {
-			e.activeFileOffset = i
+			e.pane.fileOffset = i
 			break
 		}
 	}
@@ -1178,7 +1676,7 @@ func (e *Editor) refreshActiveFileOffset(width int, fileNames []string) {
 
 func (e *Editor) FileTabsView(width int) string {
 	var fileNames []string
-	for i, f := range e.files {
+	for i, f := range e.pane.files {
 		var languageName string
 		if f.Language() != nil {
 			languageName = f.Language().Name
@@ -1186,7 +1684,7 @@ func (e *Editor) FileTabsView(width int) string {
 		icon := config.Theme.Icons.FileIcon(languageName).Render()
 
 		style := config.Theme.UI.AppBar.Files.FileStyle
-		if i == e.activeFile {
+		if i == e.pane.activeFile {
 			style = config.Theme.UI.AppBar.Files.SelectedFileStyle
 		}
 
@@ -1221,7 +1719,29 @@ func (e *Editor) FileTabsView(width int) string {
 
 	e.refreshActiveFileOffset(width, fileNames)
 
-	return config.Theme.UI.AppBar.Files.Style.Render(fileNames[e.activeFileOffset:]...)
+	return config.Theme.UI.AppBar.Files.Style.Render(fileNames[e.pane.fileOffset:]...)
+}
+
+// isTextKey returns whether the key inserts its text into the file.
+func isTextKey(k tea.Key) bool {
+	return k.Text != "" && !k.Mod.Contains(tea.ModAlt) && !k.Mod.Contains(tea.ModCtrl) && !k.Mod.Contains(tea.ModMeta) && !k.Mod.Contains(tea.ModSuper) && !k.Mod.Contains(tea.ModHyper)
+}
+
+// isEditKey returns whether the key changes or saves the content of a file.
+func isEditKey(msg tea.KeyPressMsg) bool {
+	edit := config.Keys.Editor.Edit
+	if key.Matches(msg, edit.Paste, edit.Cut, edit.Undo, edit.Redo, edit.Tab, edit.RemoveTab, edit.Newline, edit.DeleteRight, edit.DeleteLeft,
+		edit.DuplicateLine, edit.DeleteWordLeft, edit.DeleteWordRight, edit.DeleteLine, edit.ToggleComment, edit.Reindent, config.Keys.Editor.File.Save, config.Keys.Editor.Code.Format) {
+		return true
+	}
+	return isTextKey(msg.Key())
+}
+
+// isLanguageServerKey returns whether the key sends a request to the language servers.
+func isLanguageServerKey(msg tea.KeyPressMsg) bool {
+	code := config.Keys.Editor.Code
+	return key.Matches(msg, config.Keys.Editor.Hover.Show, config.Keys.Editor.Autocomplete.Show, code.ShowDeclaration, code.ShowDefinitions,
+		code.ShowTypeDefinition, code.ShowImplementation, code.ShowReferences, code.RenameSymbol, code.Format, code.CodeActions)
 }
 
 func clampString(s string, length int) string {
@@ -0,0 +1,66 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+func TestEditor_ApplyWorkspaceEdit(t *testing.T) {
+	version := func(v int32) *int32 {
+		return &v
+	}
+
+	data := []struct {
+		name    string
+		version *int32
+		edits   []ls.TextEdit
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "unversioned",
+			edits: []ls.TextEdit{{Range: buffer.Range{Start: buffer.Position{Col: 0}, End: buffer.Position{Col: 3}}, NewText: "baz"}},
+			want:  "baz\nbar\n",
+		},
+		{
+			name:    "version 0",
+			version: version(0),
+			edits:   []ls.TextEdit{{Range: buffer.Range{Start: buffer.Position{Row: 1, Col: 0}, End: buffer.Position{Row: 1, Col: 3}}, NewText: "baz"}},
+			want:    "foo\nbaz\n",
+		},
+		{
+			name:    "outside of the file",
+			version: version(0),
+			edits:   []ls.TextEdit{{Range: buffer.Range{Start: buffer.Position{Row: 5, Col: 0}, End: buffer.Position{Row: 5, Col: 0}}, NewText: "baz"}},
+			want:    "foo\nbar\n",
+			wantErr: true,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "test.txt")
+			assert.NoError(t, os.WriteFile(name, []byte("foo\nbar\n"), 0o644))
+
+			var e Editor
+			_, err := e.ApplyWorkspaceEdit(ls.WorkspaceEdit{
+				Files: []ls.FileEdit{{Name: name, Version: d.version, Edits: d.edits}},
+			})
+			if d.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			content, err := os.ReadFile(name)
+			assert.NoError(t, err)
+			assert.Equal(t, d.want, string(content))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package file
+
+import (
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+// maxChangeLog is the amount of changes kept to move stored cursor states.
+const maxChangeLog = 1024
+
+// loggedChange is a change of the buffer without its text, it is used to move cursor states taken before the change.
+type loggedChange struct {
+	version int32
+	old     buffer.Range
+	newEnd  buffer.Position
+}
+
+// logChange remembers the change for the current version of the buffer.
+func (f *File) logChange(change Change) {
+	if len(f.changeLog) == maxChangeLog {
+		f.changeLogVersion = f.changeLog[0].version
+		f.changeLog = f.changeLog[1:]
+	}
+
+	f.changeLog = append(f.changeLog, loggedChange{
+		version: f.Version(),
+		old:     change.Range,
+		newEnd:  changeEnd(change),
+	})
+}
+
+// changesSince returns the changes made after the given version.
+// It returns nil if the version is too old to be moved by the logged changes.
+func (f *File) changesSince(version int32) []loggedChange {
+	if version < f.changeLogVersion {
+		return nil
+	}
+	for i, change := range f.changeLog {
+		if change.version > version {
+			return f.changeLog[i:]
+		}
+	}
+	return nil
+}
+
+// shiftLoggedPosition moves the position by the logged changes.
+func shiftLoggedPosition(changes []loggedChange, row int, col int) (int, int) {
+	for _, change := range changes {
+		row, col = movePosition(row, col, change.old, change.newEnd)
+	}
+	return row, col
+}
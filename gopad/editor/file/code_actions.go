@@ -82,3 +82,41 @@ func (f *File) ShowTypeDefinitions() tea.Cmd {
 
 	return ls.GetTypeDefinition(f.Name(), row, col)
 }
+
+func (f *File) ShowImplementations() tea.Cmd {
+	row, col := f.Cursor()
+
+	return ls.GetImplementation(f.Name(), row, col)
+}
+
+func (f *File) ShowReferences() tea.Cmd {
+	row, col := f.Cursor()
+
+	return ls.GetReferences(f.Name(), row, col)
+}
+
+func (f *File) RenameSymbol() tea.Cmd {
+	row, col := f.Cursor()
+
+	return ls.PrepareRename(f.Name(), row, col)
+}
+
+func (f *File) GetCodeActions() tea.Cmd {
+	r := f.Selection()
+	if r == nil {
+		row, col := f.Cursor()
+		r = &buffer.Range{
+			Start: buffer.Position{Row: row, Col: col},
+			End:   buffer.Position{Row: row, Col: col},
+		}
+	}
+
+	var diagnostics []ls.Diagnostic
+	for _, diagnostic := range f.diagnostics {
+		if diagnostic.Range.Overlaps(*r) || r.Overlaps(diagnostic.Range) {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+
+	return ls.GetCodeActions(f.Name(), *r, diagnostics)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/bubbletea/v2"
 
 	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
 	"go.gopad.dev/gopad/internal/bubbles/cursor"
 )
 
@@ -30,6 +31,54 @@ type Mark struct {
 	col int
 }
 
+// CursorState holds the cursors and the view offset of a file.
+// It is used to show the same file in multiple panes with independent cursors.
+type CursorState struct {
+	version int32
+	cursor  Cursor
+	cursors []cursorState
+}
+
+func (f *File) CursorState() CursorState {
+	return CursorState{
+		version: f.Version(),
+		cursor:  f.cursor,
+		cursors: slices.Clone(f.cursors),
+	}
+}
+
+// SetCursorState restores the cursors and the view offset.
+// Positions are moved by the changes made since the state was taken, positions outside the buffer are clamped.
+func (f *File) SetCursorState(s CursorState) {
+	changes := f.changesSince(s.version)
+	lastRow := f.buffer.LinesLen() - 1
+
+	position := func(row int, col int) (int, int) {
+		row, col = shiftLoggedPosition(changes, row, col)
+		row = min(row, lastRow)
+		return row, min(col, f.buffer.LineLen(row))
+	}
+	mark := func(m *Mark) *Mark {
+		if m == nil {
+			return nil
+		}
+		row, col := position(m.row, m.col)
+		return &Mark{row: row, col: col}
+	}
+
+	f.cursor = s.cursor
+	f.cursor.row, f.cursor.col = position(f.cursor.row, f.cursor.col)
+	f.cursor.offsetRow, _ = shiftLoggedPosition(changes, f.cursor.offsetRow, 0)
+	f.cursor.offsetRow = min(f.cursor.offsetRow, lastRow)
+	f.cursor.mark = mark(f.cursor.mark)
+
+	f.cursors = slices.Clone(s.cursors)
+	for i := range f.cursors {
+		f.cursors[i].row, f.cursors[i].col = position(f.cursors[i].row, f.cursors[i].col)
+		f.cursors[i].mark = mark(f.cursors[i].mark)
+	}
+}
+
 func (f *File) Focus() tea.Cmd {
 	return f.cursor.cursor.Focus()
 }
@@ -38,6 +87,11 @@ func (f *File) Blur() {
 	f.cursor.cursor.Blur()
 }
 
+// RefreshStyles applies the styles of the current theme to the cursor.
+func (f *File) RefreshStyles() {
+	f.cursor.cursor.Styles = config.Theme.UI.Cursor
+}
+
 func (f *File) Focused() bool {
 	return f.cursor.cursor.Focused()
 }
@@ -58,7 +112,20 @@ func (f *File) CursorOffset() (int, int) {
 	return f.cursor.offsetRow, f.cursor.offsetCol
 }
 
+func (f *File) SetCursorOffset(row int, col int) {
+	if f.loading {
+		f.loadingCursor.offsetRow, f.loadingCursor.offsetCol = row, col
+		return
+	}
+	f.cursor.offsetRow = min(max(row, 0), f.buffer.LinesLen()-1)
+	f.cursor.offsetCol = max(col, 0)
+}
+
 func (f *File) SetCursor(row, col int) {
+	if f.loading {
+		f.loadingCursor.set(row, col)
+		return
+	}
 	if row > -1 {
 		f.cursor.row = min(max(row, 0), f.buffer.LinesLen()-1)
 		f.cursor.start = false
@@ -190,6 +257,15 @@ func (f *File) MoveCursorUp(count int) {
 }
 
 func (f *File) moveCursorUp(count int) {
+	if f.wrapWidth > 0 {
+		if !f.moveCursorWrapped(-count) {
+			f.cursor.start = true
+			return
+		}
+		f.cursor.end = false
+		return
+	}
+
 	if f.cursor.row == 0 {
 		f.cursor.start = true
 		return
@@ -222,6 +298,15 @@ func (f *File) MoveCursorDown(count int) {
 }
 
 func (f *File) moveCursorDown(count int) {
+	if f.wrapWidth > 0 {
+		if !f.moveCursorWrapped(count) {
+			f.cursor.end = true
+			return
+		}
+		f.cursor.start = false
+		return
+	}
+
 	if f.cursor.row == f.buffer.LinesLen()-1 {
 		f.cursor.end = true
 		return
@@ -376,10 +461,17 @@ func (f *File) refreshCursorViewOffset(width int, height int) {
 		f.cursor.offsetRow = cursorRow
 	}
 
-	if cursorCol >= f.cursor.offsetCol+width {
-		f.cursor.offsetCol = cursorCol - width + 1
-	} else if cursorCol < f.cursor.offsetCol {
+	if cursorCol < f.cursor.offsetCol {
 		f.cursor.offsetCol = cursorCol
+		return
+	}
+
+	// tabs take up more than one column, so the offset is moved until the cursor fits
+	tabSize := f.TabSize()
+	line := f.buffer.Line(cursorRow)
+	cursorVisualCol := visualCol(line, cursorCol, tabSize)
+	for f.cursor.offsetCol < cursorCol && cursorVisualCol-visualCol(line, f.cursor.offsetCol, tabSize) >= width {
+		f.cursor.offsetCol++
 	}
 }
 
@@ -432,3 +524,23 @@ func (f *File) NextWordRight() (int, int) {
 
 	return cursorRow, cursorCol
 }
+
+// WordRange returns the range of the word at the given position.
+func (f *File) WordRange(row int, col int) buffer.Range {
+	line := f.buffer.Line(row)
+
+	start := min(col, line.Len())
+	for start > 0 && !slices.Contains(wordBreakers, line.Rune(start-1)) {
+		start--
+	}
+
+	end := start
+	for end < line.Len() && !slices.Contains(wordBreakers, line.Rune(end)) {
+		end++
+	}
+
+	return buffer.Range{
+		Start: buffer.Position{Row: row, Col: start},
+		End:   buffer.Position{Row: row, Col: end},
+	}
+}
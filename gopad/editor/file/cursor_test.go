@@ -0,0 +1,137 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func TestFile_SetCursorState(t *testing.T) {
+	data := []struct {
+		name    string
+		text    string
+		row     int
+		col     int
+		edit    func(f *File)
+		wantRow int
+		wantCol int
+	}{
+		{
+			name: "insert after",
+			text: "hello\nworld",
+			row:  0,
+			col:  2,
+			edit: func(f *File) {
+				f.InsertAt(1, 0, []byte("big "))
+			},
+			wantRow: 0,
+			wantCol: 2,
+		},
+		{
+			name: "insert before on same line",
+			text: "hello\nworld",
+			row:  1,
+			col:  3,
+			edit: func(f *File) {
+				f.InsertAt(1, 0, []byte("big "))
+			},
+			wantRow: 1,
+			wantCol: 7,
+		},
+		{
+			name: "insert lines before",
+			text: "hello\nworld",
+			row:  1,
+			col:  3,
+			edit: func(f *File) {
+				f.InsertAt(0, 0, []byte("a\nb\n"))
+			},
+			wantRow: 3,
+			wantCol: 3,
+		},
+		{
+			name: "split line before",
+			text: "hello world",
+			row:  0,
+			col:  8,
+			edit: func(f *File) {
+				f.InsertAt(0, 5, []byte("\n"))
+			},
+			wantRow: 1,
+			wantCol: 3,
+		},
+		{
+			name: "delete lines before",
+			text: "a\nb\nc\nhello",
+			row:  3,
+			col:  4,
+			edit: func(f *File) {
+				f.DeleteRange(buffer.Position{Row: 0, Col: 0}, buffer.Position{Row: 2, Col: 0})
+			},
+			wantRow: 1,
+			wantCol: 4,
+		},
+		{
+			name: "delete around",
+			text: "hello world",
+			row:  0,
+			col:  8,
+			edit: func(f *File) {
+				f.DeleteRange(buffer.Position{Row: 0, Col: 5}, buffer.Position{Row: 0, Col: 11})
+			},
+			wantRow: 0,
+			wantCol: 5,
+		},
+		{
+			name: "delete end of line",
+			text: "hello\nworld",
+			row:  1,
+			col:  5,
+			edit: func(f *File) {
+				f.DeleteLine()
+			},
+			wantRow: 0,
+			wantCol: 5,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+
+			f.SetCursor(d.row, d.col)
+			s := f.CursorState()
+
+			f.SetCursor(f.buffer.LinesLen()-1, 0)
+			d.edit(f)
+
+			f.SetCursorState(s)
+			row, col := f.Cursor()
+			assert.Equal(t, d.wantRow, row)
+			assert.Equal(t, d.wantCol, col)
+		})
+	}
+}
+
+func TestFile_SetCursorState_secondaryCursors(t *testing.T) {
+	b, err := buffer.New("test.txt", bytes.NewReader([]byte("foo bar foo")), "utf-8", buffer.LineEndingLF, false)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeWrite)
+
+	f.SetCursor(0, 1)
+	f.AddNextOccurrence()
+	f.AddNextOccurrence()
+	s := f.CursorState()
+
+	f.InsertAt(0, 0, []byte("x\n"))
+
+	// the selection of the secondary cursor moves with it
+	f.SetCursorState(s)
+	assert.Equal(t, []cursorState{{row: 1, col: 3, mark: &Mark{row: 1, col: 0}}}, f.cursors)
+	assert.Equal(t, &buffer.Range{Start: buffer.Position{Row: 1, Col: 8}, End: buffer.Position{Row: 1, Col: 11}}, f.Selection())
+}
@@ -0,0 +1,79 @@
+package file
+
+import (
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+// EditorConfigForName returns the editor config for a file name with the overrides of its language applied.
+func EditorConfigForName(name string) config.EditorConfig {
+	return editorConfig(GetLanguageByFilename(name))
+}
+
+func editorConfig(language *Language) config.EditorConfig {
+	if language == nil {
+		return config.Gopad.Editor
+	}
+	return config.Gopad.Editor.WithOverrides(language.Config.Editor)
+}
+
+// EditorConfig returns the editor config of the file with the overrides of its language applied.
+func (f *File) EditorConfig() config.EditorConfig {
+	return editorConfig(f.language)
+}
+
+// TabSize returns the width of a tab character.
+func (f *File) TabSize() int {
+	return max(f.EditorConfig().TabSize, 1)
+}
+
+// VisualCol returns the screen column of the col in the row with tabs expanded to the next tab stop.
+func (f *File) VisualCol(row int, col int) int {
+	return visualCol(f.buffer.Line(row), col, f.TabSize())
+}
+
+func visualCol(line buffer.Line, col int, tabSize int) int {
+	var n int
+	for i := range min(col, line.Len()) {
+		if line.Rune(i) == '\t' {
+			n += tabSize - n%tabSize
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+func (f *File) refreshSaveOptions() {
+	cfg := f.EditorConfig()
+	f.buffer.SetSaveOptions(buffer.SaveOptions{
+		TrimTrailingWhitespace: cfg.TrimTrailingWhitespace,
+		InsertFinalNewline:     cfg.InsertFinalNewline,
+	})
+}
+
+// Normalize trims trailing whitespace and inserts the final newline as a single undo step according to the save options of the buffer.
+// This keeps tree-sitter and language servers in sync with the changes buffer.Save would otherwise apply.
+func (f *File) Normalize() tea.Cmd {
+	options := f.buffer.SaveOptions()
+
+	var edits []ls.TextEdit
+	if options.TrimTrailingWhitespace {
+		for _, r := range f.buffer.TrailingWhitespace() {
+			edits = append(edits, ls.TextEdit{Range: r})
+		}
+	}
+	if options.InsertFinalNewline && !f.buffer.HasFinalNewline() {
+		row := f.buffer.LinesLen() - 1
+		end := buffer.Position{Row: row, Col: f.buffer.LineLen(row)}
+		edits = append(edits, ls.TextEdit{
+			Range:   buffer.Range{Start: end, End: end},
+			NewText: "\n",
+		})
+	}
+
+	return f.ApplyTextEdits(edits)
+}
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"cmp"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss"
@@ -36,6 +38,7 @@ const (
 	ZoneFileLineNumberPrefix     = "file.line.number:"
 	ZoneFileDiagnosticPrefix     = "file.diagnostic:"
 	ZoneFileLineDiagnosticPrefix = "file.line.diagnostic:"
+	ZoneFileHover                = "file.hover"
 )
 
 func zoneFileLineEmptyID(line int) string {
@@ -62,8 +65,13 @@ type Change struct {
 	StartIndex  uint32
 	OldEndIndex uint32
 	NewEndIndex uint32
+	StartPoint  sitter.Point
+	OldEndPoint sitter.Point
+	NewEndPoint sitter.Point
 
-	Text []byte
+	// Range is the replaced range in the previous content and NewText the text which replaced it.
+	Range   buffer.Range
+	NewText []byte
 }
 
 func NewFileWithBuffer(b *buffer.Buffer, mode Mode) *File {
@@ -77,9 +85,14 @@ func NewFileWithBuffer(b *buffer.Buffer, mode Mode) *File {
 		},
 		language:           GetLanguageByFilename(b.Name()),
 		diagnosticVersions: map[ls.DiagnosticType]int32{},
+		changedVersion:     b.Version(),
+		changeLogVersion:   b.Version(),
 	}
 
 	f.autocomplete = NewAutocompleter(f)
+	f.hover = NewHover(f)
+	f.signatureHelp = NewSignatureHelp(f)
+	f.refreshSaveOptions()
 
 	return f
 }
@@ -98,39 +111,80 @@ func NewFileFromName(name string) (*File, error) {
 		return nil, fmt.Errorf("file is not readable")
 	}
 
-	b, err := buffer.NewFromFile(name, "UTF-8", buffer.LineEndingAuto)
+	cfg := EditorConfigForName(name)
+	large := isLargeFile(stat)
+	lazy := large && strings.EqualFold(cmp.Or(cfg.Charset, "utf-8"), "utf-8")
+	b, err := newBufferFromFile(name, cmp.Or(cfg.Charset, "utf-8"), lazy)
 	if err != nil {
 		return nil, err
 	}
+	setDefaultLineEnding(b, cfg)
 
 	mode := ModeWrite
-	if stat.Mode().Perm()&0200 == 0 {
+	if stat.Mode().Perm()&0200 == 0 || large {
 		mode = ModeReadOnly
 	}
 
-	return NewFileWithBuffer(b, mode), nil
+	f := NewFileWithBuffer(b, mode)
+	f.large = large
+	f.loading = lazy
+	return f, nil
+}
+
+// setDefaultLineEnding sets the configured line ending, files without any line break don't tell us which line ending to use.
+func setDefaultLineEnding(b *buffer.Buffer, cfg config.EditorConfig) {
+	if lineEnding := buffer.ParseLineEnding(cfg.EndOfLine); b.LinesLen() == 1 && lineEnding != buffer.LineEndingAuto {
+		b.SetLineEnding(lineEnding)
+	}
+}
+
+// isLargeFile returns whether the file exceeds the configured large file size.
+func isLargeFile(stat os.FileInfo) bool {
+	size := config.Gopad.FileView.LargeFileSize
+	return size > 0 && uint64(stat.Size()) > uint64(size)
+}
+
+// newBufferFromFile reads the file.
+// Lazily loaded files are indexed by LoadLazy, until then an empty buffer is returned.
+func newBufferFromFile(name string, charset string, lazy bool) (*buffer.Buffer, error) {
+	if lazy {
+		return newEmptyBuffer(name)
+	}
+	return buffer.NewFromFile(name, charset, buffer.LineEndingAuto)
 }
 
 type File struct {
 	buffer                *buffer.Buffer
 	mode                  Mode
+	large                 bool
+	loading               bool
+	loadingCursor         loadingCursor
 	cursor                Cursor
+	cursors               []cursorState
 	language              *Language
 	tree                  *Tree
 	autocomplete          *Autocompleter
+	hover                 *Hover
+	signatureHelp         *SignatureHelp
 	showCurrentDiagnostic bool
+	hidePopups            bool
 
 	diagnosticVersions map[ls.DiagnosticType]int32
 	diagnostics        []ls.Diagnostic
 	inlayHintsVersion  int32
 	inlayHints         []ls.InlayHint
-	matchesVersion     int32
-	matches            [][]*Match
-	changes            []Change
+	highlights         [][]lineMatch
+	history            history
+	batch              []Change
+	changedVersion     int32
+	changeLog          []loggedChange
+	changeLogVersion   int32
 	definitions        []ls.Definition
 	typeDefinitions    []ls.TypeDefinition
 	definitionsIndex   int
 	positions          [][]pos
+	// wrapWidth is the width lines are wrapped at in the last rendered view, 0 if lines are not wrapped
+	wrapWidth int
 }
 
 func (f *File) Name() string {
@@ -170,20 +224,34 @@ func (f *File) SetMode(mode Mode) {
 	f.mode = mode
 }
 
+// LargeFile returns whether the file exceeded the large file size when it was opened.
+func (f *File) LargeFile() bool {
+	return f.large
+}
+
 func (f *File) Language() *Language {
 	return f.language
 }
 
+// LanguageID returns the language identifier of the file sent to language servers.
+func (f *File) LanguageID() string {
+	if f.language == nil {
+		return "plaintext"
+	}
+	return f.language.LanguageID()
+}
+
 func (f *File) SetLanguage(name string) {
 	language := GetLanguage(name)
 	if language == nil {
 		return
 	}
 	f.language = language
+	f.refreshSaveOptions()
 
-	// reset tree and matches when changing language
+	// reset tree and highlights when changing language
 	f.tree = nil
-	f.matches = nil
+	f.highlights = nil
 }
 
 func (f *File) Buffer() *buffer.Buffer {
@@ -194,6 +262,14 @@ func (f *File) Autocomplete() *Autocompleter {
 	return f.autocomplete
 }
 
+func (f *File) Hover() *Hover {
+	return f.hover
+}
+
+func (f *File) SignatureHelp() *SignatureHelp {
+	return f.signatureHelp
+}
+
 func (f *File) Range() buffer.Range {
 	return buffer.Range{
 		Start: buffer.Position{Row: 0, Col: 0},
@@ -214,52 +290,172 @@ func (f *File) Dirty() bool {
 }
 
 func (f *File) recordChange(change Change) tea.Cmd {
+	if f.batch != nil {
+		f.batch = append(f.batch, change)
+		return nil
+	}
+
+	return f.applyChanges([]Change{change})
+}
+
+// BeginBatch collects all following edits into a single undo step, tree-sitter update and language server change until EndBatch is called.
+func (f *File) BeginBatch() {
+	f.history.lastGroup++
+	f.history.group = f.history.lastGroup
+	f.batch = make([]Change, 0, 1)
+}
+
+// EndBatch closes the batch opened by BeginBatch and applies the collected changes.
+func (f *File) EndBatch() tea.Cmd {
+	f.history.group = 0
+	return f.flushBatch()
+}
+
+func (f *File) flushBatch() tea.Cmd {
+	changes := f.batch
+	f.batch = nil
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return f.applyChanges(changes)
+}
+
+func (f *File) applyChanges(changes []Change) tea.Cmd {
 	now := time.Now()
 	defer func() {
 		log.Println("record change time: ", time.Since(now))
 	}()
 
+	edits := make([]sitter.EditInput, 0, len(changes))
+	textChanges := make([]ls.TextChange, 0, len(changes))
+	for _, change := range changes {
+		edits = append(edits, sitter.EditInput{
+			StartIndex:  change.StartIndex,
+			OldEndIndex: change.OldEndIndex,
+			NewEndIndex: change.NewEndIndex,
+			StartPoint:  change.StartPoint,
+			OldEndPoint: change.OldEndPoint,
+			NewEndPoint: change.NewEndPoint,
+		})
+		f.shiftHighlights(change)
+		f.shiftCursors(change)
+		f.logChange(change)
+		textChanges = append(textChanges, ls.TextChange{
+			Range: change.Range,
+			Text:  change.NewText,
+		})
+	}
+
 	var cmds []tea.Cmd
-	if cmd := f.UpdateTree(sitter.EditInput{
-		StartIndex:  change.StartIndex,
-		OldEndIndex: change.OldEndIndex,
-		NewEndIndex: change.NewEndIndex,
-	}); cmd != nil {
+	if cmd := f.UpdateTree(edits...); cmd != nil {
 		cmds = append(cmds, cmd)
 	}
 
-	f.changes = append(f.changes, change)
-
+	// the version the changes were made on lets the language servers detect changes delivered out of order
+	prevVersion := f.changedVersion
+	f.changedVersion = f.Version()
 	cmds = append(cmds, tea.Sequence(
-		ls.FileChanged(f.Name(), f.Version(), change.Text),
+		ls.FileChanged(f.Name(), prevVersion, f.Version(), f.buffer.Copy(), textChanges),
 		ls.GetInlayHint(f.Name(), f.Version(), f.Range()),
 	))
 
 	return tea.Batch(cmds...)
 }
 
+type pendingChange struct {
+	from        buffer.Position
+	to          buffer.Position
+	startIndex  int
+	oldEndIndex int
+	startPoint  sitter.Point
+	oldEndPoint sitter.Point
+}
+
+// beginChange remembers the range between from and to before it is replaced.
+func (f *File) beginChange(from buffer.Position, to buffer.Position) pendingChange {
+	return pendingChange{
+		from:        from,
+		to:          to,
+		startIndex:  f.buffer.ByteIndex(from.Row, from.Col),
+		oldEndIndex: f.buffer.ByteIndex(to.Row, to.Col),
+		startPoint:  f.point(from),
+		oldEndPoint: f.point(to),
+	}
+}
+
+// point returns the tree-sitter point of the position, its column is counted in bytes.
+func (f *File) point(p buffer.Position) sitter.Point {
+	return sitter.Point{
+		Row:    uint32(p.Row),
+		Column: uint32(f.buffer.Line(p.Row).EncodeCol(p.Col, buffer.PositionEncodingUTF8)),
+	}
+}
+
+// endChange returns the Change replacing the range passed to beginChange with the text up to newEnd.
+func (f *File) endChange(p pendingChange, newEnd buffer.Position) Change {
+	return Change{
+		StartIndex:  uint32(p.startIndex),
+		OldEndIndex: uint32(p.oldEndIndex),
+		NewEndIndex: uint32(f.buffer.ByteIndex(newEnd.Row, newEnd.Col)),
+		StartPoint:  p.startPoint,
+		OldEndPoint: p.oldEndPoint,
+		NewEndPoint: f.point(newEnd),
+		Range: buffer.Range{
+			Start: p.from,
+			End:   p.to,
+		},
+		NewText: f.buffer.BytesRange(p.from, newEnd),
+	}
+}
+
+func (f *File) positionBefore(row int, col int, count int) buffer.Position {
+	for range count {
+		if col > 0 {
+			col--
+		} else if row > 0 {
+			row--
+			col = f.buffer.LineLen(row)
+		}
+	}
+	return buffer.Position{Row: row, Col: col}
+}
+
+func (f *File) positionAfter(row int, col int, count int) buffer.Position {
+	for range count {
+		if col < f.buffer.LineLen(row) {
+			col++
+		} else if row < f.buffer.LinesLen()-1 {
+			row++
+			col = 0
+		}
+	}
+	return buffer.Position{Row: row, Col: col}
+}
+
+// InsertNewLine splits the line at the cursor and indents the new line.
+// If the new line starts with a closing node, an indented empty line is inserted before it.
 func (f *File) InsertNewLine() tea.Cmd {
 	row, col := f.Cursor()
-	startIndex := f.buffer.ByteIndex(row, col)
-
-	f.SetCursor(f.buffer.InsertNewLine(row, col))
-	if len(f.matches) > row {
-		log.Println("inserting new line")
-		for _, lineMatches := range f.matches[row:] {
-			for _, match := range lineMatches {
-				match.Range.Start.Row++
-				match.Range.End.Row++
-			}
-		}
-		f.matches = slices.Insert(f.matches, row, make([]*Match, 0))
+	edit := f.beginEdit(editKindOther, row, row)
+	pos := buffer.Position{Row: row, Col: col}
+	change := f.beginChange(pos, pos)
+
+	indent, inner := f.newLineIndent(row, col)
+	text := "\n" + indent
+	if inner != nil {
+		text = "\n" + *inner + text
+	}
+
+	newRow, newCol := f.buffer.Insert(row, col, []byte(text))
+	if inner != nil {
+		f.SetCursor(row+1, utf8.RuneCountInString(*inner))
+	} else {
+		f.SetCursor(newRow, newCol)
 	}
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex + 2),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, buffer.Position{Row: newRow, Col: newCol}))
 }
 
 func (f *File) Insert(text []byte) tea.Cmd {
@@ -269,16 +465,19 @@ func (f *File) Insert(text []byte) tea.Cmd {
 	}
 
 	row, col := f.Cursor()
-	startIndex := f.buffer.ByteIndex(row, col)
+	kind := editKindOther
+	if utf8.RuneCount(text) == 1 {
+		kind = editKindInsert
+	}
+	edit := f.beginEdit(kind, row, row)
+	pos := buffer.Position{Row: row, Col: col}
+	change := f.beginChange(pos, pos)
 
-	f.SetCursor(f.buffer.Insert(row, col, text))
+	newRow, newCol := f.buffer.Insert(row, col, text)
+	f.SetCursor(newRow, newCol)
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex + len(text) + 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, buffer.Position{Row: newRow, Col: newCol}))
 }
 
 func (f *File) InsertRunes(text []rune) tea.Cmd {
@@ -291,149 +490,148 @@ func (f *File) InsertAt(row int, col int, text []byte) tea.Cmd {
 		return nil
 	}
 
-	startIndex := f.buffer.ByteIndex(row, col)
+	edit := f.beginEdit(editKindOther, row, row)
+	pos := buffer.Position{Row: row, Col: col}
+	change := f.beginChange(pos, pos)
 
-	f.buffer.Insert(row, col, text)
+	newRow, newCol := f.buffer.Insert(row, col, text)
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex + len(text) + 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, buffer.Position{Row: newRow, Col: newCol}))
 }
 
 func (f *File) Replace(fromRow int, fromCol int, toRow int, toCol int, text []byte) tea.Cmd {
 	text = xrunes.Sanitize(text)
 
-	startIndex := f.buffer.ByteIndex(fromRow, fromCol)
-	endIndex := f.buffer.ByteIndex(toRow, toCol)
+	edit := f.beginEdit(editKindOther, fromRow, toRow)
+	change := f.beginChange(buffer.Position{Row: fromRow, Col: fromCol}, buffer.Position{Row: toRow, Col: toCol})
 
-	f.SetCursor(f.buffer.Replace(fromRow, fromCol, toRow, toCol, text))
+	row, col := f.buffer.Replace(fromRow, fromCol, toRow, toCol, text)
+	f.SetCursor(row, col)
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(endIndex),
-		NewEndIndex: uint32(startIndex + len(text)),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, buffer.Position{Row: row, Col: col}))
 }
 
 func (f *File) DuplicateLine() tea.Cmd {
 	row, _ := f.Cursor()
-	line := f.buffer.Line(row)
-	startIndex := f.buffer.ByteIndex(row, 0)
+	edit := f.beginEdit(editKindOther, row, row)
+	end := buffer.Position{Row: row, Col: f.buffer.LineLen(row)}
+	change := f.beginChange(end, end)
 
-	f.SetCursor(f.buffer.DuplicateLine(row), -1)
+	newRow := f.buffer.DuplicateLine(row)
+	f.SetCursor(newRow, -1)
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex + line.LenBytes() + 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, buffer.Position{Row: newRow, Col: f.buffer.LineLen(newRow)}))
 }
 
 func (f *File) DeleteLine() tea.Cmd {
 	row, _ := f.Cursor()
-	line := f.buffer.Line(row)
-	startIndex := f.buffer.ByteIndex(row, 0)
+	edit := f.beginEdit(editKindOther, row-1, row+1)
+
+	from := buffer.Position{Row: row, Col: 0}
+	to := buffer.Position{Row: row + 1, Col: 0}
+	if row == f.buffer.LinesLen()-1 {
+		to = buffer.Position{Row: row, Col: f.buffer.LineLen(row)}
+		if row > 0 {
+			from = buffer.Position{Row: row - 1, Col: f.buffer.LineLen(row - 1)}
+		}
+	}
+	change := f.beginChange(from, to)
 
 	f.SetCursor(f.buffer.DeleteLine(row), -1)
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + line.LenBytes() + 1),
-		NewEndIndex: uint32(startIndex + 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, from))
 }
 
 func (f *File) DeleteBefore(count int) tea.Cmd {
 	row, col := f.Cursor()
-	startIndex := f.buffer.ByteIndex(row, col)
+	edit := f.beginEdit(editKindDelete, row-count, row)
+	from := f.positionBefore(row, col, count)
+	change := f.beginChange(from, buffer.Position{Row: row, Col: col})
 
 	f.SetCursor(f.buffer.DeleteBefore(row, col, count))
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex - count),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex - count + 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, from))
 }
 
 func (f *File) DeleteAfter(count int) tea.Cmd {
 	row, col := f.Cursor()
-	startIndex := f.buffer.ByteIndex(row, col)
+	edit := f.beginEdit(editKindDelete, row, row+count)
+	from := buffer.Position{Row: row, Col: col}
+	change := f.beginChange(from, f.positionAfter(row, col, count))
 
 	f.SetCursor(f.buffer.DeleteAfter(row, col, count))
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex + 1 - count),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, from))
 }
 
 func (f *File) DeleteRange(from buffer.Position, to buffer.Position) tea.Cmd {
-	startIndex := f.buffer.ByteIndex(from.Row, from.Col)
-	endIndex := f.buffer.ByteIndex(to.Row, to.Col)
+	edit := f.beginEdit(editKindOther, from.Row, to.Row)
+	change := f.beginChange(from, to)
 
 	f.SetCursor(f.buffer.DeleteRange(from.Row, from.Col, to.Row, to.Col))
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(endIndex),
-		NewEndIndex: uint32(startIndex),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, from))
 }
 
 func (f *File) DeleteWordLeft() tea.Cmd {
 	row, col := f.Cursor()
-	startIndex := f.buffer.ByteIndex(row, col)
 
 	wRow, wCol := f.NextWordLeft()
+	from := buffer.Position{Row: wRow, Col: wCol}
+	edit := f.beginEdit(editKindOther, wRow, row)
+	change := f.beginChange(from, buffer.Position{Row: row, Col: col})
+
 	f.SetCursor(f.buffer.DeleteRange(wRow, wCol, row, col))
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex + 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, from))
 }
 
 func (f *File) DeleteWordRight() tea.Cmd {
 	row, col := f.Cursor()
-	startIndex := f.buffer.ByteIndex(row, col)
 
 	wRow, wCol := f.NextWordRight()
+	from := buffer.Position{Row: row, Col: col}
+	edit := f.beginEdit(editKindOther, row, wRow)
+	change := f.beginChange(from, buffer.Position{Row: wRow, Col: wCol})
+
 	f.SetCursor(f.buffer.DeleteRange(row, col, wRow, wCol))
+	f.commitEdit(edit)
+
+	return f.recordChange(f.endChange(change, from))
+}
+
+// InsertTab inserts a tab character or spaces up to the next indent stop depending on the indent style.
+func (f *File) InsertTab() tea.Cmd {
+	cfg := f.EditorConfig()
+	if cfg.IndentStyle != config.IndentStyleSpace {
+		return f.InsertRunes([]rune{'\t'})
+	}
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + 1),
-		NewEndIndex: uint32(startIndex + 1),
-		Text:        f.buffer.Bytes(),
-	})
+	row, col := f.Cursor()
+	size := max(cfg.IndentSize, 1)
+	return f.Insert([]byte(strings.Repeat(" ", size-f.VisualCol(row, col)%size)))
 }
 
 func (f *File) RemoveTab() tea.Cmd {
 	row, col := f.Cursor()
-	line := f.buffer.Line(row)
-	startIndex := f.buffer.ByteIndex(row, 0)
+	size := max(f.EditorConfig().IndentSize, 1)
+	edit := f.beginEdit(editKindOther, row, row)
+	from := buffer.Position{Row: row, Col: 0}
+	to := buffer.Position{Row: row, Col: f.buffer.LeadingIndent(row, size)}
+	change := f.beginChange(from, to)
 
-	f.SetCursor(f.buffer.RemoveTab(row, col))
+	f.SetCursor(f.buffer.RemoveTab(row, col, size))
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + line.LenBytes() + 1),
-		NewEndIndex: uint32(startIndex + line.LenBytes() - 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, from))
 }
 
 func (f *File) ToggleComment() tea.Cmd {
@@ -457,22 +655,18 @@ func (f *File) ToggleBlockComment(start buffer.Position, end buffer.Position) te
 	row, col := f.Cursor()
 
 	r := f.Selection()
-	startIndex := f.buffer.ByteIndex(r.Start.Row, r.Start.Col)
-	endIndex := f.buffer.ByteIndex(r.End.Row, r.End.Col)
+	linesLen := f.buffer.LinesLen()
+	edit := f.beginEdit(editKindOther, r.Start.Row, r.End.Row)
+	change := f.beginChange(
+		buffer.Position{Row: r.Start.Row, Col: 0},
+		buffer.Position{Row: r.End.Row, Col: f.buffer.LineLen(r.End.Row)},
+	)
 
 	f.SetCursor(f.buffer.ToggleBlockComment(r.Start, r.End, row, col, f.language.Config.BlockCommentTokens))
+	f.commitEdit(edit)
 
-	var newRuneCount int
-	for i := r.Start.Row; i <= r.End.Row; i++ {
-		newRuneCount += f.buffer.Line(i).Len()
-	}
-
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(endIndex),
-		NewEndIndex: uint32(startIndex + newRuneCount),
-		Text:        f.buffer.Bytes(),
-	})
+	endRow := r.End.Row + f.buffer.LinesLen() - linesLen
+	return f.recordChange(f.endChange(change, buffer.Position{Row: endRow, Col: f.buffer.LineLen(endRow)}))
 }
 
 func (f *File) ToggleLineComment() tea.Cmd {
@@ -481,17 +675,16 @@ func (f *File) ToggleLineComment() tea.Cmd {
 	}
 
 	row, col := f.Cursor()
-	line := f.buffer.Line(row)
-	startIndex := f.buffer.ByteIndex(row, 0)
+	edit := f.beginEdit(editKindOther, row, row)
+	change := f.beginChange(
+		buffer.Position{Row: row, Col: 0},
+		buffer.Position{Row: row, Col: f.buffer.LineLen(row)},
+	)
 
 	f.SetCursor(f.buffer.ToggleLineComment(row, col, f.language.Config.LineCommentTokens))
+	f.commitEdit(edit)
 
-	return f.recordChange(Change{
-		StartIndex:  uint32(startIndex),
-		OldEndIndex: uint32(startIndex + line.LenBytes() + 1),
-		NewEndIndex: uint32(startIndex + line.LenBytes() - 1),
-		Text:        f.buffer.Bytes(),
-	})
+	return f.recordChange(f.endChange(change, buffer.Position{Row: row, Col: f.buffer.LineLen(row)}))
 }
 
 func (f File) GetCursorForCharPos(row int, col int) (int, int) {
@@ -501,9 +694,14 @@ func (f File) GetCursorForCharPos(row int, col int) (int, int) {
 	}
 
 	linePositions := f.positions[positionRow]
-	if col >= len(linePositions) {
+	if len(linePositions) == 0 {
 		return row, f.buffer.LineLen(row)
 	}
+	if col >= len(linePositions) {
+		// wrapped rows end before the end of the line
+		p := linePositions[len(linePositions)-1]
+		return p.row, p.col
+	}
 
 	p := linePositions[col]
 	return p.row, p.col
@@ -521,6 +719,106 @@ type pos struct {
 	col int
 }
 
+// codeLineView renders count columns of the line starting at start and returns the buffer position of every screen column.
+func (f *File) codeLineView(ln int, start int, count int, cursorRow int, cursorCol int, selections []buffer.Range, codeLineCharStyle lipgloss.Style) ([]byte, []pos) {
+	styles := config.Theme.UI
+	tabSize := f.TabSize()
+	line := f.buffer.Line(ln)
+	chars := line.RuneStrings()
+	lineCol := visualCol(line, start, tabSize)
+
+	var (
+		linePositions []pos
+		colOffset     int
+		codeLine      []byte
+	)
+	for ii := range count {
+		col := ii + start
+
+		if col <= line.Len() {
+			for len(linePositions) <= ii+colOffset {
+				linePositions = append(linePositions, pos{row: ln, col: col})
+			}
+		}
+
+		inSelection := slices.ContainsFunc(selections, func(s buffer.Range) bool {
+			return s.Contains(buffer.Position{Row: ln, Col: col})
+		})
+
+		var char string
+		if col > len(chars) {
+			codeLine = append(codeLine, codeLineCharStyle.Render(" ")...)
+			break
+		} else if col == len(chars) {
+			char = " "
+		} else {
+			char = chars[col]
+		}
+
+		// Replace tabs with spaces up to the next tab stop
+		var tabPadding string
+		if char == "\t" {
+			char = " "
+			tabWidth := tabSize - lineCol%tabSize
+			tabPadding = strings.Repeat(" ", tabWidth-1)
+			colOffset += tabWidth - 1
+			lineCol += tabWidth
+		} else {
+			lineCol++
+		}
+
+		style := f.HighestMatchStyle(codeLineCharStyle, ln, col)
+		style = f.HighestLineColDiagnosticStyle(style, ln, col)
+
+		if (ln == cursorRow && col == cursorCol) || f.hasSecondaryCursor(ln, col) {
+			char = f.cursor.cursor.View(char, style)
+			if tabPadding != "" {
+				tabPadding = style.Render(tabPadding)
+			}
+		} else if inSelection {
+			char = styles.FileView.SelectionStyle.Inherit(style).Render(char + tabPadding)
+			tabPadding = ""
+		} else {
+			char = style.Render(char + tabPadding)
+			tabPadding = ""
+		}
+		codeLine = append(codeLine, char...)
+		codeLine = append(codeLine, tabPadding...)
+
+		paddingStyle := codeLineCharStyle
+		labelStyle := styles.FileView.InlayHintStyle
+		if inSelection {
+			paddingStyle = styles.FileView.SelectionStyle.Inherit(paddingStyle)
+			labelStyle = styles.FileView.SelectionStyle.Inherit(labelStyle)
+		}
+		for _, hint := range f.InlayHintsForLineCol(ln, col+1) {
+			var label string
+			if hint.PaddingLeft {
+				label += paddingStyle.Render(" ")
+			}
+			label += labelStyle.Render(hint.Label)
+			if hint.PaddingRight {
+				label += paddingStyle.Render(" ")
+			}
+			codeLine = append(codeLine, label...)
+			colOffset += lipgloss.Width(label)
+		}
+	}
+
+	return codeLine, linePositions
+}
+
+// ViewWithCursorState renders the file blurred with the cursors of the state, the file itself is not changed.
+// The popups belong to the active view of the file and are not shown.
+func (f *File) ViewWithCursorState(s CursorState, width int, height int, border bool) string {
+	v := *f
+	v.SetCursorState(s)
+	v.Blur()
+	v.showCurrentDiagnostic = false
+	v.hidePopups = true
+	return v.View(width, height, border, false)
+}
+
 func (f *File) View(width int, height int, border bool, debug bool) string {
 	styles := config.Theme.UI
 	borderStyle := func(strs ...string) string { return strings.Join(strs, " ") }
@@ -536,20 +834,41 @@ func (f *File) View(width int, height int, border bool, debug bool) string {
 		height = max(height-4, 0)
 	}
 
-	f.refreshCursorViewOffset(width-2, height)
+	wordWrap := f.wordWrap()
+	f.wrapWidth = 0
+	if wordWrap {
+		// keep one column free for the cursor at the end of a row
+		f.wrapWidth = max(width-1, 1)
+		f.refreshWrapViewOffset(f.wrapWidth, height)
+	} else {
+		f.refreshCursorViewOffset(width-2, height)
+	}
 	cursorRow, cursorCol := f.Cursor()
 	offsetRow, offsetCol := f.CursorOffset()
 	realCursorRow := cursorRow - offsetRow
 	realCursorCol := cursorCol - offsetCol
 
-	selection := f.Selection()
+	selections := f.Selections()
+
+	var rows []viewRow
+	if wordWrap {
+		rows = f.wrapRows(f.wrapWidth, height)
+	} else {
+		for i := range height {
+			rows = append(rows, viewRow{
+				row:   i + offsetRow,
+				start: offsetCol,
+				// always draw one character off the screen to ensure the cursor is visible
+				count: width - prefixWidth + 1,
+				last:  true,
+			})
+		}
+	}
 
 	var editorCode string
 	positions := make([][]pos, max(height, 0))
-	for i := range height {
-		ln := i + offsetRow
-
-		var linePositions []pos
+	for i, r := range rows {
+		ln := r.row
 
 		codeLineStyle := styles.FileView.LineStyle
 		codePrefixStyle := styles.FileView.LinePrefixStyle
@@ -568,86 +887,28 @@ func (f *File) View(width int, height int, border bool, debug bool) string {
 		lineDiagnostic, lineDiagnosticIndex := f.HighestLineDiagnostic(ln)
 
 		var prefix string
-		if lineDiagnostic.Severity > 0 {
-			prefix = zone.Mark(zoneFileLineDiagnosticID(lineDiagnosticIndex), lineDiagnostic.Severity.Icon().Render())
+		if r.wrapped {
+			prefix = " " + codePrefixStyle.Render(strings.Repeat(" ", max(prefixWidth-1, 0))+wrapIndicator)
 		} else {
-			prefix = " "
-		}
-
-		prefixLn := strconv.Itoa(ln + 1)
-		prefix += zone.Mark(zoneFileLineNumberID(ln), codePrefixStyle.Render(strings.Repeat(" ", prefixWidth-lipgloss.Width(prefixLn))+prefixLn))
-
-		line := f.buffer.Line(ln)
-		if line.Len() < offsetCol {
-			editorCode += borderStyle(codeLineStyle.Render(prefix)) + "\n"
-			continue
-		}
-
-		chars := line.RuneStrings()
-		var colOffset int
-		var codeLine []byte
-		// always draw one character off the screen to ensure the cursor is visible
-		for ii := range width - prefixWidth + 1 {
-			col := ii + offsetCol
-
-			if col <= line.Len() {
-				for len(linePositions) <= ii+colOffset {
-					linePositions = append(linePositions, pos{row: ln, col: col})
-				}
-			}
-
-			inSelection := selection != nil && selection.Contains(buffer.Position{Row: ln, Col: col})
-
-			var char string
-			if col > len(chars) {
-				codeLine = append(codeLine, codeLineCharStyle.Render(" ")...)
-				break
-			} else if col == len(chars) {
-				char = " "
+			if lineDiagnostic.Severity > 0 {
+				prefix = zone.Mark(zoneFileLineDiagnosticID(lineDiagnosticIndex), lineDiagnostic.Severity.Icon().Render())
 			} else {
-				char = chars[col]
-			}
-
-			// Replace tabs with spaces
-			if char == "\t" {
-				char = " "
+				prefix = " "
 			}
 
-			style := f.HighestMatchStyle(codeLineCharStyle, ln, col)
-			style = f.HighestLineColDiagnosticStyle(style, ln, col)
-
-			if ln == cursorRow && ii == realCursorCol {
-				char = f.cursor.cursor.View(char, style)
-			} else if inSelection {
-				char = styles.FileView.SelectionStyle.Inherit(style).Render(char)
-			} else {
-				char = style.Render(char)
-			}
-			codeLine = append(codeLine, char...)
+			prefixLn := strconv.Itoa(ln + 1)
+			prefix += zone.Mark(zoneFileLineNumberID(ln), codePrefixStyle.Render(strings.Repeat(" ", prefixWidth-lipgloss.Width(prefixLn))+prefixLn))
+		}
 
-			paddingStyle := codeLineCharStyle
-			labelStyle := config.Theme.UI.FileView.InlayHintStyle
-			if inSelection {
-				paddingStyle = styles.FileView.SelectionStyle.Inherit(paddingStyle)
-				labelStyle = styles.FileView.SelectionStyle.Inherit(labelStyle)
-			}
-			for _, hint := range f.InlayHintsForLineCol(ln, col+1) {
-				var label string
-				if hint.PaddingLeft {
-					label += paddingStyle.Render(" ")
-				}
-				label += labelStyle.Render(hint.Label)
-				if hint.PaddingRight {
-					label += paddingStyle.Render(" ")
-				}
-				codeLine = append(codeLine, label...)
-				colOffset += lipgloss.Width(label)
-			}
+		if f.buffer.LineLen(ln) < r.start {
+			editorCode += borderStyle(codeLineStyle.Render(prefix)) + "\n"
+			continue
 		}
 
+		codeLine, linePositions := f.codeLineView(ln, r.start, r.count, cursorRow, cursorCol, selections, codeLineCharStyle)
 		positions[i] = linePositions
 
-		if lineDiagnostic.Severity > 0 && lineDiagnostic.Range.Start.Row == ln {
+		if r.last && lineDiagnostic.Severity > 0 && lineDiagnostic.Range.Start.Row == ln {
 			lineWidth := ansi.StringWidth(string(codeLine))
 			if lineWidth < width {
 				diagnosticLine := zone.Mark(zoneFileDiagnosticID(lineDiagnosticIndex), codeLineCharStyle.Render(lineDiagnostic.ShortView(codeLineStyle)))
@@ -660,30 +921,65 @@ func (f *File) View(width int, height int, border bool, debug bool) string {
 			codeLine = append(codeLine, codeLineCharStyle.Render(strings.Repeat(" ", width-lineWidth))...)
 		}
 
-		editorCodeLine := zone.Mark(zoneFileLineID(ln), string(codeLine))
+		// the zones of wrapped rows are numbered by screen row, the positions map them back to the line
+		editorCodeLine := zone.Mark(zoneFileLineID(offsetRow+i), string(codeLine))
 
 		editorCode += borderStyle(codeLineStyle.Render(prefix+ansi.Truncate(editorCodeLine, width, ""))) + "\n"
 	}
 
 	f.positions = positions
 
+	if wordWrap {
+		if row, col, ok := f.screenPosition(cursorRow, cursorCol); ok {
+			realCursorRow, realCursorCol = row, col
+		}
+	}
+
 	editorCode = strings.TrimSuffix(editorCode, "\n")
 
 	if f.showCurrentDiagnostic {
 		diagnostic := f.HighestLineColDiagnostic(cursorRow, realCursorCol)
 		if diagnostic.Severity > 0 {
 			editorCode = overlay.PlacePosition(lipgloss.Left, lipgloss.Top, diagnostic.View(width, height), editorCode,
-				overlay.WithMarginX(styles.FileView.LinePrefixStyle.GetHorizontalFrameSize()+prefixWidth+1+cursorCol),
+				overlay.WithMarginX(styles.FileView.LinePrefixStyle.GetHorizontalFrameSize()+prefixWidth+1+realCursorCol),
 				overlay.WithMarginY(realCursorRow+1),
 			)
 		} else {
 			f.HideCurrentDiagnostic()
 		}
-	} else if f.autocomplete.Visible() {
+	} else if !f.hidePopups && f.autocomplete.Visible() {
 		editorCode = overlay.PlacePosition(lipgloss.Left, lipgloss.Top, f.autocomplete.View(width, height), editorCode,
-			overlay.WithMarginX(styles.FileView.LinePrefixStyle.GetHorizontalFrameSize()+prefixWidth+1+cursorCol),
+			overlay.WithMarginX(styles.FileView.LinePrefixStyle.GetHorizontalFrameSize()+prefixWidth+1+realCursorCol),
 			overlay.WithMarginY(realCursorRow+1),
 		)
+	} else if !f.hidePopups && f.hover.Visible() {
+		hoverRow, hoverCol := f.hover.Position()
+		realHoverRow, realHoverCol := hoverRow-offsetRow, max(hoverCol-offsetCol, 0)
+		if wordWrap {
+			realHoverRow = -1
+			if row, col, ok := f.screenPosition(hoverRow, hoverCol); ok {
+				realHoverRow, realHoverCol = row, col
+			}
+		}
+		if realHoverRow >= 0 && realHoverRow < height {
+			editorCode = overlay.PlacePosition(lipgloss.Left, lipgloss.Top, f.hover.View(width, max(height-realHoverRow-1, 3)), editorCode,
+				overlay.WithMarginX(styles.FileView.LinePrefixStyle.GetHorizontalFrameSize()+prefixWidth+1+realHoverCol),
+				overlay.WithMarginY(realHoverRow+1),
+			)
+		}
+	}
+
+	if !f.hidePopups && f.signatureHelp.Visible() {
+		signatureHelp := f.signatureHelp.View(width)
+		// show the signature help above the cursor if there is enough space
+		marginY := realCursorRow - lipgloss.Height(signatureHelp)
+		if marginY < 0 {
+			marginY = realCursorRow + 1
+		}
+		editorCode = overlay.PlacePosition(lipgloss.Left, lipgloss.Top, signatureHelp, editorCode,
+			overlay.WithMarginX(styles.FileView.LinePrefixStyle.GetHorizontalFrameSize()+prefixWidth+1+realCursorCol),
+			overlay.WithMarginY(marginY),
+		)
 	}
 
 	if debug {
@@ -0,0 +1,171 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+type FormattedMsg struct {
+	Name    string
+	Version int32
+	Text    []byte
+	// Err is set if the formatter failed, the file is still saved if Save is true.
+	Err  error
+	Save bool
+}
+
+func (f *File) formattingOptions() ls.FormattingOptions {
+	cfg := f.EditorConfig()
+	tabSize := cfg.TabSize
+	if cfg.IndentStyle == config.IndentStyleSpace {
+		tabSize = cfg.IndentSize
+	}
+	return ls.FormattingOptions{
+		TabSize:                tabSize,
+		InsertSpaces:           cfg.IndentStyle == config.IndentStyleSpace,
+		TrimTrailingWhitespace: cfg.TrimTrailingWhitespace,
+		InsertFinalNewline:     cfg.InsertFinalNewline,
+	}
+}
+
+// Format formats the file with a language server or the external formatter of the language.
+func (f *File) Format(save bool) tea.Cmd {
+	return ls.Format(f.Name(), f.Version(), f.formattingOptions(), nil, save)
+}
+
+// FormatSelection formats the selection or the whole file if nothing is selected.
+func (f *File) FormatSelection() tea.Cmd {
+	s := f.Selection()
+	if s == nil {
+		return f.Format(false)
+	}
+	return ls.Format(f.Name(), f.Version(), f.formattingOptions(), s, false)
+}
+
+func (f *File) FormatOnSave() bool {
+	return f.language != nil && f.language.Config.FormatOnSave
+}
+
+func (f *File) HasExternalFormatter() bool {
+	return f.language != nil && f.language.Config.Formatter != nil && f.language.Config.Formatter.Command != ""
+}
+
+// FormatExternal runs the external formatter of the language with the file content as stdin.
+// The formatter is killed if it takes longer than ls.FormatTimeout.
+func (f *File) FormatExternal(save bool) tea.Cmd {
+	if !f.HasExternalFormatter() {
+		return nil
+	}
+
+	formatter := f.language.Config.Formatter
+	name := f.Name()
+	version := f.Version()
+	text := f.buffer.Copy().Reader()
+
+	return func() tea.Msg {
+		args := make([]string, 0, len(formatter.Args))
+		for _, arg := range formatter.Args {
+			args = append(args, strings.ReplaceAll(arg, "{file}", name))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), ls.FormatTimeout)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, formatter.Command, args...)
+		cmd.Dir = filepath.Dir(name)
+		cmd.Stdin = text
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if stderr.Len() > 0 {
+				err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+			}
+			return FormattedMsg{
+				Name:    name,
+				Version: version,
+				Err:     fmt.Errorf("error running formatter %s: %w", formatter.Command, err),
+				Save:    save,
+			}
+		}
+
+		return FormattedMsg{
+			Name:    name,
+			Version: version,
+			Text:    stdout.Bytes(),
+			Save:    save,
+		}
+	}
+}
+
+// ApplyFormatted replaces the changed lines of the file with the formatted text.
+func (f *File) ApplyFormatted(text []byte) tea.Cmd {
+	edit, ok := formatTextEdit(f.buffer, text)
+	if !ok {
+		return nil
+	}
+	return f.ApplyTextEdits([]ls.TextEdit{edit})
+}
+
+// formatTextEdit returns a single edit which replaces the lines between the common prefix and suffix of the buffer and the text.
+func formatTextEdit(b *buffer.Buffer, text []byte) (ls.TextEdit, bool) {
+	oldLines := make([]string, b.LinesLen())
+	for i := range oldLines {
+		oldLines[i] = b.Line(i).String()
+	}
+	newLines := strings.Split(strings.ReplaceAll(string(text), "\r\n", "\n"), "\n")
+
+	var start int
+	for start < min(len(oldLines), len(newLines)) && oldLines[start] == newLines[start] {
+		start++
+	}
+	if start == len(oldLines) && start == len(newLines) {
+		return ls.TextEdit{}, false
+	}
+
+	var end int
+	for end < min(len(oldLines), len(newLines))-start && oldLines[len(oldLines)-1-end] == newLines[len(newLines)-1-end] {
+		end++
+	}
+
+	oldEnd := len(oldLines) - end
+	replaced := newLines[start : len(newLines)-end]
+
+	r := buffer.Range{
+		Start: buffer.Position{Row: start},
+		End:   buffer.Position{Row: oldEnd},
+	}
+	var newText string
+	if len(replaced) > 0 {
+		newText = strings.Join(replaced, "\n") + "\n"
+	}
+
+	// there is no line after the replaced lines, so the range ends at the end of the last line
+	if oldEnd == len(oldLines) {
+		lastRow := len(oldLines) - 1
+		r.End = buffer.Position{Row: lastRow, Col: b.LineLen(lastRow)}
+		newText = strings.TrimSuffix(newText, "\n")
+
+		if start == len(oldLines) {
+			r.Start = r.End
+			newText = "\n" + newText
+		} else if len(replaced) == 0 && start > 0 {
+			r.Start = buffer.Position{Row: start - 1, Col: b.LineLen(start - 1)}
+		}
+	}
+
+	return ls.TextEdit{
+		Range:   r,
+		NewText: newText,
+	}, true
+}
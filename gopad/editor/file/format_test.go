@@ -0,0 +1,44 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func TestFormatTextEdit(t *testing.T) {
+	data := []struct {
+		name      string
+		text      string
+		formatted string
+	}{
+		{name: "unchanged", text: "a\nb\nc", formatted: "a\nb\nc"},
+		{name: "change middle line", text: "a\n  b\nc", formatted: "a\n\tb\nc"},
+		{name: "change first line", text: "a \nb\nc", formatted: "a\nb\nc"},
+		{name: "change last line", text: "a\nb\nc ", formatted: "a\nb\nc"},
+		{name: "append lines", text: "a\nb", formatted: "a\nb\n"},
+		{name: "remove trailing lines", text: "a\nb\n\n", formatted: "a\nb"},
+		{name: "insert line", text: "a\nc", formatted: "a\nb\nc"},
+		{name: "remove line", text: "a\n\n\nc", formatted: "a\n\nc"},
+		{name: "replace everything", text: "x", formatted: "y\nz"},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+
+			f.ApplyFormatted([]byte(d.formatted))
+			assert.Equal(t, d.formatted, f.Text())
+
+			if d.text != d.formatted {
+				f.Undo()
+				assert.Equal(t, d.text, f.Text())
+			}
+		})
+	}
+}
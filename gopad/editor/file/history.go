@@ -0,0 +1,252 @@
+package file
+
+import (
+	"bytes"
+	"log"
+	"time"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+const (
+	// maxHistory is the maximum number of undo steps kept per file.
+	maxHistory = 1000
+	// historyGroupTimeout is the maximum pause between two edits of the same kind to still be grouped into one undo step.
+	historyGroupTimeout = time.Second
+)
+
+type editKind int
+
+const (
+	editKindOther editKind = iota
+	editKindInsert
+	editKindDelete
+)
+
+type cursorState struct {
+	row  int
+	col  int
+	mark *Mark
+}
+
+// historyEntry stores an edit as the full text of the affected lines before and after the edit.
+// Undoing the entry replaces the new lines with the old text and redoing it does the opposite.
+type historyEntry struct {
+	kind     editKind
+	group    int
+	row      int
+	oldLines int
+	newLines int
+	oldText  []byte
+	newText  []byte
+	before   cursorState
+	after    cursorState
+	time     time.Time
+}
+
+type pendingEdit struct {
+	kind     editKind
+	row      int
+	oldLines int
+	linesLen int
+	oldText  []byte
+	before   cursorState
+}
+
+type history struct {
+	undo []historyEntry
+	redo []historyEntry
+
+	// group is the id of the currently open undo group, 0 if there is none.
+	group     int
+	lastGroup int
+}
+
+func (h *history) push(entry historyEntry) {
+	h.redo = nil
+	entry.group = h.group
+
+	if len(h.undo) > 0 {
+		last := &h.undo[len(h.undo)-1]
+		if canGroup(*last, entry) {
+			last.newText = entry.newText
+			last.after = entry.after
+			last.time = entry.time
+			return
+		}
+	}
+
+	h.undo = append(h.undo, entry)
+	if len(h.undo) > maxHistory {
+		h.undo = h.undo[len(h.undo)-maxHistory:]
+	}
+}
+
+// canGroup reports whether next directly continues last, e.g. typing or deleting characters on the same line.
+func canGroup(last historyEntry, next historyEntry) bool {
+	if last.kind == editKindOther || last.kind != next.kind || last.group != 0 || next.group != 0 {
+		return false
+	}
+	if next.time.Sub(last.time) > historyGroupTimeout {
+		return false
+	}
+	if last.row != next.row || last.oldLines != 1 || last.newLines != 1 || next.oldLines != 1 || next.newLines != 1 {
+		return false
+	}
+
+	return last.after.row == next.before.row && last.after.col == next.before.col
+}
+
+func (f *File) cursorState() cursorState {
+	row, col := f.Cursor()
+
+	var mark *Mark
+	if f.cursor.mark != nil {
+		m := *f.cursor.mark
+		mark = &m
+	}
+
+	return cursorState{
+		row:  row,
+		col:  col,
+		mark: mark,
+	}
+}
+
+func (f *File) setCursorState(s cursorState) {
+	f.SetCursor(s.row, s.col)
+	f.cursor.mark = nil
+	if s.mark != nil {
+		f.SetMark(s.mark.row, s.mark.col)
+	}
+}
+
+// linesBytes returns a copy of the lines between fromRow and toRow, as single lines share their memory with the buffer.
+func (f *File) linesBytes(fromRow int, toRow int) []byte {
+	return bytes.Clone(f.buffer.BytesRange(
+		buffer.Position{Row: fromRow, Col: 0},
+		buffer.Position{Row: toRow, Col: f.buffer.LineLen(toRow)},
+	))
+}
+
+// beginEdit snapshots the lines between fromRow and toRow before they are modified.
+// The rows must cover every line the edit changes or removes.
+func (f *File) beginEdit(kind editKind, fromRow int, toRow int) pendingEdit {
+	fromRow = max(fromRow, 0)
+	toRow = max(min(toRow, f.buffer.LinesLen()-1), fromRow)
+
+	return pendingEdit{
+		kind:     kind,
+		row:      fromRow,
+		oldLines: toRow - fromRow + 1,
+		linesLen: f.buffer.LinesLen(),
+		oldText:  f.linesBytes(fromRow, toRow),
+		before:   f.cursorState(),
+	}
+}
+
+// commitEdit records the edit started with beginEdit as a new undo step.
+func (f *File) commitEdit(p pendingEdit) {
+	newLines := p.oldLines + f.buffer.LinesLen() - p.linesLen
+	if newLines <= 0 {
+		log.Printf("dropping history, edit at row %d removed more lines than recorded", p.row)
+		f.history = history{}
+		return
+	}
+
+	newText := f.linesBytes(p.row, p.row+newLines-1)
+	if bytes.Equal(p.oldText, newText) {
+		return
+	}
+
+	f.history.push(historyEntry{
+		kind:     p.kind,
+		row:      p.row,
+		oldLines: p.oldLines,
+		newLines: newLines,
+		oldText:  p.oldText,
+		newText:  newText,
+		before:   p.before,
+		after:    f.cursorState(),
+		time:     time.Now(),
+	})
+}
+
+// CanUndo returns whether there is an edit which can be undone.
+func (f *File) CanUndo() bool {
+	return len(f.history.undo) > 0
+}
+
+// CanRedo returns whether there is an undone edit which can be redone.
+func (f *File) CanRedo() bool {
+	return len(f.history.redo) > 0
+}
+
+// ClearHistory removes all undo and redo steps.
+func (f *File) ClearHistory() {
+	f.history = history{}
+}
+
+// Undo reverts the last edit or undo group and restores the cursor and selection from before it.
+func (f *File) Undo() tea.Cmd {
+	if len(f.history.undo) == 0 {
+		return nil
+	}
+
+	f.cursors = nil
+	f.batch = make([]Change, 0, 1)
+	for i := 0; len(f.history.undo) > 0; i++ {
+		entry := f.history.undo[len(f.history.undo)-1]
+		if i > 0 && (entry.group == 0 || entry.group != f.history.redo[len(f.history.redo)-1].group) {
+			break
+		}
+		f.history.undo = f.history.undo[:len(f.history.undo)-1]
+		f.history.redo = append(f.history.redo, entry)
+
+		f.replaceLines(entry.row, entry.newLines, entry.oldText)
+		f.setCursorState(entry.before)
+	}
+
+	return f.flushBatch()
+}
+
+// Redo reapplies the last undone edit or undo group.
+func (f *File) Redo() tea.Cmd {
+	if len(f.history.redo) == 0 {
+		return nil
+	}
+
+	f.cursors = nil
+	f.batch = make([]Change, 0, 1)
+	for i := 0; len(f.history.redo) > 0; i++ {
+		entry := f.history.redo[len(f.history.redo)-1]
+		if i > 0 && (entry.group == 0 || entry.group != f.history.undo[len(f.history.undo)-1].group) {
+			break
+		}
+		f.history.redo = f.history.redo[:len(f.history.redo)-1]
+		f.history.undo = append(f.history.undo, entry)
+
+		f.replaceLines(entry.row, entry.oldLines, entry.newText)
+		f.setCursorState(entry.after)
+	}
+
+	return f.flushBatch()
+}
+
+// replaceLines replaces the given amount of lines starting at row with text without recording a new undo step.
+// The change is added to the current batch.
+func (f *File) replaceLines(row int, lines int, text []byte) {
+	endRow := row + lines - 1
+	endCol := f.buffer.LineLen(endRow)
+	change := f.beginChange(
+		buffer.Position{Row: row, Col: 0},
+		buffer.Position{Row: endRow, Col: endCol},
+	)
+
+	f.buffer.Replace(row, 0, endRow, endCol, text)
+
+	newEndRow := row + bytes.Count(text, []byte("\n"))
+	f.batch = append(f.batch, f.endChange(change, buffer.Position{Row: newEndRow, Col: f.buffer.LineLen(newEndRow)}))
+}
@@ -0,0 +1,130 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func TestFile_UndoRedo(t *testing.T) {
+	data := []struct {
+		name string
+		text string
+		edit func(f *File)
+		want string
+	}{
+		{
+			name: "insert",
+			text: "hello\nworld",
+			edit: func(f *File) {
+				f.SetCursor(0, 5)
+				f.Insert([]byte(" there"))
+			},
+			want: "hello there\nworld",
+		},
+		{
+			name: "new line",
+			text: "hello\nworld",
+			edit: func(f *File) {
+				f.SetCursor(0, 2)
+				f.InsertNewLine()
+			},
+			want: "he\nllo\nworld",
+		},
+		{
+			name: "delete line",
+			text: "a\nb\nc",
+			edit: func(f *File) {
+				f.SetCursor(1, 0)
+				f.DeleteLine()
+			},
+			want: "a\nc",
+		},
+		{
+			name: "delete last line",
+			text: "a\nb\nc",
+			edit: func(f *File) {
+				f.SetCursor(2, 0)
+				f.DeleteLine()
+			},
+			want: "a\nb",
+		},
+		{
+			name: "delete before line start",
+			text: "a\nb\nc",
+			edit: func(f *File) {
+				f.SetCursor(1, 0)
+				f.DeleteBefore(1)
+			},
+			want: "ab\nc",
+		},
+		{
+			name: "replace range",
+			text: "one\ntwo\nthree",
+			edit: func(f *File) {
+				f.Replace(0, 1, 2, 2, []byte("X\nY"))
+			},
+			want: "oX\nYree",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+
+			d.edit(f)
+			assert.Equal(t, d.want, f.Text())
+
+			f.Undo()
+			assert.Equal(t, d.text, f.Text())
+
+			f.Redo()
+			assert.Equal(t, d.want, f.Text())
+		})
+	}
+}
+
+func TestFile_UndoGroupsTyping(t *testing.T) {
+	b, err := buffer.New("test.txt", bytes.NewReader([]byte("foo")), "utf-8", buffer.LineEndingLF, false)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeWrite)
+
+	f.SetCursor(0, 3)
+	f.InsertNewLine()
+	for _, r := range "bar" {
+		f.InsertRunes([]rune{r})
+	}
+	assert.Equal(t, "foo\nbar", f.Text())
+
+	f.Undo()
+	assert.Equal(t, "foo\n", f.Text())
+	row, col := f.Cursor()
+	assert.Equal(t, 1, row)
+	assert.Equal(t, 0, col)
+
+	f.Undo()
+	assert.Equal(t, "foo", f.Text())
+	assert.False(t, f.CanUndo())
+}
+
+func TestFile_RedoRestoresSelection(t *testing.T) {
+	b, err := buffer.New("test.txt", bytes.NewReader([]byte("foo\nbar")), "utf-8", buffer.LineEndingLF, false)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeWrite)
+
+	f.SetCursor(0, 0)
+	f.SetMark(0, 0)
+	f.SetCursor(1, 3)
+	f.Replace(0, 0, 0, 0, []byte("x"))
+	want := f.Selection()
+	assert.NotNil(t, want)
+
+	f.Undo()
+	f.Redo()
+	assert.Equal(t, want, f.Selection())
+}
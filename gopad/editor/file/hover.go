@@ -0,0 +1,201 @@
+package file
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lrstanley/bubblezone"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+const hoverDwellTime = 500 * time.Millisecond
+
+// HoverDwellMsg is sent once the mouse rested on the same position for hoverDwellTime.
+type HoverDwellMsg struct {
+	Name string
+	Row  int
+	Col  int
+	id   int
+}
+
+func NewHover(f *File) *Hover {
+	return &Hover{
+		file:       f,
+		requestRow: -1,
+		requestCol: -1,
+		dwellRow:   -1,
+		dwellCol:   -1,
+	}
+}
+
+type Hover struct {
+	file   *File
+	hover  ls.Hover
+	row    int
+	col    int
+	offset int
+	show   bool
+
+	requestRow int
+	requestCol int
+	mouse      bool
+
+	dwell    int
+	dwellRow int
+	dwellCol int
+}
+
+func (h *Hover) request(row int, col int, mouse bool) tea.Cmd {
+	h.requestRow = row
+	h.requestCol = col
+	h.mouse = mouse
+	return ls.GetHover(h.file.Name(), row, col)
+}
+
+// Show requests the hover for the cursor position.
+func (h *Hover) Show() tea.Cmd {
+	row, col := h.file.Cursor()
+	return h.request(row, col, false)
+}
+
+// Dwell starts waiting for the mouse to rest on the given position.
+func (h *Hover) Dwell(row int, col int) tea.Cmd {
+	if h.show && h.mouse {
+		if h.hover.Range != nil && h.hover.Range.Contains(buffer.Position{Row: row, Col: col}) {
+			return nil
+		}
+		if h.hover.Range != nil || row != h.row || col != h.col {
+			h.Hide()
+		}
+	}
+
+	if row == h.dwellRow && col == h.dwellCol {
+		return nil
+	}
+
+	h.dwell++
+	h.dwellRow = row
+	h.dwellCol = col
+
+	msg := HoverDwellMsg{
+		Name: h.file.Name(),
+		Row:  row,
+		Col:  col,
+		id:   h.dwell,
+	}
+	return tea.Tick(hoverDwellTime, func(time.Time) tea.Msg {
+		return msg
+	})
+}
+
+// Dwelled requests the hover if the mouse did not move since the dwell started.
+func (h *Hover) Dwelled(msg HoverDwellMsg) tea.Cmd {
+	if msg.id != h.dwell {
+		return nil
+	}
+	return h.request(msg.Row, msg.Col, true)
+}
+
+// MouseLeave stops waiting for the mouse and hides the hover if it was opened by the mouse.
+func (h *Hover) MouseLeave() {
+	h.dwell++
+	h.dwellRow = -1
+	h.dwellCol = -1
+	if h.show && h.mouse {
+		h.Hide()
+	}
+}
+
+func (h *Hover) SetHover(row int, col int, hover ls.Hover) {
+	if row != h.requestRow || col != h.requestCol || strings.TrimSpace(hover.Contents) == "" {
+		return
+	}
+
+	h.hover = hover
+	h.row = row
+	h.col = col
+	h.offset = 0
+	h.show = true
+}
+
+func (h *Hover) Visible() bool {
+	return h.show
+}
+
+func (h *Hover) Hide() {
+	h.show = false
+	h.hover = ls.Hover{}
+	h.offset = 0
+}
+
+func (h *Hover) ScrollUp() {
+	if h.offset > 0 {
+		h.offset--
+	}
+}
+
+func (h *Hover) ScrollDown() {
+	h.offset++
+}
+
+func (h *Hover) Position() (int, int) {
+	return h.row, h.col
+}
+
+func renderHoverContents(hover ls.Hover) string {
+	if hover.Kind != ls.MarkupKindMarkdown {
+		return hover.Contents
+	}
+
+	var lines []string
+	for _, line := range strings.Split(hover.Contents, "\n") {
+		// code fences are not rendered
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		lines = append(lines, markdownUnescaper.Replace(line))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+var markdownUnescaper = strings.NewReplacer(
+	`\\`, `\`,
+	"\\`", "`",
+	`\*`, `*`,
+	`\_`, `_`,
+	`\{`, `{`,
+	`\}`, `}`,
+	`\[`, `[`,
+	`\]`, `]`,
+	`\(`, `(`,
+	`\)`, `)`,
+	`\#`, `#`,
+	`\+`, `+`,
+	`\-`, `-`,
+	`\.`, `.`,
+	`\!`, `!`,
+	`\<`, `<`,
+	`\>`, `>`,
+)
+
+func (h *Hover) View(width int, height int) string {
+	width = min(width, 80)
+	height = min(height, 15)
+
+	style := config.Theme.UI.Documentation.Style
+	contentWidth := max(width-style.GetHorizontalFrameSize(), 0)
+	contentHeight := max(height-style.GetVerticalFrameSize(), 0)
+
+	contents := lipgloss.NewStyle().Width(contentWidth).Render(renderHoverContents(h.hover))
+	lines := strings.Split(contents, "\n")
+
+	h.offset = max(min(h.offset, len(lines)-contentHeight), 0)
+	lines = lines[h.offset:min(h.offset+contentHeight, len(lines))]
+
+	return zone.Mark(ZoneFileHover, style.Width(width).Render(strings.Join(lines, "\n")))
+}
@@ -25,6 +25,7 @@ const (
 	queryInjectionsFileName = "injections.scm"
 	queryLocalsFileName     = "locals.scm"
 	queryOutlineFileName    = "outline.scm"
+	queryIndentsFileName    = "indents.scm"
 )
 
 var Languages []*Language
@@ -35,6 +36,15 @@ type Language struct {
 	Grammar *Grammar
 }
 
+// LanguageID returns the language identifier sent to language servers.
+// It defaults to the language name if no language_id is configured.
+func (l *Language) LanguageID() string {
+	if l.Config.LanguageID != "" {
+		return l.Config.LanguageID
+	}
+	return l.Name
+}
+
 func (l *Language) Title() string {
 	return l.Name
 }
@@ -48,6 +58,7 @@ type Grammar struct {
 	HighlightsQuery HighlightsQuery
 	InjectionsQuery *InjectionsQuery
 	OutlineQuery    *OutlineQuery
+	IndentsQuery    *IndentsQuery
 }
 
 type HighlightsQuery struct {
@@ -73,6 +84,17 @@ type OutlineQuery struct {
 	ExtraContextCaptureID *uint32
 }
 
+type IndentsQuery struct {
+	Query                      *sitter.Query
+	IndentCaptureID            *uint32
+	IndentAlwaysCaptureID      *uint32
+	OutdentCaptureID           *uint32
+	ExtendCaptureID            *uint32
+	ExtendPreventOnceCaptureID *uint32
+	AlignCaptureID             *uint32
+	AnchorCaptureID            *uint32
+}
+
 func GetCaptureIndexes(query *sitter.Query, captureNames []string) []*uint32 {
 	indexes := make([]*uint32, len(captureNames))
 	for id := range query.CaptureCount() {
@@ -236,11 +258,46 @@ func loadTreeSitterGrammar(name string, cfg config.GrammarConfig, defaultConfigs
 		}
 	}
 
+	rawIndentsQuery, err := readQuery(queriesConfigDir, defaultConfigs, name, queryIndentsFileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading indents query: %w", err)
+	}
+
+	var indentsQuery *IndentsQuery
+	if len(rawIndentsQuery) > 0 {
+		query, err = sitter.NewQuery(rawIndentsQuery, tsLang)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing indents query: %w", err)
+		}
+
+		indexes := GetCaptureIndexes(query, []string{
+			"indent",
+			"indent.always",
+			"outdent",
+			"extend",
+			"extend.prevent-once",
+			"align",
+			"anchor",
+		})
+
+		indentsQuery = &IndentsQuery{
+			Query:                      query,
+			IndentCaptureID:            indexes[0],
+			IndentAlwaysCaptureID:      indexes[1],
+			OutdentCaptureID:           indexes[2],
+			ExtendCaptureID:            indexes[3],
+			ExtendPreventOnceCaptureID: indexes[4],
+			AlignCaptureID:             indexes[5],
+			AnchorCaptureID:            indexes[6],
+		}
+	}
+
 	return &Grammar{
 		Language:        tsLang,
 		HighlightsQuery: highlightsQuery,
 		InjectionsQuery: injectionsQuery,
 		OutlineQuery:    outlineQuery,
+		IndentsQuery:    indentsQuery,
 	}, nil
 }
 
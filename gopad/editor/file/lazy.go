@@ -0,0 +1,82 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+// LoadLazy indexes the large file in a command, the buffer is passed to SetLazyBuffer with LazyLoadedMsg.
+func LoadLazy(name string, lineEnding buffer.LineEnding) tea.Cmd {
+	return func() tea.Msg {
+		b, err := buffer.NewLazyFromFile(name, lineEnding)
+		return LazyLoadedMsg{
+			Name:   name,
+			Buffer: b,
+			Err:    err,
+		}
+	}
+}
+
+type LazyLoadedMsg struct {
+	Name   string
+	Buffer *buffer.Buffer
+	Err    error
+}
+
+// newEmptyBuffer returns the empty buffer a lazily loaded file shows until it is indexed.
+func newEmptyBuffer(name string) (*buffer.Buffer, error) {
+	name, err := filepath.Abs(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute file path: %w", err)
+	}
+	return buffer.New(name, bytes.NewReader(nil), "utf-8", buffer.LineEndingAuto, true)
+}
+
+// loadingCursor is the cursor set while the file is loaded, it is moved to once the file is loaded.
+type loadingCursor struct {
+	row       int
+	col       int
+	offsetRow int
+	offsetCol int
+}
+
+func (c *loadingCursor) set(row int, col int) {
+	if row > -1 {
+		c.row = row
+	}
+	if col > -1 {
+		c.col = col
+	}
+}
+
+// Loading returns whether the large file is still indexed.
+func (f *File) Loading() bool {
+	return f.loading
+}
+
+// SetLazyBuffer replaces the buffer with the lazily loaded buffer and closes the previous one.
+// The cursor set while the file was loading is restored, reloaded files keep their cursor.
+func (f *File) SetLazyBuffer(b *buffer.Buffer) {
+	s := f.CursorState()
+	_ = f.buffer.Close()
+	f.buffer = b
+	// the logged changes belong to the old buffer
+	f.changeLog = nil
+	f.changeLogVersion = b.Version()
+	f.changedVersion = b.Version()
+
+	if !f.loading {
+		f.SetCursorState(s)
+		return
+	}
+
+	f.loading = false
+	setDefaultLineEnding(b, EditorConfigForName(f.Name()))
+	f.SetCursor(f.loadingCursor.row, f.loadingCursor.col)
+	f.SetCursorOffset(f.loadingCursor.offsetRow, f.loadingCursor.offsetCol)
+}
@@ -0,0 +1,47 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func TestFile_SetLazyBuffer(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "test.txt")
+	assert.NoError(t, os.WriteFile(name, []byte("hello\nworld\nfoo"), 0644))
+
+	b, err := newEmptyBuffer(name)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeReadOnly)
+	f.loading = true
+
+	f.SetCursor(1, 3)
+	assert.True(t, f.Loading())
+	row, col := f.Cursor()
+	assert.Equal(t, 0, row)
+	assert.Equal(t, 0, col)
+
+	msg := LoadLazy(name, buffer.LineEndingAuto)().(LazyLoadedMsg)
+	assert.NoError(t, msg.Err)
+	f.SetLazyBuffer(msg.Buffer)
+	assert.False(t, f.Loading())
+	assert.Equal(t, "hello\nworld\nfoo", f.Text())
+	row, col = f.Cursor()
+	assert.Equal(t, 1, row)
+	assert.Equal(t, 3, col)
+
+	// reloading keeps the cursor
+	f.SetCursor(2, 1)
+	msg = LoadLazy(name, f.LineEnding())().(LazyLoadedMsg)
+	assert.NoError(t, msg.Err)
+	f.SetLazyBuffer(msg.Buffer)
+	row, col = f.Cursor()
+	assert.Equal(t, 2, row)
+	assert.Equal(t, 1, col)
+
+	assert.NoError(t, f.Buffer().Close())
+}
@@ -211,3 +211,63 @@ func GoTo() tea.Msg {
 }
 
 type GoToMsg struct{}
+
+func ReloadFile(name string) tea.Cmd {
+	return func() tea.Msg {
+		return ReloadFileMsg{
+			Name: name,
+		}
+	}
+}
+
+type ReloadFileMsg struct {
+	Name string
+}
+
+func KeepFile(name string) tea.Cmd {
+	return func() tea.Msg {
+		return KeepFileMsg{
+			Name: name,
+		}
+	}
+}
+
+type KeepFileMsg struct {
+	Name string
+}
+
+func DiffFile(name string) tea.Cmd {
+	return func() tea.Msg {
+		return DiffFileMsg{
+			Name: name,
+		}
+	}
+}
+
+type DiffFileMsg struct {
+	Name string
+}
+
+func OverwriteFile(name string) tea.Cmd {
+	return func() tea.Msg {
+		return OverwriteFileMsg{
+			Name: name,
+		}
+	}
+}
+
+type OverwriteFileMsg struct {
+	Name string
+}
+
+func RecoverFile(name string) tea.Cmd {
+	return func() tea.Msg {
+		return RecoverFileMsg{
+			Name: name,
+		}
+	}
+}
+
+type RecoverFileMsg struct {
+	Name string
+}
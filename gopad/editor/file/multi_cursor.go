@@ -0,0 +1,337 @@
+package file
+
+import (
+	"bytes"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+// Cursors returns the number of cursors including the primary one.
+func (f *File) Cursors() int {
+	return len(f.cursors) + 1
+}
+
+// ResetCursors removes all secondary cursors.
+func (f *File) ResetCursors() {
+	f.cursors = nil
+}
+
+func (f *File) allCursorStates() []cursorState {
+	return append([]cursorState{f.cursorState()}, f.cursors...)
+}
+
+// setCursorStates sets the first state as the primary cursor and the others as secondary cursors.
+// Cursors at the same position as a previous cursor are dropped.
+func (f *File) setCursorStates(states []cursorState) {
+	f.setCursorState(states[0])
+	f.cursors = nil
+	for i, s := range states[1:] {
+		if slices.ContainsFunc(states[:i+1], func(o cursorState) bool {
+			return o.row == s.row && o.col == s.col
+		}) {
+			continue
+		}
+		f.cursors = append(f.cursors, s)
+	}
+}
+
+// ForEachCursor runs fn once for every cursor with the cursor set as the current one.
+// All edits made by fn are applied as a single undo step, tree-sitter update and language server change.
+func (f *File) ForEachCursor(fn func() tea.Cmd) tea.Cmd {
+	if len(f.cursors) == 0 {
+		return fn()
+	}
+
+	states := f.allCursorStates()
+
+	// run fn from the last cursor to the first, this way edits only move the cursors which are already done
+	order := make([]int, len(states))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return comparePosition(states[b].row, states[b].col, states[a].row, states[a].col)
+	})
+
+	f.BeginBatch()
+	cmds := make([]tea.Cmd, 0, len(states)+1)
+	for n, i := range order {
+		f.setCursorState(states[i])
+		changes := len(f.batch)
+
+		cmds = append(cmds, fn())
+
+		for _, change := range f.batch[changes:] {
+			for _, j := range order[:n] {
+				states[j] = shiftCursorState(states[j], change)
+			}
+		}
+		states[i] = f.cursorState()
+	}
+	cmds = append(cmds, f.EndBatch())
+
+	f.setCursorStates(states)
+
+	return tea.Batch(cmds...)
+}
+
+func comparePosition(aRow int, aCol int, bRow int, bCol int) int {
+	return buffer.Position{Row: aRow, Col: aCol}.Compare(buffer.Position{Row: bRow, Col: bCol})
+}
+
+// shiftCursors moves the secondary cursors by a change which was not made through ForEachCursor.
+// ForEachCursor sets the secondary cursors itself after its changes are applied.
+func (f *File) shiftCursors(change Change) {
+	for i := range f.cursors {
+		f.cursors[i] = shiftCursorState(f.cursors[i], change)
+	}
+}
+
+// shiftCursorState moves the cursor and its mark by the change made before them.
+func shiftCursorState(s cursorState, change Change) cursorState {
+	s.row, s.col = shiftPosition(s.row, s.col, change)
+	if s.mark != nil {
+		m := *s.mark
+		m.row, m.col = shiftPosition(m.row, m.col, change)
+		s.mark = &m
+	}
+	return s
+}
+
+func shiftPosition(row int, col int, change Change) (int, int) {
+	return movePosition(row, col, change.Range, changeEnd(change))
+}
+
+// changeEnd returns the end of the new text of the change.
+func changeEnd(change Change) buffer.Position {
+	newEnd := change.Range.Start
+	if lines := bytes.Count(change.NewText, []byte("\n")); lines > 0 {
+		newEnd.Row += lines
+		newEnd.Col = utf8.RuneCount(change.NewText[bytes.LastIndexByte(change.NewText, '\n')+1:])
+	} else {
+		newEnd.Col += utf8.RuneCount(change.NewText)
+	}
+	return newEnd
+}
+
+// movePosition moves the position by the replacement of the old range with text ending at newEnd.
+func movePosition(row int, col int, old buffer.Range, newEnd buffer.Position) (int, int) {
+	start := old.Start
+	end := old.End
+	if comparePosition(row, col, start.Row, start.Col) < 0 {
+		return row, col
+	}
+
+	// positions inside the replaced range end up at the end of the new text
+	if comparePosition(row, col, end.Row, end.Col) < 0 {
+		return newEnd.Row, newEnd.Col
+	}
+
+	if row == end.Row {
+		return newEnd.Row, newEnd.Col + col - end.Col
+	}
+	return row + newEnd.Row - end.Row, col
+}
+
+// Selections returns the selections of all cursors.
+func (f *File) Selections() []buffer.Range {
+	var selections []buffer.Range
+	if s := f.Selection(); s != nil {
+		selections = append(selections, *s)
+	}
+	for _, c := range f.cursors {
+		if s := c.selection(); s != nil {
+			selections = append(selections, *s)
+		}
+	}
+	return selections
+}
+
+func (s cursorState) selection() *buffer.Range {
+	if s.mark == nil || (s.mark.row == s.row && s.mark.col == s.col) {
+		return nil
+	}
+
+	cursor := buffer.Position{Row: s.row, Col: s.col}
+	mark := buffer.Position{Row: s.mark.row, Col: s.mark.col}
+	if cursor.LessThan(mark) {
+		return &buffer.Range{Start: cursor, End: mark}
+	}
+	return &buffer.Range{Start: mark, End: cursor}
+}
+
+// hasSecondaryCursor returns whether one of the secondary cursors is at the given position.
+func (f *File) hasSecondaryCursor(row int, col int) bool {
+	return slices.ContainsFunc(f.cursors, func(s cursorState) bool {
+		return s.row == row && s.col == col
+	})
+}
+
+// AddCursorAbove adds a cursor one line above the topmost cursor and makes it the primary cursor.
+func (f *File) AddCursorAbove() {
+	states := f.allCursorStates()
+	top := slices.MinFunc(states, func(a, b cursorState) int {
+		return comparePosition(a.row, a.col, b.row, b.col)
+	})
+	if top.row == 0 {
+		return
+	}
+
+	f.addCursor(cursorState{
+		row: top.row - 1,
+		col: min(top.col, f.buffer.LineLen(top.row-1)),
+	})
+}
+
+// AddCursorBelow adds a cursor one line below the bottommost cursor and makes it the primary cursor.
+func (f *File) AddCursorBelow() {
+	states := f.allCursorStates()
+	bottom := slices.MaxFunc(states, func(a, b cursorState) int {
+		return comparePosition(a.row, a.col, b.row, b.col)
+	})
+	if bottom.row >= f.buffer.LinesLen()-1 {
+		return
+	}
+
+	f.addCursor(cursorState{
+		row: bottom.row + 1,
+		col: min(bottom.col, f.buffer.LineLen(bottom.row+1)),
+	})
+}
+
+// addCursor adds a new primary cursor and keeps the current primary cursor as secondary cursor.
+func (f *File) addCursor(s cursorState) {
+	f.setCursorStates(append([]cursorState{s}, f.allCursorStates()...))
+}
+
+// AddNextOccurrence selects the word at the cursor if nothing is selected.
+// Otherwise, it adds a cursor selecting the next occurrence of the selected text after the primary selection.
+func (f *File) AddNextOccurrence() {
+	s := f.Selection()
+	if s == nil {
+		row, col := f.Cursor()
+		r := f.WordRange(row, col)
+		if r.IsEmpty() {
+			return
+		}
+		f.SetMark(r.Start.Row, r.Start.Col)
+		f.SetCursor(r.End.Row, r.End.Col)
+		return
+	}
+
+	r, ok := f.findNext(f.SelectionBytes(), s.End)
+	if !ok {
+		return
+	}
+
+	states := f.allCursorStates()
+	if slices.ContainsFunc(states, func(c cursorState) bool {
+		cs := c.selection()
+		return cs != nil && cs.Equal(r)
+	}) {
+		return
+	}
+
+	f.addCursor(cursorState{
+		row:  r.End.Row,
+		col:  r.End.Col,
+		mark: &Mark{row: r.Start.Row, col: r.Start.Col},
+	})
+}
+
+// findNext returns the range of the next occurrence of text after the position and wraps around at the end of the file.
+func (f *File) findNext(text []byte, after buffer.Position) (buffer.Range, bool) {
+	if len(text) == 0 {
+		return buffer.Range{}, false
+	}
+
+	data := f.buffer.Bytes()
+	offset := f.buffer.ByteIndex(after.Row, after.Col)
+
+	index := bytes.Index(data[offset:], text)
+	if index > -1 {
+		index += offset
+	} else if index = bytes.Index(data, text); index == -1 {
+		return buffer.Range{}, false
+	}
+
+	return buffer.Range{
+		Start: bytePosition(data, index),
+		End:   bytePosition(data, index+len(text)),
+	}, true
+}
+
+// bytePosition returns the position with a rune based column of the byte index in data.
+func bytePosition(data []byte, index int) buffer.Position {
+	data = data[:index]
+	row := bytes.Count(data, []byte("\n"))
+	lineStart := bytes.LastIndexByte(data, '\n') + 1
+	return buffer.Position{
+		Row: row,
+		Col: utf8.RuneCount(data[lineStart:]),
+	}
+}
+
+// SplitSelectionIntoLines replaces every selection spanning multiple lines with one selection and cursor per line.
+func (f *File) SplitSelectionIntoLines() {
+	var states []cursorState
+	for _, c := range f.allCursorStates() {
+		s := c.selection()
+		if s == nil || s.Start.Row == s.End.Row {
+			states = append(states, c)
+			continue
+		}
+
+		for row := s.Start.Row; row <= s.End.Row; row++ {
+			startCol := 0
+			if row == s.Start.Row {
+				startCol = s.Start.Col
+			}
+			endCol := f.buffer.LineLen(row)
+			if row == s.End.Row {
+				// the selection ends at the start of the line, so there is nothing to select in it
+				if s.End.Col == 0 {
+					break
+				}
+				endCol = s.End.Col
+			}
+
+			var mark *Mark
+			if startCol != endCol {
+				mark = &Mark{row: row, col: startCol}
+			}
+			states = append(states, cursorState{
+				row:  row,
+				col:  endCol,
+				mark: mark,
+			})
+		}
+	}
+
+	// the bottommost cursor becomes the primary cursor
+	slices.SortStableFunc(states, func(a, b cursorState) int {
+		return comparePosition(b.row, b.col, a.row, a.col)
+	})
+	f.setCursorStates(states)
+}
+
+// MoveCursors runs fn once for every cursor with the cursor set as the current one.
+// Cursors which end up at the same position are merged.
+func (f *File) MoveCursors(fn func()) {
+	if len(f.cursors) == 0 {
+		fn()
+		return
+	}
+
+	states := f.allCursorStates()
+	for i, s := range states {
+		f.setCursorState(s)
+		fn()
+		states[i] = f.cursorState()
+	}
+	f.setCursorStates(states)
+}
@@ -0,0 +1,117 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+func TestFile_ForEachCursor(t *testing.T) {
+	data := []struct {
+		name    string
+		text    string
+		cursors func(f *File)
+		edit    func(f *File) tea.Cmd
+		want    string
+	}{
+		{
+			name: "insert on lines below",
+			text: "a := 1\nb := 2\nc := 3",
+			cursors: func(f *File) {
+				f.SetCursor(0, 1)
+				f.AddCursorBelow()
+				f.AddCursorBelow()
+			},
+			edit: func(f *File) tea.Cmd {
+				return f.Insert([]byte("xy"))
+			},
+			want: "axy := 1\nbxy := 2\ncxy := 3",
+		},
+		{
+			name: "new lines on the same line",
+			text: "foo foo foo",
+			cursors: func(f *File) {
+				f.SetCursor(0, 0)
+				f.AddNextOccurrence()
+				f.AddNextOccurrence()
+				f.AddNextOccurrence()
+			},
+			edit: func(f *File) tea.Cmd {
+				f.ResetMark()
+				return f.InsertNewLine()
+			},
+			want: "foo\n foo\n foo\n",
+		},
+		{
+			name: "replace occurrences",
+			text: "föö bar föö",
+			cursors: func(f *File) {
+				f.SetCursor(0, 1)
+				f.AddNextOccurrence()
+				f.AddNextOccurrence()
+			},
+			edit: func(f *File) tea.Cmd {
+				s := f.Selection()
+				return f.Replace(s.Start.Row, s.Start.Col, s.End.Row, s.End.Col, []byte("x"))
+			},
+			want: "x bar x",
+		},
+		{
+			name: "split selection into lines",
+			text: "one\ntwo\nthree",
+			cursors: func(f *File) {
+				f.SetMark(0, 1)
+				f.SetCursor(2, 2)
+				f.SplitSelectionIntoLines()
+			},
+			edit: func(f *File) tea.Cmd {
+				s := f.Selection()
+				return f.DeleteRange(s.Start, s.End)
+			},
+			want: "o\n\nree",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+
+			d.cursors(f)
+			f.ForEachCursor(func() tea.Cmd {
+				return d.edit(f)
+			})
+			assert.Equal(t, d.want, f.Text())
+
+			f.Undo()
+			assert.Equal(t, d.text, f.Text())
+		})
+	}
+}
+
+func TestFile_ApplyTextEdits_secondaryCursors(t *testing.T) {
+	b, err := buffer.New("test.txt", bytes.NewReader([]byte("a := 1\nb := 2")), "utf-8", buffer.LineEndingLF, false)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeWrite)
+
+	f.SetCursor(0, 5)
+	f.AddCursorBelow()
+
+	// the edit before the secondary cursor moves it to the right
+	f.ApplyTextEdits([]ls.TextEdit{{
+		Range:   buffer.Range{Start: buffer.Position{Row: 0, Col: 0}, End: buffer.Position{Row: 0, Col: 0}},
+		NewText: "x",
+	}})
+	assert.Equal(t, []cursorState{{row: 0, col: 6}}, f.cursors)
+
+	f.ForEachCursor(func() tea.Cmd {
+		return f.Insert([]byte("0"))
+	})
+	assert.Equal(t, "xa := 01\nb := 02", f.Text())
+}
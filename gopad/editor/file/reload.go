@@ -0,0 +1,129 @@
+package file
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/internal/diff"
+)
+
+// Reload replaces the content of the file with the content on disk.
+// Only the changed lines are replaced, so the reload can be undone and the cursor stays in place.
+func (f *File) Reload() (tea.Cmd, error) {
+	// lazily loaded files can't be compared line by line without reading them completely
+	if f.loading || f.buffer.Lazy() {
+		return LoadLazy(f.Name(), f.LineEnding()), nil
+	}
+
+	b, err := buffer.NewFromFile(f.Name(), f.Encoding(), f.LineEnding())
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := f.SetContent(b)
+	f.buffer.SetChecksum(b.Checksum())
+
+	return cmd, nil
+}
+
+// SetContent replaces the content of the file with the content of the buffer.
+// Only the changed lines are replaced, so the change can be undone and the cursor stays in place.
+func (f *File) SetContent(b *buffer.Buffer) tea.Cmd {
+	return f.ApplyTextEdits(lineDiffEdits(bufferLines(f.buffer), bufferLines(b)))
+}
+
+// DiskDiff returns the line diff from the file on disk to the content of the file.
+func (f *File) DiskDiff() ([]diff.Line, error) {
+	b, err := buffer.NewFromFile(f.Name(), f.Encoding(), f.LineEnding())
+	if err != nil {
+		return nil, err
+	}
+
+	return diff.Lines(bufferLines(b), bufferLines(f.buffer)), nil
+}
+
+func bufferLines(b *buffer.Buffer) []string {
+	lines := make([]string, b.LinesLen())
+	for i, line := range b.Lines() {
+		lines[i] = line.String()
+	}
+	return lines
+}
+
+// lineDiffEdits returns the text edits turning the old lines into the new lines.
+func lineDiffEdits(oldLines []string, newLines []string) []ls.TextEdit {
+	lines := diff.Lines(oldLines, newLines)
+
+	var edits []ls.TextEdit
+	row := 0
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == diff.KindEqual {
+			row++
+			i++
+			continue
+		}
+
+		var (
+			deleted  int
+			inserted []string
+		)
+		for ; i < len(lines) && lines[i].Kind != diff.KindEqual; i++ {
+			if lines[i].Kind == diff.KindDelete {
+				deleted++
+			} else {
+				inserted = append(inserted, lines[i].Text)
+			}
+		}
+
+		edits = append(edits, lineEdit(oldLines, row, deleted, inserted))
+		row += deleted
+	}
+
+	return edits
+}
+
+// lineEdit returns the edit replacing count lines starting at row with the new lines.
+func lineEdit(oldLines []string, row int, count int, newLines []string) ls.TextEdit {
+	text := strings.Join(newLines, "\n")
+
+	// the change is in the middle of the file, so the edit can end at the start of the next line
+	if row+count < len(oldLines) {
+		if len(newLines) > 0 {
+			text += "\n"
+		}
+		return ls.TextEdit{
+			Range: buffer.Range{
+				Start: buffer.Position{Row: row},
+				End:   buffer.Position{Row: row + count},
+			},
+			NewText: text,
+		}
+	}
+
+	// the change reaches the end of the file which has no line break after the last line
+	last := len(oldLines) - 1
+	end := buffer.Position{Row: last, Col: utf8.RuneCountInString(oldLines[last])}
+	if row == 0 {
+		return ls.TextEdit{
+			Range: buffer.Range{
+				End: end,
+			},
+			NewText: text,
+		}
+	}
+
+	if len(newLines) > 0 {
+		text = "\n" + text
+	}
+	return ls.TextEdit{
+		Range: buffer.Range{
+			Start: buffer.Position{Row: row - 1, Col: utf8.RuneCountInString(oldLines[row-1])},
+			End:   end,
+		},
+		NewText: text,
+	}
+}
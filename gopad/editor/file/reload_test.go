@@ -0,0 +1,41 @@
+package file
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func TestLineDiffEdits(t *testing.T) {
+	data := []struct {
+		name string
+		old  string
+		new  string
+	}{
+		{name: "unchanged", old: "a\nb", new: "a\nb"},
+		{name: "change middle", old: "a\nb\nc", new: "a\nx\nc"},
+		{name: "insert middle", old: "a\nc", new: "a\nb\nc"},
+		{name: "delete middle", old: "a\nb\nc", new: "a\nc"},
+		{name: "append", old: "a\nb", new: "a\nb\nc\nd"},
+		{name: "delete end", old: "a\nb\nc", new: "a"},
+		{name: "change end", old: "a\nb", new: "a\nc"},
+		{name: "final newline", old: "a\nb", new: "a\nb\n"},
+		{name: "replace all", old: "a\nb", new: "x\ny\nz"},
+		{name: "empty", old: "a\nb", new: ""},
+		{name: "multiple", old: "a\nb\nc\nd\ne", new: "x\nb\nd\ne\nf"},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test", bytes.NewReader([]byte(d.old)), "utf-8", buffer.LineEndingLF, true)
+			assert.NoError(t, err)
+
+			ApplyTextEdits(b, lineDiffEdits(strings.Split(d.old, "\n"), strings.Split(d.new, "\n")))
+			assert.Equal(t, d.new, b.String())
+		})
+	}
+}
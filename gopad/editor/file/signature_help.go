@@ -0,0 +1,86 @@
+package file
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+func NewSignatureHelp(f *File) *SignatureHelp {
+	return &SignatureHelp{
+		file: f,
+	}
+}
+
+type SignatureHelp struct {
+	file *File
+	help ls.SignatureHelp
+	show bool
+}
+
+// Update requests the signature help after the given text was typed or the content changed otherwise.
+func (s *SignatureHelp) Update(text string) tea.Cmd {
+	if text == "" && !s.show {
+		return nil
+	}
+
+	row, col := s.file.Cursor()
+	return ls.GetSignatureHelp(s.file.Name(), row, col, text, s.show)
+}
+
+func (s *SignatureHelp) SetSignatureHelp(help ls.SignatureHelp) {
+	if len(help.Signatures) == 0 {
+		s.Hide()
+		return
+	}
+
+	s.help = help
+	s.show = true
+}
+
+func (s *SignatureHelp) Visible() bool {
+	return s.show
+}
+
+func (s *SignatureHelp) Hide() {
+	s.show = false
+	s.help = ls.SignatureHelp{}
+}
+
+func (s *SignatureHelp) View(width int) string {
+	width = min(width, 80)
+
+	style := config.Theme.UI.Documentation.Style
+	activeStyle := config.Theme.UI.Documentation.ActiveParameterStyle
+
+	signature := s.help.Signatures[min(max(s.help.ActiveSignature, 0), len(s.help.Signatures)-1)]
+
+	label := signature.Label
+	if signature.ActiveParameter >= 0 && signature.ActiveParameter < len(signature.Parameters) {
+		// find the parameter after the previous ones to not highlight a parameter name which appears multiple times
+		var offset int
+		for i, parameter := range signature.Parameters {
+			index := strings.Index(label[offset:], parameter)
+			if index == -1 {
+				break
+			}
+			start := offset + index
+			end := start + len(parameter)
+			if i == signature.ActiveParameter {
+				label = style.Inline(true).Render(label[:start]) + activeStyle.Inline(true).Render(label[start:end]) + style.Inline(true).Render(label[end:])
+				break
+			}
+			offset = end
+		}
+	}
+
+	view := label
+	if doc := strings.TrimSpace(signature.Documentation); doc != "" {
+		view += "\n\n" + strings.SplitN(doc, "\n\n", 2)[0]
+	}
+
+	return style.MaxWidth(width).Render(view)
+}
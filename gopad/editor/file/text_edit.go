@@ -0,0 +1,72 @@
+package file
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+// sortTextEdits returns the edits ordered from the end of the document to the start.
+// Edits starting at the same position keep their relative order once applied.
+func sortTextEdits(edits []ls.TextEdit) []ls.TextEdit {
+	sorted := slices.Clone(edits)
+	slices.Reverse(sorted)
+	slices.SortStableFunc(sorted, func(a, b ls.TextEdit) int {
+		return b.Range.Start.Compare(a.Range.Start)
+	})
+	return sorted
+}
+
+// CheckTextEdits returns an error if an edit is outside the buffer or overlaps another edit.
+func CheckTextEdits(b *buffer.Buffer, edits []ls.TextEdit) error {
+	sorted := sortTextEdits(edits)
+	for i, edit := range sorted {
+		if edit.Range.End.LessThan(edit.Range.Start) {
+			return fmt.Errorf("edit range %s ends before it starts", edit.Range)
+		}
+		if !validPosition(b, edit.Range.Start) || !validPosition(b, edit.Range.End) {
+			return fmt.Errorf("edit range %s is outside of the file", edit.Range)
+		}
+		// the edits are sorted from the end to the start, so the previous one has to start after this one ends
+		if i > 0 && sorted[i-1].Range.Start.LessThan(edit.Range.End) {
+			return fmt.Errorf("edit range %s overlaps %s", edit.Range, sorted[i-1].Range)
+		}
+	}
+	return nil
+}
+
+func validPosition(b *buffer.Buffer, p buffer.Position) bool {
+	return p.Row >= 0 && p.Row < b.LinesLen() && p.Col >= 0 && p.Col <= b.LineLen(p.Row)
+}
+
+// ApplyTextEdits applies the edits as a single undo step and keeps the cursor at its position.
+func (f *File) ApplyTextEdits(edits []ls.TextEdit) tea.Cmd {
+	if len(edits) == 0 {
+		return nil
+	}
+
+	row, col := f.Cursor()
+	mark := f.cursor.mark
+
+	f.BeginBatch()
+	for _, edit := range sortTextEdits(edits) {
+		f.Replace(edit.Range.Start.Row, edit.Range.Start.Col, edit.Range.End.Row, edit.Range.End.Col, []byte(edit.NewText))
+	}
+	cmd := f.EndBatch()
+
+	f.SetCursor(row, col)
+	f.cursor.mark = mark
+
+	return cmd
+}
+
+// ApplyTextEdits applies the edits to a buffer which is not opened as a file.
+func ApplyTextEdits(b *buffer.Buffer, edits []ls.TextEdit) {
+	for _, edit := range sortTextEdits(edits) {
+		b.Replace(edit.Range.Start.Row, edit.Range.Start.Col, edit.Range.End.Row, edit.Range.End.Col, []byte(edit.NewText))
+	}
+}
@@ -0,0 +1,49 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+func TestCheckTextEdits(t *testing.T) {
+	edit := func(startRow, startCol, endRow, endCol int) ls.TextEdit {
+		return ls.TextEdit{
+			Range: buffer.Range{
+				Start: buffer.Position{Row: startRow, Col: startCol},
+				End:   buffer.Position{Row: endRow, Col: endCol},
+			},
+		}
+	}
+
+	data := []struct {
+		name    string
+		edits   []ls.TextEdit
+		wantErr bool
+	}{
+		{name: "valid", edits: []ls.TextEdit{edit(0, 0, 0, 3), edit(1, 0, 1, 3), edit(1, 3, 1, 3)}},
+		{name: "end of line", edits: []ls.TextEdit{edit(1, 3, 1, 3)}},
+		{name: "after line end", edits: []ls.TextEdit{edit(0, 4, 0, 4)}, wantErr: true},
+		{name: "after last line", edits: []ls.TextEdit{edit(2, 0, 2, 0)}, wantErr: true},
+		{name: "reversed", edits: []ls.TextEdit{edit(1, 0, 0, 0)}, wantErr: true},
+		{name: "overlapping", edits: []ls.TextEdit{edit(0, 0, 1, 1), edit(1, 0, 1, 2)}, wantErr: true},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte("foo\nbar")), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+
+			err = CheckTextEdits(b, d.edits)
+			if d.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
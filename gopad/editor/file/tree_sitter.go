@@ -71,46 +71,40 @@ func (t *Tree) FindTree(p buffer.Position) *Tree {
 }
 
 func (f *File) InitTree() tea.Cmd {
-	if f.language == nil || f.language.Grammar == nil {
+	if f.large || f.language == nil || f.language.Grammar == nil {
 		return nil
 	}
 
 	if err := f.updateTree(); err != nil {
 		return notifications.Addf("Error updating tree sitter tree: %s", err.Error())
 	}
+	f.highlights = nil
 
-	name := f.Name()
-	version := f.Version()
-
-	return tea.Batch(
-		HighlightTree(name, version, f.tree.Copy(), f.buffer.LinesLen()),
-		ValidateTree(name, version, f.tree.Copy()),
-	)
+	return ValidateTree(f.Name(), f.Version(), f.tree.Copy())
 }
 
-func (f *File) UpdateTree(edit sitter.EditInput) tea.Cmd {
+func (f *File) UpdateTree(edits ...sitter.EditInput) tea.Cmd {
 	now := time.Now()
 	defer func() {
 		log.Println("Update tree time: ", time.Since(now))
 	}()
 
-	if f.language == nil || f.language.Grammar == nil {
+	if f.large || f.language == nil || f.language.Grammar == nil {
 		return nil
 	}
 
-	editTree(f.tree, edit)
+	for _, edit := range edits {
+		editTree(f.tree, edit)
+	}
 
+	oldTree := f.tree
 	if err := f.updateTree(); err != nil {
 		return notifications.Addf("Error updating tree sitter tree: %s", err.Error())
 	}
+	// the edited lines are already dropped from the highlights, only lines whose syntax changed elsewhere are left
+	f.clearHighlights(changedRanges(oldTree, f.tree))
 
-	name := f.Name()
-	version := f.Version()
-
-	return tea.Batch(
-		HighlightTree(name, version, f.tree.Copy(), f.buffer.LinesLen()),
-		ValidateTree(name, version, f.tree.Copy()),
-	)
+	return ValidateTree(f.Name(), f.Version(), f.tree.Copy())
 }
 
 func (f *File) updateTree() error {
@@ -148,7 +142,13 @@ func parseTree(ctx context.Context, buff *buffer.Buffer, oldTree *Tree, language
 		oldSitterTree = oldTree.Tree
 	}
 
-	tree, err := parser.ParseCtx(ctx, oldSitterTree, buff.Bytes())
+	// the parser reads the buffer line by line instead of copying the whole content
+	tree, err := parser.ParseInputCtx(ctx, oldSitterTree, sitter.Input{
+		Read: func(offset uint32, _ sitter.Point) []byte {
+			return buff.Chunk(int(offset))
+		},
+		Encoding: sitter.InputEncodingUTF8,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error parsing tree: %w", err)
 	}
@@ -243,6 +243,38 @@ func getLanguageByMatch(parentLanguage *Language, match *sitter.QueryMatch) *Lan
 	return nil
 }
 
+// changedRanges returns the ranges whose syntax differs between the edited old tree and the new tree, including the ones of added or removed sub trees.
+func changedRanges(oldTree *Tree, newTree *Tree) []sitter.Range {
+	if oldTree == nil || oldTree.Tree == nil {
+		return []sitter.Range{treeRange(newTree)}
+	}
+
+	ranges := oldTree.Tree.ChangedRanges(newTree.Tree)
+	for name, subTree := range newTree.SubTrees {
+		if oldSubTree, ok := oldTree.SubTrees[name]; ok {
+			ranges = append(ranges, changedRanges(oldSubTree, subTree)...)
+			continue
+		}
+		ranges = append(ranges, treeRange(subTree))
+	}
+	for name, oldSubTree := range oldTree.SubTrees {
+		if _, ok := newTree.SubTrees[name]; !ok {
+			ranges = append(ranges, treeRange(oldSubTree))
+		}
+	}
+	return ranges
+}
+
+func treeRange(tree *Tree) sitter.Range {
+	root := tree.Tree.RootNode()
+	return sitter.Range{
+		StartPoint: root.StartPoint(),
+		EndPoint:   root.EndPoint(),
+		StartByte:  root.StartByte(),
+		EndByte:    root.EndByte(),
+	}
+}
+
 func editTree(tree *Tree, edit sitter.EditInput) {
 	tree.Tree.Edit(edit)
 
@@ -2,13 +2,10 @@ package file
 
 import (
 	"fmt"
-	"log"
+	"slices"
 	"strconv"
 	"strings"
-	"sync"
-	"time"
 
-	"github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss"
 	"go.gopad.dev/go-tree-sitter"
 
@@ -16,53 +13,102 @@ import (
 	"go.gopad.dev/gopad/gopad/config"
 )
 
-func HighlightTree(name string, version int32, tree *Tree, lines int) tea.Cmd {
-	return func() tea.Msg {
-		if tree == nil || tree.Tree == nil || tree.Language.Grammar == nil {
-			return nil
-		}
+// highlightMargin is the number of lines above and below the view which are highlighted with it, so scrolling shows highlighted lines right away.
+const highlightMargin = 50
 
-		return UpdateMatchesMsg{
-			Name:    name,
-			Version: version,
-			Matches: highlightTree(tree, lines),
-		}
-	}
+// lineMatch is a match covering the columns from start to end (exclusive) of a line, end is -1 if the match continues on the next line.
+type lineMatch struct {
+	start int
+	end   int
+	match *Match
+}
+
+// RefreshHighlights highlights the lines a view of the given height shows, so View only reads the cached highlights.
+func (f *File) RefreshHighlights(height int) {
+	f.RefreshHighlightsFor(f.CursorState(), height)
 }
 
-type UpdateMatchesMsg struct {
-	Name    string
-	Version int32
-	Matches [][]*Match
+// RefreshHighlightsFor highlights the lines a view of the given height with the cursors of the state shows.
+func (f *File) RefreshHighlightsFor(s CursorState, height int) {
+	if f.tree == nil {
+		return
+	}
+
+	// the stored cursors are moved by the changes since the state was taken
+	v := *f
+	v.SetCursorState(s)
+	offsetRow, _ := v.CursorOffset()
+	cursorRow, _ := v.Cursor()
+	// the view scrolls to the cursor if it is outside of it
+	if cursorRow < offsetRow {
+		offsetRow = cursorRow
+	} else if cursorRow >= offsetRow+height {
+		offsetRow = cursorRow - height + 1
+	}
+
+	// wrapped lines take up at least one row, so the view never shows more than height lines
+	f.refreshHighlights(offsetRow-highlightMargin, offsetRow+height+highlightMargin)
 }
 
-func (f *File) SetMatches(version int32, matches [][]*Match) {
-	log.Println("setting matches", version, len(matches))
-	if version < f.matchesVersion {
-		log.Printf("skipping outdated matches: %d < %d", version, f.matchesVersion)
+// refreshHighlights highlights the lines from start to end (exclusive) which are not cached yet.
+// The matches are cached per line, lines which are not highlighted yet are nil.
+func (f *File) refreshHighlights(start int, end int) {
+	if f.tree == nil || f.tree.Tree == nil || f.tree.Language.Grammar == nil {
 		return
 	}
-	if version > f.matchesVersion {
-		f.matches = matches
-		f.matchesVersion = version
+
+	if len(f.highlights) != f.buffer.LinesLen() {
+		f.highlights = make([][]lineMatch, f.buffer.LinesLen())
+	}
+	start = max(start, 0)
+	end = min(end, len(f.highlights))
+	for start < end && f.highlights[start] != nil {
+		start++
+	}
+	for end > start && f.highlights[end-1] != nil {
+		end--
+	}
+	if start == end {
 		return
 	}
-	f.matches = append(f.matches, matches...)
+
+	// cached lines in between are highlighted again, so they are reset to not contain matches twice
+	for row := start; row < end; row++ {
+		f.highlights[row] = []lineMatch{}
+	}
+	f.highlightTree(f.tree, start, end)
 }
 
-func (f *File) MatchesForLineCol(row int, col int) []*Match {
-	pos := buffer.Position{Row: row, Col: col}
+// shiftHighlights moves the cached highlights of the lines after the change and drops the ones of the changed lines.
+// Changes reaching past the cached lines only drop the cached lines they cover.
+func (f *File) shiftHighlights(change Change) {
+	if f.highlights == nil {
+		return
+	}
+	oldEnd := min(change.Range.End.Row+1, len(f.highlights))
+	start := min(change.Range.Start.Row, oldEnd)
+	newEnd := max(int(change.NewEndPoint.Row)+1, start)
+	f.highlights = slices.Replace(f.highlights, start, oldEnd, make([][]lineMatch, newEnd-start)...)
+}
 
-	if len(f.matches) <= row {
-		return nil
+// clearHighlights drops the cached highlights of the lines in the ranges.
+func (f *File) clearHighlights(ranges []sitter.Range) {
+	for _, r := range ranges {
+		for row := int(r.StartPoint.Row); row <= int(r.EndPoint.Row) && row < len(f.highlights); row++ {
+			f.highlights[row] = nil
+		}
 	}
+}
 
-	lineMatches := f.matches[row]
+func (f *File) MatchesForLineCol(row int, col int) []*Match {
+	if len(f.highlights) <= row {
+		return nil
+	}
 
 	var matches []*Match
-	for _, match := range lineMatches {
-		if match.Range.Contains(pos) {
-			matches = append(matches, match)
+	for _, m := range f.highlights[row] {
+		if col >= m.start && (m.end < 0 || col < m.end) {
+			matches = append(matches, m.match)
 		}
 	}
 
@@ -143,155 +189,162 @@ type LocalScope struct {
 	LocalDefs []*LocalDef
 }
 
-func highlightTree(tree *Tree, lines int) [][]*Match {
-	now := time.Now()
-	defer func() {
-		log.Println("highlightTree took", time.Since(now))
-	}()
-
+// highlightTree adds the matches of the tree and its sub trees in the lines from start to end (exclusive) to the cached highlights.
+func (f *File) highlightTree(tree *Tree, start int, end int) {
 	query := tree.Language.Grammar.HighlightsQuery
 	queryCursor := sitter.NewQueryCursor()
+	// locals defined before the first line are needed to highlight references to them
+	queryCursor.SetPointRange(sitter.Point{Row: uint32(scopeStart(tree.Tree.RootNode(), start))}, sitter.Point{Row: uint32(end)})
 	queryCursor.Exec(query.Query, tree.Tree.RootNode())
 
-	lineMatches := make([][]*Match, lines)
-	var lineMatchesMu sync.Mutex
 	var scopes []*LocalScope
 	var lastDef *LocalDef
 	var lastRef *LocalDef
 	var lastCapture *sitter.QueryCapture
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+	for {
+		match, index, ok := queryCursor.NextCapture()
+		if !ok {
+			break
+		}
+		capture := match.Captures[index]
+
+		captureRange := buffer.Range{
+			Start: buffer.Position{
+				Row: int(capture.StartPoint().Row),
+				Col: int(capture.StartPoint().Column),
+			},
+			End: buffer.Position{
+				Row: int(capture.EndPoint().Row),
+				Col: int(capture.EndPoint().Column),
+			},
+		}
 
 		for {
-			match, index, ok := queryCursor.NextCapture()
-			if !ok {
+			if len(scopes) == 0 {
 				break
 			}
-			capture := match.Captures[index]
-
-			captureRange := buffer.Range{
-				Start: buffer.Position{
-					Row: int(capture.StartPoint().Row),
-					Col: int(capture.StartPoint().Column),
-				},
-				End: buffer.Position{
-					Row: int(capture.EndPoint().Row),
-					Col: int(capture.EndPoint().Column),
-				},
+			lastScope := scopes[len(scopes)-1]
+			if captureRange.Start.GreaterThan(lastScope.Range.End) {
+				scopes = scopes[:len(scopes)-1]
+				lastRef = nil
+				lastDef = nil
+				continue
 			}
 
-			for {
-				if len(scopes) == 0 {
-					break
-				}
-				lastScope := scopes[len(scopes)-1]
-				if captureRange.Start.GreaterThan(lastScope.Range.End) {
-					scopes = scopes[:len(scopes)-1]
-					lastRef = nil
-					lastDef = nil
-					continue
-				}
+			break
+		}
 
-				break
-			}
+		// if lastCapture != nil && !capture.Node.Equal(lastCapture.Node) {
+		if lastCapture != nil && lastCapture.Node.Content() != capture.Node.Content() {
+			lastDef = nil
+			lastRef = nil
+		}
 
-			// if lastCapture != nil && !capture.Node.Equal(lastCapture.Node) {
-			if lastCapture != nil && lastCapture.Node.Content() != capture.Node.Content() {
-				lastDef = nil
-				lastRef = nil
-			}
+		if uint32(match.PatternIndex) < query.HighlightsPatternIndex {
+			if query.ScopeCaptureID != nil && capture.Index == *query.ScopeCaptureID {
+				scopes = append(scopes, &LocalScope{
+					Inherits:  true,
+					Range:     captureRange,
+					LocalDefs: nil,
+				})
+			} else if query.DefinitionCaptureID != nil && capture.Index == *query.DefinitionCaptureID {
+				if len(scopes) > 0 {
+					def := &LocalDef{
+						Name: capture.Node.Content(),
+						Type: "",
+					}
 
-			if uint32(match.PatternIndex) < query.HighlightsPatternIndex {
-				if query.ScopeCaptureID != nil && capture.Index == *query.ScopeCaptureID {
-					scopes = append(scopes, &LocalScope{
-						Inherits:  true,
-						Range:     captureRange,
-						LocalDefs: nil,
-					})
-				} else if query.DefinitionCaptureID != nil && capture.Index == *query.DefinitionCaptureID {
-					if len(scopes) > 0 {
-						def := &LocalDef{
-							Name: capture.Node.Content(),
-							Type: "",
-						}
+					lastDef = def
 
-						lastDef = def
+					scope := scopes[len(scopes)-1]
+					scope.LocalDefs = append(scope.LocalDefs, def)
+				}
+			} else if query.ReferenceCaptureID != nil && capture.Index == *query.ReferenceCaptureID {
+				for i := len(scopes) - 1; i >= 0; i-- {
+					for ii := len(scopes[i].LocalDefs) - 1; ii >= 0; ii-- {
+						def := scopes[i].LocalDefs[ii]
 
-						scope := scopes[len(scopes)-1]
-						scope.LocalDefs = append(scope.LocalDefs, def)
-					}
-				} else if query.ReferenceCaptureID != nil && capture.Index == *query.ReferenceCaptureID {
-					for i := len(scopes) - 1; i >= 0; i-- {
-						for ii := len(scopes[i].LocalDefs) - 1; ii >= 0; ii-- {
-							def := scopes[i].LocalDefs[ii]
-
-							if def.Type != "" && def.Name == capture.Node.Content() {
-								lastRef = def
-								break
-							}
-						}
-						if !scopes[i].Inherits {
+						if def.Type != "" && def.Name == capture.Node.Content() {
+							lastRef = def
 							break
 						}
 					}
+					if !scopes[i].Inherits {
+						break
+					}
 				}
-
-				lastCapture = &capture
-				continue
 			}
 
-			if lastDef != nil {
-				lastDef.Type = query.Query.CaptureNameForID(capture.Index)
-			}
+			lastCapture = &capture
+			continue
+		}
 
-			var refType string
-			if lastRef != nil {
-				refType = lastRef.Type
-			}
+		if lastDef != nil {
+			lastDef.Type = query.Query.CaptureNameForID(capture.Index)
+		}
 
-			lineMatch := &Match{
-				Range: buffer.Range{
-					Start: buffer.Position{Row: int(capture.StartPoint().Row), Col: int(capture.StartPoint().Column)},
-					End:   buffer.Position{Row: int(capture.EndPoint().Row), Col: max(0, int(capture.EndPoint().Column)-1)}, // -1 to exclude the last character idk why this is like this tbh
-				},
-				Type:          query.Query.CaptureNameForID(capture.Index),
-				ReferenceType: refType,
-				Priority:      getPriority(match),
-				Source:        tree.Language.Name,
-			}
+		var refType string
+		if lastRef != nil {
+			refType = lastRef.Type
+		}
 
-			for row := captureRange.Start.Row; row <= captureRange.End.Row; row++ {
-				lineMatchesMu.Lock()
-				lineMatches[row] = append(lineMatches[row], lineMatch)
-				lineMatchesMu.Unlock()
-			}
+		lineMatch := &Match{
+			Range: buffer.Range{
+				Start: buffer.Position{Row: int(capture.StartPoint().Row), Col: int(capture.StartPoint().Column)},
+				End:   buffer.Position{Row: int(capture.EndPoint().Row), Col: max(0, int(capture.EndPoint().Column)-1)}, // -1 to exclude the last character idk why this is like this tbh
+			},
+			Type:          query.Query.CaptureNameForID(capture.Index),
+			ReferenceType: refType,
+			Priority:      getPriority(match),
+			Source:        tree.Language.Name,
+		}
 
-			lastCapture = &capture
+		for row := max(captureRange.Start.Row, start); row <= min(captureRange.End.Row, end-1); row++ {
+			f.addLineMatch(row, captureRange, lineMatch)
 		}
-	}()
 
-	for _, subTree := range tree.SubTrees {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
+		lastCapture = &capture
+	}
 
-			for row, matches := range highlightTree(subTree, lines) {
-				if len(matches) == 0 {
-					continue
-				}
-				lineMatchesMu.Lock()
-				lineMatches[row] = append(lineMatches[row], matches...)
-				lineMatchesMu.Unlock()
-			}
-		}()
+	for _, subTree := range tree.SubTrees {
+		f.highlightTree(subTree, start, end)
 	}
+}
 
-	wg.Wait()
+// scopeStart returns the first row of the top level node containing the row, or the row if it is not inside one.
+func scopeStart(root *sitter.Node, row int) int {
+	n := root.DescendantForRange(sitter.Point{Row: uint32(row)}, sitter.Point{Row: uint32(row)})
+	for n != nil {
+		parent := n.Parent()
+		if parent == nil || parent.Equal(root) {
+			break
+		}
+		n = parent
+	}
+	if n == nil || n.Equal(root) {
+		return row
+	}
+	return min(int(n.StartPoint().Row), row)
+}
 
-	return lineMatches
+// addLineMatch adds the part of the match in the row to the cached highlights, the byte columns of the capture are converted to rune columns.
+func (f *File) addLineMatch(row int, captureRange buffer.Range, match *Match) {
+	line := f.buffer.Line(row)
+	m := lineMatch{
+		end:   -1,
+		match: match,
+	}
+	if row == captureRange.Start.Row {
+		m.start = line.DecodeCol(captureRange.Start.Col, buffer.PositionEncodingUTF8)
+	}
+	if row == captureRange.End.Row {
+		m.end = line.DecodeCol(captureRange.End.Col, buffer.PositionEncodingUTF8)
+		if m.end <= m.start {
+			return
+		}
+	}
+	f.highlights[row] = append(f.highlights[row], m)
 }
 
 func getPriority(match *sitter.QueryMatch) int {
@@ -0,0 +1,112 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/stretchr/testify/assert"
+	"go.gopad.dev/go-tree-sitter"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func TestFile_shiftHighlights(t *testing.T) {
+	data := []struct {
+		name string
+		edit func(f *File) tea.Cmd
+		want []string
+	}{
+		{
+			name: "insert in line",
+			edit: func(f *File) tea.Cmd {
+				f.SetCursor(1, 1)
+				return f.Insert([]byte("x"))
+			},
+			want: []string{"0", "", "2", "3"},
+		},
+		{
+			name: "insert new line",
+			edit: func(f *File) tea.Cmd {
+				f.SetCursor(1, 1)
+				return f.InsertNewLine()
+			},
+			want: []string{"0", "", "", "2", "3"},
+		},
+		{
+			name: "insert lines",
+			edit: func(f *File) tea.Cmd {
+				f.SetCursor(0, 0)
+				return f.Insert([]byte("x\ny\nz"))
+			},
+			want: []string{"", "", "", "1", "2", "3"},
+		},
+		{
+			name: "delete lines",
+			edit: func(f *File) tea.Cmd {
+				return f.Replace(1, 1, 3, 0, nil)
+			},
+			want: []string{"0", ""},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte("a\nbb\ncc\nd")), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+			for row := range b.LinesLen() {
+				f.highlights = append(f.highlights, []lineMatch{{end: -1, match: &Match{Type: fmt.Sprint(row)}}})
+			}
+
+			d.edit(f)
+
+			assert.Len(t, f.highlights, f.buffer.LinesLen())
+			var got []string
+			for row := range f.highlights {
+				var types string
+				for _, match := range f.MatchesForLineCol(row, 0) {
+					types += match.Type
+				}
+				got = append(got, types)
+			}
+			assert.Equal(t, d.want, got)
+		})
+	}
+}
+
+func TestFile_shiftHighlights_pastEnd(t *testing.T) {
+	f := &File{}
+	for row := range 4 {
+		f.highlights = append(f.highlights, []lineMatch{{end: -1, match: &Match{Type: fmt.Sprint(row)}}})
+	}
+
+	f.shiftHighlights(Change{
+		Range: buffer.Range{
+			Start: buffer.Position{Row: 2},
+			End:   buffer.Position{Row: 6},
+		},
+		NewEndPoint: sitter.Point{Row: 3},
+	})
+
+	assert.Len(t, f.highlights, 4)
+	assert.NotNil(t, f.highlights[0])
+	assert.NotNil(t, f.highlights[1])
+	assert.Nil(t, f.highlights[2])
+	assert.Nil(t, f.highlights[3])
+}
+
+func TestFile_MatchesForLineCol(t *testing.T) {
+	b, err := buffer.New("test.txt", bytes.NewReader([]byte("ä := b")), "utf-8", buffer.LineEndingLF, false)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeWrite)
+	f.highlights = make([][]lineMatch, 1)
+
+	match := &Match{Type: "variable"}
+	// the capture of ä ends at byte column 2
+	f.addLineMatch(0, buffer.Range{End: buffer.Position{Col: 2}}, match)
+
+	assert.Equal(t, []*Match{match}, f.MatchesForLineCol(0, 0))
+	assert.Empty(t, f.MatchesForLineCol(0, 1))
+}
@@ -0,0 +1,306 @@
+package file
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"go.gopad.dev/go-tree-sitter"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+)
+
+// indentation is the indentation of a line computed from the indents query.
+type indentation struct {
+	level int
+	// align is the position the line is aligned to instead of indenting it by level, nil if it isn't aligned
+	align *sitter.Point
+	// outdent is set if the line starts with an @outdent node
+	outdent bool
+}
+
+// indentCapture is an @indent or @indent.always node of the indents query.
+type indentCapture struct {
+	start  sitter.Point
+	end    sitter.Point
+	always bool
+	extend bool
+}
+
+// alignCapture is an @align node with the position of its @anchor.
+type alignCapture struct {
+	start  sitter.Point
+	end    sitter.Point
+	anchor sitter.Point
+}
+
+// indentCaptures are the captures of the indents query for a position.
+type indentCaptures struct {
+	indents []indentCapture
+	aligns  []alignCapture
+	// prevent is set if an @extend.prevent-once node ends on the line before the position
+	prevent bool
+	outdent bool
+}
+
+// indentation returns the indentation of a line starting with the text after the position.
+// A line is indented once for every line an @indent node containing the position starts on, @indent.always nodes indent it even if they start on the same line.
+// @extend nodes also contain positions right after them on their last line, unless an @extend.prevent-once node ends before the position which stops the innermost one.
+// A line inside an @align node after its @anchor is aligned to the anchor, a line starting with an @outdent node is indented one level less instead.
+func (c indentCaptures) indentation(pos sitter.Point) indentation {
+	var (
+		contained []indentCapture
+		extended  []indentCapture
+	)
+	for _, node := range c.indents {
+		if !pointBefore(node.start, pos) {
+			continue
+		}
+		if pointBefore(pos, node.end) {
+			contained = append(contained, node)
+		} else if node.extend && node.end.Row == pos.Row {
+			extended = append(extended, node)
+		}
+	}
+	if c.prevent && len(extended) > 0 {
+		innermost := 0
+		for i, node := range extended {
+			if pointBefore(extended[innermost].start, node.start) {
+				innermost = i
+			}
+		}
+		extended = slices.Delete(extended, innermost, innermost+1)
+	}
+
+	var always int
+	rows := make(map[uint32]struct{})
+	for _, node := range append(contained, extended...) {
+		if node.always {
+			always++
+			continue
+		}
+		rows[node.start.Row] = struct{}{}
+	}
+
+	ind := indentation{
+		level:   len(rows) + always,
+		outdent: c.outdent,
+	}
+	if c.outdent {
+		ind.level--
+	}
+	ind.level = max(ind.level, 0)
+
+	// closing nodes are not aligned, they go back to the level of the opening line
+	if !c.outdent {
+		var align *alignCapture
+		for i, node := range c.aligns {
+			if pointBefore(node.start, pos) && pointBefore(pos, node.end) && pointBefore(node.anchor, pos) && (align == nil || pointBefore(align.start, node.start)) {
+				align = &c.aligns[i]
+			}
+		}
+		if align != nil {
+			ind.align = &align.anchor
+		}
+	}
+
+	return ind
+}
+
+// indentLevel returns the indentation of a line starting with the text after the position.
+// It returns false if there is no tree or indents query for the position.
+func (f *File) indentLevel(row int, col int) (indentation, bool) {
+	if f.tree == nil || f.tree.Tree == nil {
+		return indentation{}, false
+	}
+
+	tree := f.tree.FindTree(buffer.Position{Row: row, Col: col})
+	if tree.Language.Grammar == nil || tree.Language.Grammar.IndentsQuery == nil {
+		return indentation{}, false
+	}
+	query := tree.Language.Grammar.IndentsQuery
+
+	pos := f.point(buffer.Position{Row: row, Col: col})
+	rest := f.buffer.Line(row).Bytes()[pos.Column:]
+	lineStart := sitter.Point{Row: pos.Row, Column: pos.Column + uint32(len(rest)-len(bytes.TrimLeft(rest, " \t")))}
+
+	queryCursor := sitter.NewQueryCursor()
+	// anchors of @align nodes are on lines before the position
+	queryCursor.SetPointRange(sitter.Point{Row: uint32(scopeStart(tree.Tree.RootNode(), row))}, sitter.Point{Row: uint32(row + 1)})
+	queryCursor.Exec(query.Query, tree.Tree.RootNode())
+
+	isCapture := func(id *uint32, capture sitter.QueryCapture) bool {
+		return id != nil && capture.Index == *id
+	}
+
+	var (
+		captures indentCaptures
+		indents  = make(map[[2]uint32]int)
+		extended = make(map[[2]uint32]bool)
+	)
+	for {
+		match, index, ok := queryCursor.NextCapture()
+		if !ok {
+			break
+		}
+		capture := match.Captures[index]
+		node := capture.Node
+
+		switch {
+		case isCapture(query.IndentCaptureID, capture), isCapture(query.IndentAlwaysCaptureID, capture):
+			always := isCapture(query.IndentAlwaysCaptureID, capture)
+			if i, ok := indents[nodeKey(node)]; ok {
+				captures.indents[i].always = captures.indents[i].always || always
+				continue
+			}
+			indents[nodeKey(node)] = len(captures.indents)
+			captures.indents = append(captures.indents, indentCapture{
+				start:  node.StartPoint(),
+				end:    node.EndPoint(),
+				always: always,
+			})
+		case isCapture(query.ExtendCaptureID, capture):
+			extended[nodeKey(node)] = true
+		case isCapture(query.ExtendPreventOnceCaptureID, capture):
+			if end := node.EndPoint(); end.Row == pos.Row && !pointBefore(pos, end) {
+				captures.prevent = true
+			}
+		case isCapture(query.OutdentCaptureID, capture):
+			if node.StartPoint() == lineStart {
+				captures.outdent = true
+			}
+		case isCapture(query.AlignCaptureID, capture):
+			for _, anchor := range match.Captures {
+				if isCapture(query.AnchorCaptureID, anchor) {
+					captures.aligns = append(captures.aligns, alignCapture{
+						start:  node.StartPoint(),
+						end:    node.EndPoint(),
+						anchor: anchor.Node.StartPoint(),
+					})
+				}
+			}
+		}
+	}
+
+	// @extend can be captured before or after @indent of the same node
+	for key, i := range indents {
+		captures.indents[i].extend = extended[key]
+	}
+
+	return captures.indentation(pos), true
+}
+
+func nodeKey(node *sitter.Node) [2]uint32 {
+	return [2]uint32{node.StartByte(), node.EndByte()}
+}
+
+func pointBefore(a sitter.Point, b sitter.Point) bool {
+	return a.Row < b.Row || a.Row == b.Row && a.Column < b.Column
+}
+
+// indent returns the indentation of the given level using the indent style of the file.
+func (f *File) indent(level int) string {
+	return strings.Repeat(f.EditorConfig().Indent(), level)
+}
+
+// indentString returns the indentation in the indent style of the file, aligned lines are indented up to the screen column of their anchor.
+func (f *File) indentString(ind indentation) string {
+	if ind.align == nil {
+		return f.indent(ind.level)
+	}
+	line := f.buffer.Line(int(ind.align.Row))
+	col := line.DecodeCol(int(ind.align.Column), buffer.PositionEncodingUTF8)
+	return f.widthIndent(visualCol(line, col, f.TabSize()))
+}
+
+// lineIndent returns the indentation of the row converted to the indent style of the file.
+// Tabs count as tab_size columns.
+func (f *File) lineIndent(row int) string {
+	line := f.buffer.Line(row)
+	return f.widthIndent(visualCol(line, leadingWhitespace(line), f.TabSize()))
+}
+
+// widthIndent returns an indentation of the given screen width in the indent style of the file.
+func (f *File) widthIndent(width int) string {
+	cfg := f.EditorConfig()
+	if cfg.Indent() != "\t" {
+		return strings.Repeat(" ", width)
+	}
+	return strings.Repeat("\t", width/f.TabSize()) + strings.Repeat(" ", width%f.TabSize())
+}
+
+// indentLevelFunc returns the indentation of a line starting with the text after the position, false if it can't be computed.
+type indentLevelFunc func(row int, col int) (indentation, bool)
+
+// newLineIndent returns the indentation of a line inserted at the position.
+// Without an indents query the indentation of the current line is copied.
+// If the line is split between an opening and a closing node, the indentation of an empty line between them is returned as well.
+func (f *File) newLineIndent(row int, col int) (string, *string) {
+	return f.newLineIndentWith(f.indentLevel, row, col)
+}
+
+func (f *File) newLineIndentWith(indentLevel indentLevelFunc, row int, col int) (string, *string) {
+	ind, ok := indentLevel(row, col)
+	if !ok {
+		return f.lineIndent(row), nil
+	}
+	if ind.outdent && leadingWhitespace(f.buffer.Line(row)) < col {
+		inner := f.indent(ind.level + 1)
+		return f.indentString(ind), &inner
+	}
+	return f.indentString(ind), nil
+}
+
+// Reindent replaces the indentation of the selected lines or the current line with the one computed from the indents query.
+// Empty lines are left alone.
+func (f *File) Reindent() tea.Cmd {
+	row, _ := f.Cursor()
+	start, end := row, row
+	if s := f.Selection(); s != nil {
+		start, end = s.Start.Row, s.End.Row
+	}
+
+	edits, ok := f.reindentEdits(f.indentLevel, start, end)
+	if !ok {
+		return notifications.Add("no indents query available for this file")
+	}
+	return f.ApplyTextEdits(edits)
+}
+
+// reindentEdits returns the edits replacing the indentation of the rows from start to end (inclusive) which differs from the computed one.
+func (f *File) reindentEdits(indentLevel indentLevelFunc, start int, end int) ([]ls.TextEdit, bool) {
+	var edits []ls.TextEdit
+	for r := start; r <= end; r++ {
+		line := f.buffer.Line(r)
+		indentLen := leadingWhitespace(line)
+		if indentLen == line.Len() {
+			continue
+		}
+
+		ind, ok := indentLevel(r, indentLen)
+		if !ok {
+			return nil, false
+		}
+		if indent := f.indentString(ind); line.StringRange(0, indentLen) != indent {
+			edits = append(edits, ls.TextEdit{
+				Range: buffer.Range{
+					Start: buffer.Position{Row: r},
+					End:   buffer.Position{Row: r, Col: indentLen},
+				},
+				NewText: indent,
+			})
+		}
+	}
+	return edits, true
+}
+
+func leadingWhitespace(line buffer.Line) int {
+	var n int
+	for n < line.Len() && (line.Rune(n) == ' ' || line.Rune(n) == '\t') {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,321 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.gopad.dev/go-tree-sitter"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+)
+
+func TestFile_InsertNewLine_indent(t *testing.T) {
+	data := []struct {
+		name   string
+		text   string
+		col    int
+		editor config.EditorConfig
+		want   string
+	}{
+		{
+			name:   "copy tabs",
+			text:   "\t\tfoo",
+			col:    5,
+			editor: config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleTab},
+			want:   "\t\tfoo\n\t\t",
+		},
+		{
+			name:   "tabs to spaces",
+			text:   "\t  foo",
+			col:    6,
+			editor: config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleSpace, IndentSize: 2},
+			want:   "\t  foo\n      ",
+		},
+		{
+			name:   "spaces to tabs",
+			text:   "      foo",
+			col:    9,
+			editor: config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleTab},
+			want:   "      foo\n\t  ",
+		},
+		{
+			name:   "split line",
+			text:   "  foo bar",
+			col:    6,
+			editor: config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleSpace, IndentSize: 2},
+			want:   "  foo \n  bar",
+		},
+	}
+
+	editor := config.Gopad.Editor
+	defer func() {
+		config.Gopad.Editor = editor
+	}()
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			config.Gopad.Editor = d.editor
+
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+			f.SetCursor(0, d.col)
+
+			f.InsertNewLine()
+			assert.Equal(t, d.want, f.buffer.String())
+
+			row, col := f.Cursor()
+			assert.Equal(t, 1, row)
+			assert.Equal(t, len([]rune(d.want))-len([]rune(d.text))-1, col)
+		})
+	}
+}
+
+func TestIndentCaptures_indentation(t *testing.T) {
+	point := func(row uint32, col uint32) sitter.Point {
+		return sitter.Point{Row: row, Column: col}
+	}
+
+	data := []struct {
+		name      string
+		captures  indentCaptures
+		pos       sitter.Point
+		wantLevel int
+		wantAlign *sitter.Point
+	}{
+		{
+			name: "inside indent",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 5), end: point(2, 1)},
+			}},
+			pos:       point(0, 6),
+			wantLevel: 1,
+		},
+		{
+			name: "before indent",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 5), end: point(2, 1)},
+			}},
+			pos:       point(0, 5),
+			wantLevel: 0,
+		},
+		{
+			name: "indents on the same line",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 0), end: point(3, 0)},
+				{start: point(0, 5), end: point(2, 1)},
+			}},
+			pos:       point(1, 0),
+			wantLevel: 1,
+		},
+		{
+			name: "indent always on the same line",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 0), end: point(3, 0), always: true},
+				{start: point(0, 2), end: point(2, 1), always: true},
+			}},
+			pos:       point(1, 0),
+			wantLevel: 2,
+		},
+		{
+			name: "outdent",
+			captures: indentCaptures{
+				indents: []indentCapture{{start: point(0, 5), end: point(2, 1)}},
+				outdent: true,
+			},
+			pos:       point(2, 0),
+			wantLevel: 0,
+		},
+		{
+			name: "after indent",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 0), end: point(1, 5)},
+			}},
+			pos:       point(1, 5),
+			wantLevel: 0,
+		},
+		{
+			name: "extend",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 0), end: point(1, 5), extend: true},
+			}},
+			pos:       point(1, 5),
+			wantLevel: 1,
+		},
+		{
+			name: "extend on a later line",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 0), end: point(1, 5), extend: true},
+			}},
+			pos:       point(2, 0),
+			wantLevel: 0,
+		},
+		{
+			name: "extend nested",
+			captures: indentCaptures{indents: []indentCapture{
+				{start: point(0, 0), end: point(2, 10), extend: true},
+				{start: point(1, 4), end: point(2, 10), extend: true},
+			}},
+			pos:       point(2, 10),
+			wantLevel: 2,
+		},
+		{
+			name: "extend prevent once",
+			captures: indentCaptures{
+				indents: []indentCapture{
+					{start: point(0, 0), end: point(2, 10), extend: true},
+					{start: point(1, 4), end: point(2, 10), extend: true},
+				},
+				prevent: true,
+			},
+			pos:       point(2, 10),
+			wantLevel: 1,
+		},
+		{
+			name: "align",
+			captures: indentCaptures{
+				indents: []indentCapture{{start: point(0, 5), end: point(1, 3)}},
+				aligns:  []alignCapture{{start: point(0, 5), end: point(1, 3), anchor: point(0, 6)}},
+			},
+			pos:       point(1, 0),
+			wantLevel: 1,
+			wantAlign: &sitter.Point{Row: 0, Column: 6},
+		},
+		{
+			name: "align before anchor",
+			captures: indentCaptures{
+				indents: []indentCapture{{start: point(0, 5), end: point(1, 3)}},
+				aligns:  []alignCapture{{start: point(0, 5), end: point(1, 3), anchor: point(0, 6)}},
+			},
+			pos:       point(0, 6),
+			wantLevel: 1,
+		},
+		{
+			name: "align outdent",
+			captures: indentCaptures{
+				indents: []indentCapture{{start: point(0, 5), end: point(1, 3)}},
+				aligns:  []alignCapture{{start: point(0, 5), end: point(1, 3), anchor: point(0, 6)}},
+				outdent: true,
+			},
+			pos:       point(1, 2),
+			wantLevel: 0,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			ind := d.captures.indentation(d.pos)
+			assert.Equal(t, d.wantLevel, ind.level)
+			assert.Equal(t, d.wantAlign, ind.align)
+		})
+	}
+}
+
+func TestFile_newLineIndentWith(t *testing.T) {
+	data := []struct {
+		name       string
+		text       string
+		col        int
+		editor     config.EditorConfig
+		ind        indentation
+		wantIndent string
+		wantInner  *string
+	}{
+		{
+			name:       "indent",
+			text:       "{",
+			col:        1,
+			editor:     config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleSpace, IndentSize: 2},
+			ind:        indentation{level: 1},
+			wantIndent: "  ",
+		},
+		{
+			name:       "split pair",
+			text:       "\t{}",
+			col:        2,
+			editor:     config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleTab},
+			ind:        indentation{level: 1, outdent: true},
+			wantIndent: "\t",
+			wantInner:  ptr("\t\t"),
+		},
+		{
+			name:       "outdent at line start",
+			text:       "}",
+			col:        0,
+			editor:     config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleTab},
+			ind:        indentation{level: 0, outdent: true},
+			wantIndent: "",
+		},
+		{
+			name:       "align",
+			text:       "\tfoo(a,",
+			col:        7,
+			editor:     config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleTab},
+			ind:        indentation{level: 2, align: &sitter.Point{Row: 0, Column: 5}},
+			wantIndent: "\t\t",
+		},
+		{
+			name:       "align with spaces",
+			text:       "foo(a,",
+			col:        6,
+			editor:     config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleSpace, IndentSize: 2},
+			ind:        indentation{level: 1, align: &sitter.Point{Row: 0, Column: 4}},
+			wantIndent: "    ",
+		},
+	}
+
+	editor := config.Gopad.Editor
+	defer func() {
+		config.Gopad.Editor = editor
+	}()
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			config.Gopad.Editor = d.editor
+
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+
+			indent, inner := f.newLineIndentWith(func(int, int) (indentation, bool) {
+				return d.ind, true
+			}, 0, d.col)
+			assert.Equal(t, d.wantIndent, indent)
+			assert.Equal(t, d.wantInner, inner)
+		})
+	}
+}
+
+func TestFile_reindentEdits(t *testing.T) {
+	editor := config.Gopad.Editor
+	defer func() {
+		config.Gopad.Editor = editor
+	}()
+	config.Gopad.Editor = config.EditorConfig{TabSize: 4, IndentStyle: config.IndentStyleSpace, IndentSize: 2}
+
+	b, err := buffer.New("test.txt", bytes.NewReader([]byte("func() {\nfoo\n\n      bar\n  }")), "utf-8", buffer.LineEndingLF, false)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeWrite)
+
+	levels := []indentation{{level: 0}, {level: 1}, {level: 1}, {level: 1}, {level: 0, outdent: true}}
+	edits, ok := f.reindentEdits(func(row int, col int) (indentation, bool) {
+		return levels[row], true
+	}, 0, 4)
+	assert.True(t, ok)
+	// the first line already has the right indentation and the empty line is left alone
+	assert.Len(t, edits, 3)
+
+	ApplyTextEdits(b, edits)
+	assert.Equal(t, "func() {\n  foo\n\n  bar\n}", b.String())
+
+	_, ok = f.reindentEdits(func(int, int) (indentation, bool) {
+		return indentation{}, false
+	}, 0, 4)
+	assert.False(t, ok)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,205 @@
+package file
+
+import (
+	"github.com/charmbracelet/lipgloss"
+
+	"go.gopad.dev/gopad/gopad/config"
+)
+
+// wrapIndicator is shown in the line number column of continuation lines.
+const wrapIndicator = "↪"
+
+// viewRow is a row on the screen showing count columns of a buffer line starting at start.
+type viewRow struct {
+	row     int
+	start   int
+	count   int
+	wrapped bool
+	last    bool
+}
+
+func (f *File) wordWrap() bool {
+	return config.Gopad.FileView.WordWrap
+}
+
+// wrapLine returns the columns the visual rows of the line start at.
+// Lines are wrapped after whitespace if possible, tabs and inlay hints count towards the width.
+func (f *File) wrapLine(row int, width int) []int {
+	starts := []int{0}
+	if width <= 0 {
+		return starts
+	}
+
+	chars := f.buffer.Line(row).RuneStrings()
+	tabSize := f.TabSize()
+	widths := make([]int, len(chars))
+
+	var (
+		lineCol   int
+		rowWidth  int
+		lastBreak int
+	)
+	for col, char := range chars {
+		w := 1
+		if char == "\t" {
+			w = tabSize - lineCol%tabSize
+		}
+		lineCol += w
+		for _, hint := range f.InlayHintsForLineCol(row, col+1) {
+			w += lipgloss.Width(hint.Label)
+			if hint.PaddingLeft {
+				w++
+			}
+			if hint.PaddingRight {
+				w++
+			}
+		}
+		widths[col] = w
+
+		start := starts[len(starts)-1]
+		if rowWidth+w > width && col > start {
+			// fall back to breaking inside the word if there is no whitespace in the row
+			start = col
+			if lastBreak > starts[len(starts)-1] {
+				start = lastBreak
+			}
+			starts = append(starts, start)
+			rowWidth = 0
+			for _, cw := range widths[start:col] {
+				rowWidth += cw
+			}
+		}
+		rowWidth += w
+
+		if char == " " || char == "\t" {
+			lastBreak = col + 1
+		}
+	}
+
+	return starts
+}
+
+// wrapIndex returns the index of the visual row containing the column.
+func wrapIndex(starts []int, col int) int {
+	for i := len(starts) - 1; i > 0; i-- {
+		if col >= starts[i] {
+			return i
+		}
+	}
+	return 0
+}
+
+// wrapRows returns the rows of the screen starting at the first line of the view.
+func (f *File) wrapRows(width int, height int) []viewRow {
+	var rows []viewRow
+	ln := f.cursor.offsetRow
+	for len(rows) < height {
+		if ln >= f.buffer.LinesLen() {
+			rows = append(rows, viewRow{row: ln, last: true})
+			ln++
+			continue
+		}
+
+		starts := f.wrapLine(ln, width)
+		lineLen := f.buffer.LineLen(ln)
+		for i, start := range starts {
+			if len(rows) == height {
+				break
+			}
+			end := lineLen + 1
+			if i < len(starts)-1 {
+				end = starts[i+1]
+			}
+			rows = append(rows, viewRow{
+				row:     ln,
+				start:   start,
+				count:   end - start,
+				wrapped: i > 0,
+				last:    i == len(starts)-1,
+			})
+		}
+		ln++
+	}
+	return rows
+}
+
+// refreshWrapViewOffset scrolls the view so the visual row of the cursor is visible.
+func (f *File) refreshWrapViewOffset(width int, height int) {
+	f.cursor.offsetCol = 0
+
+	cursorRow, cursorCol := f.Cursor()
+	if cursorRow <= f.cursor.offsetRow {
+		f.cursor.offsetRow = cursorRow
+		return
+	}
+
+	rows := wrapIndex(f.wrapLine(cursorRow, width), cursorCol) + 1
+	top := cursorRow
+	for top > f.cursor.offsetRow {
+		lineRows := len(f.wrapLine(top-1, width))
+		if rows+lineRows > height {
+			break
+		}
+		rows += lineRows
+		top--
+	}
+	f.cursor.offsetRow = top
+}
+
+// moveCursorWrapped moves the cursor by count visual rows, it returns false if the cursor is already at the first or last row.
+func (f *File) moveCursorWrapped(count int) bool {
+	row, col := f.Cursor()
+	starts := f.wrapLine(row, f.wrapWidth)
+	index := wrapIndex(starts, col)
+	x := col - starts[index]
+
+	moved := false
+	for count != 0 {
+		switch {
+		case count < 0 && index > 0:
+			index--
+		case count < 0 && row > 0:
+			row--
+			starts = f.wrapLine(row, f.wrapWidth)
+			index = len(starts) - 1
+		case count > 0 && index < len(starts)-1:
+			index++
+		case count > 0 && row < f.buffer.LinesLen()-1:
+			row++
+			starts = f.wrapLine(row, f.wrapWidth)
+			index = 0
+		default:
+			count = 0
+			continue
+		}
+		moved = true
+		if count < 0 {
+			count++
+		} else {
+			count--
+		}
+	}
+	if !moved {
+		return false
+	}
+
+	end := f.buffer.LineLen(row)
+	if index < len(starts)-1 {
+		end = starts[index+1] - 1
+	}
+	f.cursor.row = row
+	f.cursor.col = min(starts[index]+x, end)
+	return true
+}
+
+// screenPosition returns the row and column on the screen of the position in the last rendered view.
+func (f *File) screenPosition(row int, col int) (int, int, bool) {
+	for i, linePositions := range f.positions {
+		for ii := len(linePositions) - 1; ii >= 0; ii-- {
+			if linePositions[ii].row == row && linePositions[ii].col == col {
+				return i, ii, true
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,75 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func TestFile_wrapLine(t *testing.T) {
+	data := []struct {
+		name  string
+		text  string
+		width int
+		want  []int
+	}{
+		{
+			name:  "short line",
+			text:  "foo bar",
+			width: 10,
+			want:  []int{0},
+		},
+		{
+			name:  "wrap at whitespace",
+			text:  "foo bar baz",
+			width: 9,
+			want:  []int{0, 8},
+		},
+		{
+			name:  "wrap multiple rows",
+			text:  "foo bar baz qux",
+			width: 7,
+			want:  []int{0, 4, 8},
+		},
+		{
+			name:  "wrap inside long word",
+			text:  "foobarbaz",
+			width: 4,
+			want:  []int{0, 4, 8},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+			assert.NoError(t, err)
+			f := NewFileWithBuffer(b, ModeWrite)
+
+			assert.Equal(t, d.want, f.wrapLine(0, d.width))
+		})
+	}
+}
+
+func TestFile_moveCursorWrapped(t *testing.T) {
+	b, err := buffer.New("test.txt", bytes.NewReader([]byte("foo bar baz\nqux")), "utf-8", buffer.LineEndingLF, false)
+	assert.NoError(t, err)
+	f := NewFileWithBuffer(b, ModeWrite)
+	f.wrapWidth = 4
+
+	f.SetCursor(0, 1)
+	f.MoveCursorDown(1)
+	assert.Equal(t, []int{0, 5}, pair(f.Cursor()))
+	f.MoveCursorDown(1)
+	assert.Equal(t, []int{0, 9}, pair(f.Cursor()))
+	f.MoveCursorDown(1)
+	assert.Equal(t, []int{1, 1}, pair(f.Cursor()))
+	f.MoveCursorUp(2)
+	assert.Equal(t, []int{0, 5}, pair(f.Cursor()))
+}
+
+func pair(a int, b int) []int {
+	return []int{a, b}
+}
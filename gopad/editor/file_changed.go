@@ -0,0 +1,32 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+)
+
+const FileChangedOverlayID = "editor.file_changed"
+
+// NewFileChangedOverlay asks whether a file with unsaved changes should be reloaded after it changed on disk.
+func NewFileChangedOverlay(name string) ConfirmOverlay {
+	id := FileChangedOverlayID + ":" + name
+
+	bReload := config.NewButton("Reload", func() tea.Cmd {
+		return tea.Sequence(overlay.Close(id), file.ReloadFile(name))
+	})
+	bKeep := config.NewButton("Keep", func() tea.Cmd {
+		return tea.Sequence(overlay.Close(id), file.KeepFile(name))
+	})
+	bDiff := config.NewButton("Diff", func() tea.Cmd {
+		return file.DiffFile(name)
+	})
+
+	msg := fmt.Sprintf("%s changed on disk and has unsaved changes.\nDo you want to reload it?", filepath.Base(name))
+	return NewConfirmOverlay(id, "File Changed", msg, 1, bReload, bKeep, bDiff)
+}
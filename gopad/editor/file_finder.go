@@ -0,0 +1,285 @@
+package editor
+
+import (
+	"cmp"
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"go.gopad.dev/gopad/internal/bubbles/key"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/internal/bubbles/list"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/bubbles/textinput"
+	"go.gopad.dev/gopad/internal/fuzzymatch"
+)
+
+const (
+	FileFinderOverlayID = "editor.file_finder"
+
+	maxFileFinderItems = 100
+	maxRecentFiles     = 50
+
+	// recently opened files get a score boost decreasing with each older file
+	recentFileBoost     = 40
+	recentFileBoostStep = 4
+)
+
+// indexWorkspace lists all files in the workspace which are not ignored.
+func indexWorkspace(workspace string) tea.Cmd {
+	return func() tea.Msg {
+		var names []string
+		err := walkWorkspace(context.Background(), workspace, config.Gopad.FileTree.Ignored, func(name string, d fs.DirEntry) {
+			if d.IsDir() {
+				return
+			}
+			if relName, err := filepath.Rel(workspace, name); err == nil {
+				names = append(names, relName)
+			}
+		})
+		return fileFinderIndexMsg{
+			names: names,
+			err:   err,
+		}
+	}
+}
+
+type fileFinderIndexMsg struct {
+	names []string
+	err   error
+}
+
+type fileFinderMatch struct {
+	name      string
+	score     int
+	positions []int
+}
+
+type fileFinderItem struct {
+	name  string
+	title string
+}
+
+func (i fileFinderItem) Title() string {
+	return i.title
+}
+
+func (i fileFinderItem) Description() string {
+	return ""
+}
+
+var _ overlay.Overlay = (*FileFinderOverlay)(nil)
+
+// NewFileFinderOverlay creates a quick open overlay for the files in the workspace.
+// The recent files are absolute file names ordered from the most recently opened one.
+func NewFileFinderOverlay(workspace string, recentFiles []string) FileFinderOverlay {
+	l := config.NewList[fileFinderItem](nil)
+	l.SetFiltering(false)
+	l.TextInput.Placeholder = "Search files..."
+	l.Focus()
+
+	recent := make(map[string]int, len(recentFiles))
+	for i, name := range recentFiles {
+		if relName, err := filepath.Rel(workspace, name); err == nil && !strings.HasPrefix(relName, "..") {
+			recent[relName] = i
+		}
+	}
+
+	return FileFinderOverlay{
+		workspace: workspace,
+		recent:    recent,
+		indexing:  true,
+		l:         l,
+	}
+}
+
+type FileFinderOverlay struct {
+	workspace string
+	recent    map[string]int
+	names     []string
+	indexing  bool
+	query     string
+	matches   []fileFinderMatch
+	l         list.Model[fileFinderItem]
+}
+
+func (o FileFinderOverlay) ID() string {
+	return FileFinderOverlayID
+}
+
+func (o FileFinderOverlay) Position() (lipgloss.Position, lipgloss.Position) {
+	return lipgloss.Center, lipgloss.Top
+}
+
+func (o FileFinderOverlay) Margin() (int, int) {
+	return 0, 2
+}
+
+func (o FileFinderOverlay) Title() string {
+	if o.indexing {
+		return "Open File (indexing...)"
+	}
+	return "Open File"
+}
+
+func (o FileFinderOverlay) Init() (overlay.Overlay, tea.Cmd) {
+	return o, tea.Batch(
+		textinput.Blink,
+		indexWorkspace(o.workspace),
+	)
+}
+
+func (o FileFinderOverlay) recentBoost(name string) int {
+	i, ok := o.recent[name]
+	if !ok {
+		return 0
+	}
+	return max(recentFileBoost-i*recentFileBoostStep, 0)
+}
+
+// rank matches all files against the query and sorts them by score.
+func (o *FileFinderOverlay) rank() {
+	o.query = o.l.TextInput.Value()
+	o.matches = nil
+
+	for _, name := range o.names {
+		score, positions, ok := fuzzymatch.Match(o.query, name)
+		if !ok {
+			continue
+		}
+		o.matches = append(o.matches, fileFinderMatch{
+			name:      name,
+			score:     score + o.recentBoost(name),
+			positions: positions,
+		})
+	}
+
+	slices.SortStableFunc(o.matches, func(a, b fileFinderMatch) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			cmp.Compare(len(a.name), len(b.name)),
+			strings.Compare(a.name, b.name),
+		)
+	})
+	o.matches = o.matches[:min(len(o.matches), maxFileFinderItems)]
+
+	o.l.SetItems(make([]fileFinderItem, len(o.matches)))
+	o.l.Select(0)
+}
+
+// renderItems renders the matches with the matched characters highlighted.
+func (o *FileFinderOverlay) renderItems() {
+	selected := o.l.SelectedIndex()
+
+	items := make([]fileFinderItem, len(o.matches))
+	for i, match := range o.matches {
+		style := o.l.Styles.ItemStyle
+		if i == selected {
+			style = o.l.Styles.ItemSelectedStyle
+		}
+		items[i] = fileFinderItem{
+			name:  match.name,
+			title: renderFileFinderMatch(match, lipgloss.NewStyle().Inherit(style), o.l.Styles.ItemMatchStyle.Inherit(style)),
+		}
+	}
+	o.l.SetItems(items)
+}
+
+func renderFileFinderMatch(match fileFinderMatch, style lipgloss.Style, matchStyle lipgloss.Style) string {
+	var (
+		title string
+		run   []rune
+		isRun bool
+	)
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if isRun {
+			title += matchStyle.Render(string(run))
+		} else {
+			title += style.Render(string(run))
+		}
+		run = run[:0]
+	}
+
+	for i, r := range []rune(match.name) {
+		matched := slices.Contains(match.positions, i)
+		if matched != isRun {
+			flush()
+			isRun = matched
+		}
+		run = append(run, r)
+	}
+	flush()
+
+	return title
+}
+
+func (o FileFinderOverlay) open() tea.Cmd {
+	if len(o.l.Items()) == 0 {
+		return nil
+	}
+	item := o.l.Selected()
+	return tea.Batch(
+		overlay.Close(FileFinderOverlayID),
+		file.OpenFile(filepath.Join(o.workspace, item.name)),
+	)
+}
+
+func (o FileFinderOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case fileFinderIndexMsg:
+		o.indexing = false
+		if msg.err != nil {
+			cmds = append(cmds, notifications.Addf("error while indexing workspace: %s", msg.err))
+		}
+		o.names = msg.names
+		o.rank()
+		o.renderItems()
+		return o, tea.Batch(cmds...)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, config.Keys.Cancel):
+			return o, overlay.Close(FileFinderOverlayID)
+		case key.Matches(msg, config.Keys.OK):
+			return o, o.open()
+		}
+	}
+
+	var cmd tea.Cmd
+	o.l, cmd = o.l.Update(msg)
+	cmds = append(cmds, cmd)
+
+	if o.l.Clicked() {
+		return o, o.open()
+	}
+
+	if o.l.TextInput.Value() != o.query {
+		o.rank()
+	}
+	o.renderItems()
+
+	return o, tea.Batch(cmds...)
+}
+
+func (o FileFinderOverlay) View(width int, height int) string {
+	style := config.Theme.UI.Overlay.RunOverlayStyle
+	width /= 2
+	width -= style.GetHorizontalFrameSize()
+	if width > 0 {
+		o.l.SetWidth(width)
+	}
+
+	o.l.SetHeight(height - style.GetVerticalFrameSize() - 2)
+	return o.l.View()
+}
@@ -140,6 +140,133 @@ func (m *Model) Open(name string) error {
 	return nil
 }
 
+// find returns the entry of the path and its parent.
+func (m *Model) find(path string) (*Entry, *Entry) {
+	if m.entry == nil {
+		return nil, nil
+	}
+	if path == m.entry.Path {
+		return m.entry, nil
+	}
+
+	relPath, err := filepath.Rel(m.entry.Path, path)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return nil, nil
+	}
+
+	var parent *Entry
+	current := m.entry
+	for _, component := range strings.Split(relPath, string(os.PathSeparator)) {
+		i := slices.IndexFunc(current.Children, func(child *Entry) bool {
+			return child.Name == component
+		})
+		if i == -1 {
+			return nil, nil
+		}
+		parent = current
+		current = current.Children[i]
+	}
+	return current, parent
+}
+
+// Add adds an entry for the path if its parent directory is part of the tree.
+func (m *Model) Add(path string, isDir bool) {
+	m.insert(&Entry{
+		Name:  filepath.Base(path),
+		Path:  path,
+		IsDir: isDir,
+	})
+}
+
+func (m *Model) insert(entry *Entry) {
+	if existing, _ := m.find(entry.Path); existing != nil {
+		return
+	}
+	parent, _ := m.find(filepath.Dir(entry.Path))
+	if parent == nil || !parent.IsDir || slices.Contains(m.Ignored, entry.Name) {
+		return
+	}
+
+	parent.Children = append(parent.Children, entry)
+	parent.Sort()
+}
+
+// Remove removes the entry of the path and all its children.
+func (m *Model) Remove(path string) {
+	entry, parent := m.find(path)
+	if entry == nil || parent == nil {
+		return
+	}
+
+	parent.Children = slices.DeleteFunc(parent.Children, func(child *Entry) bool {
+		return child == entry
+	})
+
+	// keep a selection if the selected entry was removed
+	if m.Selected() == nil {
+		parent.Selected = true
+	}
+}
+
+// Rename moves the entry of the old path to the new path and keeps its state.
+// If there is no entry for the old path, for example because its parent directory was removed before, the new path is added.
+func (m *Model) Rename(oldPath string, newPath string, isDir bool) {
+	entry, _ := m.find(oldPath)
+	if entry == nil {
+		m.Add(newPath, isDir)
+		return
+	}
+	selected := m.Selected() == entry
+	m.Remove(oldPath)
+
+	var walk func(e *Entry, path string)
+	walk = func(e *Entry, path string) {
+		e.Path = path
+		for _, child := range e.Children {
+			walk(child, filepath.Join(path, child.Name))
+		}
+	}
+	entry.Name = filepath.Base(newPath)
+	walk(entry, newPath)
+
+	m.insert(entry)
+	if selected {
+		if s := m.Selected(); s != nil {
+			s.Selected = false
+		}
+		entry.Selected = true
+	}
+}
+
+// Expanded returns the paths of all open directories below the root.
+func (m *Model) Expanded() []string {
+	if m.entry == nil {
+		return nil
+	}
+
+	var paths []string
+	var walk func(e *Entry)
+	walk = func(e *Entry) {
+		for _, child := range e.Children {
+			if child.IsDir && child.Open {
+				paths = append(paths, child.Path)
+				walk(child)
+			}
+		}
+	}
+	walk(m.entry)
+	return paths
+}
+
+// SetExpanded opens the directories of the paths which are part of the tree.
+func (m *Model) SetExpanded(paths []string) {
+	for _, path := range paths {
+		if entry, _ := m.find(path); entry != nil && entry.IsDir {
+			entry.Open = true
+		}
+	}
+}
+
 func (m *Model) Visible() bool {
 	return m.show
 }
@@ -288,9 +415,11 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case refreshMsg:
+		expanded := m.Expanded()
 		if err := m.Open(m.entry.Path); err != nil {
 			cmds = append(cmds, notifications.Add("Error updating file tree: "+err.Error()))
 		}
+		m.SetExpanded(expanded)
 		return m, tea.Batch(cmds...)
 	case tea.MouseClickMsg:
 		for _, z := range zone.GetPrefix(zoneIDPrefix) {
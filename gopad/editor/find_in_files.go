@@ -0,0 +1,666 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lrstanley/bubblezone"
+	"go.gopad.dev/gopad/internal/bubbles/key"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/gopad/editor/searchbar"
+	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/internal/bubbles/groupedlist"
+	"go.gopad.dev/gopad/internal/bubbles/mouse"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/bubbles/textinput"
+	"go.gopad.dev/gopad/internal/gitignore"
+)
+
+const (
+	FindInFilesOverlayID = "editor.find_in_files"
+
+	ZoneFindInFilesRegex     = "editor.find_in_files.regex"
+	ZoneFindInFilesCase      = "editor.find_in_files.case"
+	ZoneFindInFilesWholeWord = "editor.find_in_files.whole-word"
+	ZoneFindInFilesReplace   = "editor.find_in_files.replace"
+
+	// binaryCheckSize is the number of bytes checked for a NUL byte to detect binary files.
+	binaryCheckSize = 8000
+)
+
+type findInFilesMatch struct {
+	Result searchbar.Result
+	Line   string
+}
+
+type findInFilesResult struct {
+	Name    string
+	Matches []findInFilesMatch
+}
+
+type findInFilesResultMsg struct {
+	id     int
+	result findInFilesResult
+}
+
+type findInFilesDoneMsg struct {
+	id  int
+	err error
+}
+
+type findInFilesPreviewMsg struct {
+	id    int
+	edits []ls.FileEdit
+	items []groupedlist.Item
+}
+
+// findInFilesSearch streams the results of a running search in the workspace.
+type findInFilesSearch struct {
+	id      int
+	cancel  context.CancelFunc
+	results chan findInFilesResult
+	err     error
+}
+
+// next waits for the next result of the search.
+func (s *findInFilesSearch) next() tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-s.results
+		if !ok {
+			return findInFilesDoneMsg{
+				id:  s.id,
+				err: s.err,
+			}
+		}
+		return findInFilesResultMsg{
+			id:     s.id,
+			result: result,
+		}
+	}
+}
+
+// startFindInFiles searches all files in the workspace concurrently.
+// Open buffers are searched instead of their files on disk.
+func startFindInFiles(id int, workspace string, re *regexp.Regexp, buffers map[string]*buffer.Buffer) *findInFilesSearch {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &findInFilesSearch{
+		id:      id,
+		cancel:  cancel,
+		results: make(chan findInFilesResult),
+	}
+
+	go func() {
+		names := make(chan string)
+
+		var wg sync.WaitGroup
+		for range runtime.NumCPU() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for name := range names {
+					b, err := findInFilesBuffer(name, buffers)
+					if err != nil || b == nil {
+						continue
+					}
+					result := findInFilesResult{
+						Name: name,
+					}
+					for _, r := range searchbar.Find(re, b) {
+						result.Matches = append(result.Matches, findInFilesMatch{
+							Result: r,
+							Line:   b.Line(r.RowStart).String(),
+						})
+					}
+					if len(result.Matches) == 0 {
+						continue
+					}
+
+					select {
+					case s.results <- result:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		err := walkWorkspace(ctx, workspace, config.Gopad.FileTree.Ignored, func(name string, d fs.DirEntry) {
+			if d.IsDir() {
+				return
+			}
+			select {
+			case names <- name:
+			case <-ctx.Done():
+			}
+		})
+		close(names)
+		wg.Wait()
+
+		if err != nil && !errors.Is(err, context.Canceled) {
+			s.err = err
+		}
+		close(s.results)
+	}()
+
+	return s
+}
+
+// findInFilesBuffer returns the open buffer of the file or reads it from disk.
+// Binary files return a nil buffer.
+func findInFilesBuffer(name string, buffers map[string]*buffer.Buffer) (*buffer.Buffer, error) {
+	if b, ok := buffers[name]; ok {
+		return b, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), binaryCheckSize)], 0) > -1 {
+		return nil, nil
+	}
+
+	return buffer.New(name, bytes.NewReader(data), "UTF-8", buffer.LineEndingAuto, true)
+}
+
+// walkWorkspace calls fn for every directory and regular file in the workspace which is not ignored by the file tree config or a .gitignore file.
+func walkWorkspace(ctx context.Context, workspace string, ignored []string, fn func(name string, d fs.DirEntry)) error {
+	var matcher gitignore.Matcher
+	return filepath.WalkDir(workspace, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// skip unreadable files and directories
+			if name == workspace {
+				return err
+			}
+			return nil
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		relName, err := filepath.Rel(workspace, name)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		relName = filepath.ToSlash(relName)
+
+		if relName != "." && (slices.Contains(ignored, d.Name()) || matcher.Match(relName, d.IsDir())) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if relName == "." {
+				relName = ""
+			} else {
+				fn(name, d)
+			}
+			if err = matcher.ParseFile(relName, name); err != nil {
+				return fmt.Errorf("error reading %s: %w", gitignore.FileName, err)
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			fn(name, d)
+		}
+		return nil
+	})
+}
+
+// replaceInFilesPreview calculates the edits replacing all matches in the files.
+func replaceInFilesPreview(id int, workspace string, re *regexp.Regexp, options searchbar.Options, replacement string, names []string, buffers map[string]*buffer.Buffer) tea.Cmd {
+	return func() tea.Msg {
+		msg := findInFilesPreviewMsg{
+			id: id,
+		}
+		for _, name := range names {
+			b, err := findInFilesBuffer(name, buffers)
+			if err != nil || b == nil {
+				continue
+			}
+
+			edits := searchbar.ReplaceEdits(re, options, replacement, b, nil)
+			if len(edits) == 0 {
+				continue
+			}
+			msg.edits = append(msg.edits, ls.FileEdit{
+				Name:  name,
+				Edits: edits,
+			})
+
+			items := make([]groupedlist.Item, len(edits))
+			for i, edit := range edits {
+				items[i] = findInFilesItem{
+					name:     name,
+					position: edit.Range.Start,
+					title:    replacePreview(b, edit),
+				}
+			}
+			msg.items = append(msg.items, findInFilesFileItem{
+				name:  name,
+				title: fmt.Sprintf("%s (%d changes)", relativeName(workspace, name), len(edits)),
+				items: items,
+			})
+		}
+		return msg
+	}
+}
+
+// replacePreview renders the line of the edit before and after the edit is applied.
+func replacePreview(b *buffer.Buffer, edit ls.TextEdit) string {
+	start := edit.Range.Start
+	end := edit.Range.End
+
+	line := []rune(b.Line(start.Row).String())
+	endLine := []rune(b.Line(end.Row).String())
+
+	after := string(line[:start.Col]) + edit.NewText + string(endLine[end.Col:])
+	after = strings.ReplaceAll(after, "\n", "↵")
+
+	return fmt.Sprintf("%d: %s → %s", start.Row+1, strings.TrimSpace(string(line)), strings.TrimSpace(after))
+}
+
+func relativeName(workspace string, name string) string {
+	if relName, err := filepath.Rel(workspace, name); err == nil {
+		return relName
+	}
+	return name
+}
+
+type findInFilesFileItem struct {
+	name  string
+	title string
+	items []groupedlist.Item
+}
+
+func (i findInFilesFileItem) Title() string {
+	return i.title
+}
+
+func (i findInFilesFileItem) Items() []groupedlist.Item {
+	return i.items
+}
+
+type findInFilesItem struct {
+	name     string
+	position buffer.Position
+	title    string
+}
+
+func (i findInFilesItem) Title() string {
+	return i.title
+}
+
+func (i findInFilesItem) Items() []groupedlist.Item {
+	return nil
+}
+
+var _ overlay.Overlay = (*FindInFilesOverlay)(nil)
+
+func NewFindInFilesOverlay(workspace string, buffers map[string]*buffer.Buffer) FindInFilesOverlay {
+	l := config.NewGroupedList(nil)
+	l.SetFilter(nil)
+	l.TextInput.Placeholder = "Find in files..."
+	l.Focus()
+
+	replaceInput := config.NewTextInput()
+	replaceInput.Placeholder = "Replace"
+
+	return FindInFilesOverlay{
+		workspace:    workspace,
+		buffers:      buffers,
+		l:            l,
+		replaceInput: replaceInput,
+	}
+}
+
+type FindInFilesOverlay struct {
+	workspace    string
+	buffers      map[string]*buffer.Buffer
+	l            groupedlist.Model
+	replaceInput textinput.Model
+	showReplace  bool
+	focusReplace bool
+	options      searchbar.Options
+
+	id      int
+	term    string
+	search  *findInFilesSearch
+	results []findInFilesResult
+	err     error
+
+	// preview contains the edits of a replace waiting for confirmation
+	preview []ls.FileEdit
+}
+
+func (o FindInFilesOverlay) ID() string {
+	return FindInFilesOverlayID
+}
+
+func (o FindInFilesOverlay) Position() (lipgloss.Position, lipgloss.Position) {
+	return lipgloss.Center, lipgloss.Top
+}
+
+func (o FindInFilesOverlay) Margin() (int, int) {
+	return 0, 2
+}
+
+func (o FindInFilesOverlay) Title() string {
+	if o.preview != nil {
+		return "Replace in Files"
+	}
+	return "Find in Files"
+}
+
+func (o FindInFilesOverlay) Init() (overlay.Overlay, tea.Cmd) {
+	return o, textinput.Blink
+}
+
+func (o *FindInFilesOverlay) stop() {
+	if o.search != nil {
+		o.search.cancel()
+		o.search = nil
+	}
+}
+
+// find restarts the search with the current term and options.
+func (o *FindInFilesOverlay) find() tea.Cmd {
+	o.stop()
+	o.id++
+	o.term = o.l.TextInput.Value()
+	o.results = nil
+	o.preview = nil
+	o.err = nil
+	o.l.SetItems(nil)
+
+	if o.term == "" {
+		return nil
+	}
+
+	re, err := searchbar.Pattern(o.term, o.options)
+	if err != nil {
+		o.err = err
+		return nil
+	}
+
+	o.search = startFindInFiles(o.id, o.workspace, re, o.buffers)
+	return o.search.next()
+}
+
+func (o *FindInFilesOverlay) refreshItems() {
+	items := make([]groupedlist.Item, len(o.results))
+	for i, result := range o.results {
+		matches := make([]groupedlist.Item, len(result.Matches))
+		for j, match := range result.Matches {
+			matches[j] = findInFilesItem{
+				name:     result.Name,
+				position: match.Result.Range().Start,
+				title:    fmt.Sprintf("%d: %s", match.Result.RowStart+1, strings.TrimSpace(match.Line)),
+			}
+		}
+		items[i] = findInFilesFileItem{
+			name:  result.Name,
+			title: fmt.Sprintf("%s (%d)", relativeName(o.workspace, result.Name), len(result.Matches)),
+			items: matches,
+		}
+	}
+	o.l.SetItems(items)
+}
+
+func (o *FindInFilesOverlay) replacePreview() tea.Cmd {
+	if len(o.results) == 0 || o.err != nil {
+		return nil
+	}
+
+	re, err := searchbar.Pattern(o.term, o.options)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, len(o.results))
+	for i, result := range o.results {
+		names[i] = result.Name
+	}
+	return replaceInFilesPreview(o.id, o.workspace, re, o.options, o.replaceInput.Value(), names, o.buffers)
+}
+
+func (o *FindInFilesOverlay) cancelPreview() {
+	o.preview = nil
+	o.refreshItems()
+}
+
+func (o *FindInFilesOverlay) toggleReplace() tea.Cmd {
+	o.showReplace = !o.showReplace
+	return o.switchInput(o.showReplace)
+}
+
+func (o *FindInFilesOverlay) switchInput(replace bool) tea.Cmd {
+	o.focusReplace = replace && o.showReplace
+	o.l.Blur()
+	o.replaceInput.Blur()
+	if o.focusReplace {
+		return o.replaceInput.Focus()
+	}
+	return o.l.Focus()
+}
+
+func (o *FindInFilesOverlay) close() tea.Cmd {
+	o.stop()
+	return overlay.Close(FindInFilesOverlayID)
+}
+
+func (o FindInFilesOverlay) open() tea.Cmd {
+	var (
+		name     string
+		position buffer.Position
+	)
+	switch item := o.l.SelectedItem().(type) {
+	case findInFilesItem:
+		name = item.name
+		position = item.position
+	case findInFilesFileItem:
+		name = item.name
+		if len(item.items) > 0 {
+			position = item.items[0].(findInFilesItem).position
+		}
+	default:
+		return nil
+	}
+
+	return tea.Batch(
+		o.close(),
+		file.OpenFilePosition(name, &position),
+	)
+}
+
+func (o FindInFilesOverlay) apply() tea.Cmd {
+	var changes int
+	for _, edit := range o.preview {
+		changes += len(edit.Edits)
+	}
+
+	return tea.Sequence(
+		o.close(),
+		ls.ApplyWorkspaceEdit(ls.WorkspaceEdit{
+			Label: "Replace in files",
+			Files: o.preview,
+		}, nil),
+		notifications.Addf("replaced %d occurrences in %d files", changes, len(o.preview)),
+	)
+}
+
+func (o FindInFilesOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case findInFilesResultMsg:
+		if o.search == nil || msg.id != o.id {
+			return o, nil
+		}
+		index, _ := slices.BinarySearchFunc(o.results, msg.result.Name, func(r findInFilesResult, name string) int {
+			return strings.Compare(r.Name, name)
+		})
+		o.results = slices.Insert(o.results, index, msg.result)
+		if o.preview == nil {
+			o.refreshItems()
+		}
+		return o, o.search.next()
+	case findInFilesDoneMsg:
+		if msg.id != o.id {
+			return o, nil
+		}
+		o.search = nil
+		if msg.err != nil {
+			o.err = msg.err
+		}
+		return o, nil
+	case findInFilesPreviewMsg:
+		if msg.id != o.id || len(msg.edits) == 0 {
+			return o, nil
+		}
+		o.stop()
+		o.preview = msg.edits
+		o.l.SetItems(msg.items)
+		return o, nil
+	case tea.MouseClickMsg:
+		switch {
+		case mouse.Matches(msg, ZoneFindInFilesRegex, tea.MouseLeft):
+			o.options.Regex = !o.options.Regex
+			return o, o.find()
+		case mouse.Matches(msg, ZoneFindInFilesCase, tea.MouseLeft):
+			o.options.CaseSensitive = !o.options.CaseSensitive
+			return o, o.find()
+		case mouse.Matches(msg, ZoneFindInFilesWholeWord, tea.MouseLeft):
+			o.options.WholeWord = !o.options.WholeWord
+			return o, o.find()
+		case mouse.Matches(msg, ZoneFindInFilesReplace, tea.MouseLeft):
+			return o, o.toggleReplace()
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, config.Keys.Cancel):
+			if o.preview != nil {
+				o.cancelPreview()
+				return o, nil
+			}
+			return o, o.close()
+		case key.Matches(msg, config.Keys.OK):
+			if o.preview != nil {
+				return o, o.apply()
+			}
+			return o, o.open()
+		case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleRegex):
+			o.options.Regex = !o.options.Regex
+			return o, o.find()
+		case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleCaseSensitive):
+			o.options.CaseSensitive = !o.options.CaseSensitive
+			return o, o.find()
+		case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleWholeWord):
+			o.options.WholeWord = !o.options.WholeWord
+			return o, o.find()
+		case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleReplace):
+			return o, o.toggleReplace()
+		case key.Matches(msg, config.Keys.Editor.SearchBar.SwitchInput) && o.showReplace:
+			return o, o.switchInput(!o.focusReplace)
+		case key.Matches(msg, config.Keys.Editor.SearchBar.ReplaceAll) && o.showReplace:
+			return o, o.replacePreview()
+		}
+	}
+
+	var cmd tea.Cmd
+	if o.focusReplace {
+		o.replaceInput, cmd = o.replaceInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	o.l, cmd = o.l.Update(msg)
+	cmds = append(cmds, cmd)
+
+	if o.l.Clicked() {
+		if o.preview != nil {
+			return o, nil
+		}
+		return o, o.open()
+	}
+
+	if o.l.TextInput.Value() != o.term {
+		cmds = append(cmds, o.find())
+	}
+
+	return o, tea.Batch(cmds...)
+}
+
+func (o FindInFilesOverlay) optionView(zoneID string, label string, enabled bool) string {
+	style := config.Theme.UI.SearchBar.OptionStyle
+	if enabled {
+		style = config.Theme.UI.SearchBar.SelectedOptionStyle
+	}
+	return zone.Mark(zoneID, style.Render(label))
+}
+
+func (o FindInFilesOverlay) statusView() string {
+	if o.err != nil {
+		return o.err.Error()
+	}
+	if o.preview != nil {
+		return "enter to apply, esc to go back"
+	}
+
+	var matches int
+	for _, result := range o.results {
+		matches += len(result.Matches)
+	}
+	status := fmt.Sprintf("%d results in %d files", matches, len(o.results))
+	if o.search != nil {
+		status += " (searching...)"
+	}
+	return status
+}
+
+func (o FindInFilesOverlay) View(width int, height int) string {
+	style := config.Theme.UI.Overlay.RunOverlayStyle
+	width /= 2
+	width -= style.GetHorizontalFrameSize()
+
+	header := []string{
+		lipgloss.JoinHorizontal(lipgloss.Center,
+			o.optionView(ZoneFindInFilesRegex, ".*", o.options.Regex),
+			o.optionView(ZoneFindInFilesCase, "Aa", o.options.CaseSensitive),
+			o.optionView(ZoneFindInFilesWholeWord, "ab", o.options.WholeWord),
+			o.optionView(ZoneFindInFilesReplace, "⇄", o.showReplace),
+			config.Theme.UI.SearchBar.ResultStyle.Render(" "+o.statusView()),
+		),
+	}
+	if o.showReplace {
+		if width > 0 {
+			o.replaceInput.Width = width - 2
+		}
+		header = append(header, o.replaceInput.View())
+	}
+
+	if width > 0 {
+		o.l.SetWidth(width)
+	}
+	o.l.SetHeight(height - style.GetVerticalFrameSize() - 2 - len(header))
+
+	return lipgloss.JoinVertical(lipgloss.Left, append(header, o.l.View())...)
+}
@@ -0,0 +1,199 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"go.gopad.dev/gopad/internal/bubbles/key"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/internal/bubbles/list"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/bubbles/textinput"
+)
+
+type location struct {
+	Name  string
+	Range buffer.Range
+}
+
+type locationItem struct {
+	location
+	title   string
+	snippet string
+}
+
+func (l locationItem) Title() string {
+	return l.title
+}
+
+func (l locationItem) Description() string {
+	return l.snippet
+}
+
+func (l locationItem) FilterValue() string {
+	return l.title + " " + l.snippet
+}
+
+// showLocations opens the locations in an overlay or notifies that there are none.
+// If jump is set, a single location is opened directly.
+// The items are built in a command because files which are not open are read from disk.
+func (e Editor) showLocations(title string, notFound string, locations []location, jump bool) tea.Cmd {
+	if len(locations) == 0 {
+		return notifications.Add(notFound)
+	}
+	if jump && len(locations) == 1 {
+		return file.OpenFilePosition(locations[0].Name, &locations[0].Range.Start)
+	}
+
+	buffers := make(map[string]*buffer.Buffer)
+	for _, l := range locations {
+		if _, ok := buffers[l.Name]; ok {
+			continue
+		}
+		if f := e.FileByName(l.Name); f != nil {
+			buffers[l.Name] = f.Buffer().Copy()
+		}
+	}
+	workspace := e.workspace
+	return func() tea.Msg {
+		return overlay.Open(NewLocationsOverlay(title, locationItems(workspace, buffers, locations)))()
+	}
+}
+
+// locationItems resolves the source line of each location from the buffers of the open files or from disk.
+func locationItems(workspace string, buffers map[string]*buffer.Buffer, locations []location) []locationItem {
+	fileLines := make(map[string][][]byte)
+
+	items := make([]locationItem, 0, len(locations))
+	for _, l := range locations {
+		var snippet string
+		if b, ok := buffers[l.Name]; ok {
+			if l.Range.Start.Row < b.LinesLen() {
+				snippet = b.Line(l.Range.Start.Row).String()
+			}
+		} else {
+			lines, ok := fileLines[l.Name]
+			if !ok {
+				data, err := os.ReadFile(l.Name)
+				if err == nil {
+					lines = bytes.Split(data, []byte("\n"))
+				}
+				fileLines[l.Name] = lines
+			}
+			if l.Range.Start.Row < len(lines) {
+				snippet = string(lines[l.Range.Start.Row])
+			}
+		}
+
+		name := l.Name
+		if workspace != "" {
+			if relName, err := filepath.Rel(workspace, l.Name); err == nil {
+				name = relName
+			}
+		}
+
+		items = append(items, locationItem{
+			location: l,
+			title:    fmt.Sprintf("%s:%d:%d", name, l.Range.Start.Row+1, l.Range.Start.Col+1),
+			snippet:  strings.TrimSpace(snippet),
+		})
+	}
+
+	return items
+}
+
+const LocationsOverlayID = "editor.locations"
+
+var _ overlay.Overlay = (*LocationsOverlay)(nil)
+
+func NewLocationsOverlay(title string, items []locationItem) LocationsOverlay {
+	l := config.NewList(items)
+	l.TextInput.Placeholder = "Search locations..."
+	l.Focus()
+
+	return LocationsOverlay{
+		title: title,
+		l:     l,
+	}
+}
+
+type LocationsOverlay struct {
+	title string
+	l     list.Model[locationItem]
+}
+
+func (o LocationsOverlay) ID() string {
+	return LocationsOverlayID
+}
+
+func (o LocationsOverlay) Position() (lipgloss.Position, lipgloss.Position) {
+	return lipgloss.Center, lipgloss.Top
+}
+
+func (o LocationsOverlay) Margin() (int, int) {
+	return 0, 2
+}
+
+func (o LocationsOverlay) Title() string {
+	return o.title
+}
+
+func (o LocationsOverlay) Init() (overlay.Overlay, tea.Cmd) {
+	return o, textinput.Blink
+}
+
+func (o LocationsOverlay) open() tea.Cmd {
+	item := o.l.Selected()
+	return tea.Batch(
+		overlay.Close(LocationsOverlayID),
+		file.OpenFilePosition(item.Name, &buffer.Position{
+			Row: item.Range.Start.Row,
+			Col: item.Range.Start.Col,
+		}),
+	)
+}
+
+func (o LocationsOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, config.Keys.Cancel):
+			return o, overlay.Close(LocationsOverlayID)
+		case key.Matches(msg, config.Keys.OK):
+			if len(o.l.Items()) == 0 {
+				return o, nil
+			}
+			return o, o.open()
+		}
+	}
+
+	var cmd tea.Cmd
+	o.l, cmd = o.l.Update(msg)
+
+	if o.l.Clicked() {
+		return o, o.open()
+	}
+
+	return o, cmd
+}
+
+func (o LocationsOverlay) View(width int, height int) string {
+	style := config.Theme.UI.Overlay.RunOverlayStyle
+	width /= 2
+	width -= style.GetHorizontalFrameSize()
+	if width > 0 {
+		o.l.SetWidth(width)
+	}
+
+	o.l.SetHeight(height - style.GetVerticalFrameSize() - 2)
+	return o.l.View()
+}
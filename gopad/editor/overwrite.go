@@ -0,0 +1,32 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+)
+
+const OverwriteOverlayID = "editor.overwrite"
+
+// NewOverwriteOverlay asks whether a file which changed on disk since it was loaded should be overwritten on save.
+func NewOverwriteOverlay(name string) ConfirmOverlay {
+	id := OverwriteOverlayID + ":" + name
+
+	bOverwrite := config.NewButton("Overwrite", func() tea.Cmd {
+		return tea.Sequence(overlay.Close(id), file.OverwriteFile(name))
+	})
+	bCancel := config.NewButton("Cancel", func() tea.Cmd {
+		return overlay.Close(id)
+	})
+	bDiff := config.NewButton("Diff", func() tea.Cmd {
+		return file.DiffFile(name)
+	})
+
+	msg := fmt.Sprintf("%s changed on disk since it was opened.\nDo you want to overwrite it?", filepath.Base(name))
+	return NewConfirmOverlay(id, "Overwrite File", msg, 1, bOverwrite, bCancel, bDiff)
+}
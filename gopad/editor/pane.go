@@ -0,0 +1,345 @@
+package editor
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lrstanley/bubblezone"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/editormsg"
+	"go.gopad.dev/gopad/gopad/editor/file"
+)
+
+const (
+	minPaneRatio   = 0.1
+	maxPaneRatio   = 0.9
+	paneResizeStep = 0.05
+)
+
+// zoneMarkerRe matches the zone markers of bubblezone, they are removed from inactive panes so mouse events only reach the active pane.
+var zoneMarkerRe = regexp.MustCompile("\x1b\\[\\d+z")
+
+func newPane() *pane {
+	return &pane{
+		states: map[*file.File]file.CursorState{},
+	}
+}
+
+// pane is a node of the split layout.
+// Leaf panes show their own list of files while split panes divide their space between two children.
+type pane struct {
+	parent *pane
+
+	children [2]*pane
+	// vertical splits place the children side by side
+	vertical bool
+	// ratio is the share of the first child
+	ratio float64
+
+	files      []*file.File
+	activeFile int
+	fileOffset int
+	// states holds the cursors of the files in this pane while they are not edited in it
+	states map[*file.File]file.CursorState
+}
+
+func (p *pane) leaf() bool {
+	return p.children[0] == nil
+}
+
+func (p *pane) file() *file.File {
+	if len(p.files) == 0 {
+		return nil
+	}
+	return p.files[p.activeFile]
+}
+
+// leaves returns all leaf panes from left to right and top to bottom.
+func (p *pane) leaves() []*pane {
+	if p.leaf() {
+		return []*pane{p}
+	}
+	return append(p.children[0].leaves(), p.children[1].leaves()...)
+}
+
+// remove removes the file from the pane and keeps the active file if possible.
+func (p *pane) remove(f *file.File) {
+	index := slices.Index(p.files, f)
+	if index == -1 {
+		return
+	}
+	p.files = slices.Delete(p.files, index, index+1)
+	delete(p.states, f)
+	if index < p.activeFile {
+		p.activeFile--
+	}
+	p.activeFile = max(0, min(p.activeFile, len(p.files)-1))
+	p.fileOffset = min(p.fileOffset, p.activeFile)
+}
+
+// walk calls fn for every leaf pane with its size and whether it has a left border.
+func (p *pane) walk(width int, height int, border bool, fn func(p *pane, width int, height int, border bool)) {
+	if p.leaf() {
+		fn(p, width, height, border)
+		return
+	}
+
+	if p.vertical {
+		w := int(float64(width) * p.ratio)
+		p.children[0].walk(w, height, border, fn)
+		p.children[1].walk(width-w, height, true, fn)
+		return
+	}
+
+	// the second child is separated by a border line
+	h := int(float64(height) * p.ratio)
+	p.children[0].walk(width, h, border, fn)
+	p.children[1].walk(width, max(height-h-1, 0), border, fn)
+}
+
+// join joins the rendered leaf panes according to the layout.
+func (p *pane) join(views map[*pane]string) string {
+	if p.leaf() {
+		return views[p]
+	}
+
+	if p.vertical {
+		return lipgloss.JoinHorizontal(lipgloss.Top, p.children[0].join(views), p.children[1].join(views))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		p.children[0].join(views),
+		config.Theme.UI.FileView.SplitBorderStyle.Render(p.children[1].join(views)),
+	)
+}
+
+// showing reports whether the file is shown in any pane except the given one.
+func (e *Editor) showing(f *file.File, except *pane) bool {
+	for _, p := range e.layout.leaves() {
+		if p != except && slices.Contains(p.files, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveCursor stores the cursors of the active file in the active pane.
+func (e *Editor) saveCursor() {
+	if f := e.File(); f != nil {
+		f.Blur()
+		e.pane.states[f] = f.CursorState()
+	}
+}
+
+// loadCursor restores the cursors the active pane has for its active file.
+func (e *Editor) loadCursor() {
+	if f := e.File(); f != nil {
+		if s, ok := e.pane.states[f]; ok {
+			f.SetCursorState(s)
+		}
+	}
+}
+
+// addFile adds the file to the active pane if it is not shown in it yet.
+func (e *Editor) addFile(f *file.File) {
+	if slices.Contains(e.pane.files, f) {
+		return
+	}
+	// the new tab starts at the cursors the file currently has
+	e.pane.states[f] = f.CursorState()
+	e.pane.files = append(e.pane.files, f)
+}
+
+// removeFile removes the file from all panes.
+func (e *Editor) removeFile(f *file.File) {
+	e.saveCursor()
+	for _, p := range e.layout.leaves() {
+		p.remove(f)
+	}
+	e.loadCursor()
+}
+
+// closeTab removes the file from the active pane only.
+func (e *Editor) closeTab(f *file.File) tea.Cmd {
+	e.saveCursor()
+	e.pane.remove(f)
+	e.loadCursor()
+	return editormsg.Focus(editormsg.ModelFile)
+}
+
+// setPane makes the pane the active one and restores its cursors.
+func (e *Editor) setPane(p *pane) tea.Cmd {
+	if p == e.pane {
+		return nil
+	}
+	e.saveCursor()
+	e.pane = p
+	e.loadCursor()
+	return editormsg.Focus(editormsg.ModelFile)
+}
+
+// splitPane splits the active pane and opens the active file in the new pane.
+func (e *Editor) splitPane(vertical bool) tea.Cmd {
+	e.saveCursor()
+
+	old := e.pane
+	p := newPane()
+	if f := old.file(); f != nil {
+		p.files = []*file.File{f}
+		p.states[f] = old.states[f]
+	}
+
+	split := &pane{
+		parent:   old.parent,
+		children: [2]*pane{old, p},
+		vertical: vertical,
+		ratio:    0.5,
+	}
+	e.replacePane(old, split)
+	old.parent = split
+	p.parent = split
+
+	e.pane = p
+	e.loadCursor()
+	return editormsg.Focus(editormsg.ModelFile)
+}
+
+// closePane closes the active pane, files which are not shown in any other pane are moved to the neighbouring pane.
+func (e *Editor) closePane() tea.Cmd {
+	old := e.pane
+	split := old.parent
+	if split == nil {
+		return nil
+	}
+	e.saveCursor()
+
+	sibling := split.children[0]
+	if sibling == old {
+		sibling = split.children[1]
+	}
+	target := sibling.leaves()[0]
+	for _, f := range old.files {
+		if e.showing(f, old) {
+			continue
+		}
+		target.files = append(target.files, f)
+		target.states[f] = old.states[f]
+	}
+
+	sibling.parent = split.parent
+	e.replacePane(split, sibling)
+
+	e.pane = target
+	e.loadCursor()
+	return editormsg.Focus(editormsg.ModelFile)
+}
+
+func (e *Editor) replacePane(old *pane, p *pane) {
+	if old.parent == nil {
+		e.layout = p
+		return
+	}
+	if old.parent.children[0] == old {
+		old.parent.children[0] = p
+	} else {
+		old.parent.children[1] = p
+	}
+}
+
+// resizePane grows or shrinks the active pane within its parent split.
+func (e *Editor) resizePane(delta float64) {
+	split := e.pane.parent
+	if split == nil {
+		return
+	}
+	if split.children[1] == e.pane {
+		delta = -delta
+	}
+	split.ratio = max(minPaneRatio, min(split.ratio+delta, maxPaneRatio))
+}
+
+// focusPane moves the focus by the given number of panes.
+func (e *Editor) focusPane(step int) tea.Cmd {
+	leaves := e.layout.leaves()
+	index := slices.Index(leaves, e.pane)
+	return e.setPane(leaves[(index+step+len(leaves))%len(leaves)])
+}
+
+// panesView renders all panes, inactive panes are rendered first so the active pane has the last word on the shared file state.
+func (e *Editor) panesView(width int, height int, border bool) string {
+	type paneSize struct {
+		p      *pane
+		width  int
+		height int
+		border bool
+	}
+	var sizes []paneSize
+	e.layout.walk(width, height, border, func(p *pane, width int, height int, border bool) {
+		sizes = append(sizes, paneSize{p: p, width: width, height: height, border: border})
+	})
+
+	views := make(map[*pane]string, len(sizes))
+	var active paneSize
+	for i, s := range sizes {
+		if s.p == e.pane {
+			active = s
+			continue
+		}
+		views[s.p] = zone.Mark(fmt.Sprintf("%s%d", ZonePanePrefix, i), zoneMarkerRe.ReplaceAllString(e.inactivePaneView(s.p, s.width, s.height, s.border), ""))
+	}
+	views[e.pane] = e.paneView(active.p, active.width, active.height, active.border)
+
+	return e.layout.join(views)
+}
+
+func (e *Editor) paneView(p *pane, width int, height int, border bool) string {
+	f := p.file()
+	if f == nil {
+		styles := config.Theme.UI.FileView
+		if border {
+			width -= styles.BorderStyle.GetHorizontalFrameSize()
+		}
+		view := styles.EmptyStyle.
+			Width(max(width-styles.EmptyStyle.GetHorizontalBorderSize(), 0)).
+			Height(max(height-styles.EmptyStyle.GetVerticalBorderSize(), 0)).
+			Render("No file open.")
+		if border {
+			view = styles.BorderStyle.Render(view)
+		}
+		return view
+	}
+
+	return f.View(width, height, border, e.treeSitterDebug)
+}
+
+// RefreshHighlights highlights the lines the panes show, height is the most lines a pane can show.
+func (e *Editor) RefreshHighlights(height int) {
+	for _, p := range e.layout.leaves() {
+		f := p.file()
+		if f == nil {
+			continue
+		}
+		if s, ok := p.states[f]; ok && p != e.pane {
+			f.RefreshHighlightsFor(s, height)
+			continue
+		}
+		f.RefreshHighlights(height)
+	}
+}
+
+// inactivePaneView renders the pane with its own cursors, the cursors of the file are not changed.
+func (e *Editor) inactivePaneView(p *pane, width int, height int, border bool) string {
+	f := p.file()
+	if f == nil {
+		return e.paneView(p, width, height, border)
+	}
+
+	s, ok := p.states[f]
+	if !ok {
+		s = f.CursorState()
+	}
+	return f.ViewWithCursorState(s, width, height, border)
+}
@@ -0,0 +1,45 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/gopad/recovery"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+)
+
+const RecoveryOverlayID = "editor.recovery"
+
+// NewRecoveryOverlay offers to restore the unsaved changes of a file found in a recovery file.
+func NewRecoveryOverlay(r recovery.File) ConfirmOverlay {
+	id := RecoveryOverlayID + ":" + r.Name
+
+	bRestore := config.NewButton("Restore", func() tea.Cmd {
+		return tea.Sequence(overlay.Close(id), file.RecoverFile(r.Name))
+	})
+	bDiff := config.NewButton("Diff", func() tea.Cmd {
+		return func() tea.Msg {
+			lines, err := r.Diff()
+			if err != nil {
+				return notifications.Addf("error while comparing file %s: %s", r.Name, err)()
+			}
+			return overlay.Open(NewDiffOverlay(r.Name, lines))()
+		}
+	})
+	bDiscard := config.NewButton("Discard", func() tea.Cmd {
+		return tea.Sequence(overlay.Close(id), func() tea.Msg {
+			if err := recovery.Remove(r.Name); err != nil {
+				return notifications.Addf("error while discarding recovery of file %s: %s", r.Name, err)()
+			}
+			return nil
+		})
+	})
+
+	msg := fmt.Sprintf("Unsaved changes of %s from %s were recovered.\nDo you want to restore them?", filepath.Base(r.Name), r.Time.Format("2006-01-02 15:04:05"))
+	return NewConfirmOverlay(id, "Recover File", msg, 0, bRestore, bDiff, bDiscard)
+}
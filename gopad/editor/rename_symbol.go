@@ -0,0 +1,88 @@
+package editor
+
+import (
+	"github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"go.gopad.dev/gopad/internal/bubbles/key"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/bubbles/textinput"
+)
+
+const RenameSymbolOverlayID = "editor.rename_symbol"
+
+var _ overlay.Overlay = (*RenameSymbolOverlay)(nil)
+
+func NewRenameSymbolOverlay(name string, row int, col int, symbol string) RenameSymbolOverlay {
+	ti := config.NewTextInput()
+	ti.Placeholder = "New symbol name"
+	ti.SetValue(symbol)
+	ti.Focus()
+
+	return RenameSymbolOverlay{
+		name:    name,
+		row:     row,
+		col:     col,
+		newName: ti,
+	}
+}
+
+type RenameSymbolOverlay struct {
+	name    string
+	row     int
+	col     int
+	newName textinput.Model
+}
+
+func (r RenameSymbolOverlay) ID() string {
+	return RenameSymbolOverlayID
+}
+
+func (r RenameSymbolOverlay) Position() (lipgloss.Position, lipgloss.Position) {
+	return lipgloss.Center, lipgloss.Center
+}
+
+func (r RenameSymbolOverlay) Margin() (int, int) {
+	return 0, 0
+}
+
+func (r RenameSymbolOverlay) Title() string {
+	return "Rename Symbol"
+}
+
+func (r RenameSymbolOverlay) Init() (overlay.Overlay, tea.Cmd) {
+	return r, textinput.Blink
+}
+
+func (r RenameSymbolOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
+	var cmds []tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, config.Keys.OK):
+			if r.newName.Value() == "" {
+				return r, nil
+			}
+			return r, tea.Sequence(
+				overlay.Close(RenameSymbolOverlayID),
+				ls.Rename(r.name, r.row, r.col, r.newName.Value()),
+			)
+		case key.Matches(msg, config.Keys.Cancel):
+			return r, overlay.Close(RenameSymbolOverlayID)
+		}
+	}
+
+	var cmd tea.Cmd
+	r.newName, cmd = r.newName.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	return r, tea.Batch(cmds...)
+}
+
+func (r RenameSymbolOverlay) View(width int, height int) string {
+	return r.newName.View()
+}
@@ -1,59 +1,60 @@
 package searchbar
 
 import (
-	"bytes"
-
 	"github.com/charmbracelet/bubbletea/v2"
-	"github.com/charmbracelet/x/ansi"
 
 	"go.gopad.dev/gopad/gopad/buffer"
 )
 
-func Search(term string) tea.Cmd {
+func Search(term string, options Options) tea.Cmd {
 	return func() tea.Msg {
 		return SearchMsg{
-			Term: term,
+			Term:    term,
+			Options: options,
 		}
 	}
 }
 
 type SearchMsg struct {
-	Term string
+	Term    string
+	Options Options
 }
 
-func ExecSearch(term string, buffer *buffer.Buffer) tea.Cmd {
+func ExecSearch(term string, options Options, buffer *buffer.Buffer) tea.Cmd {
 	return func() tea.Msg {
-		bytesTerm := []byte(term)
-		termWidth := ansi.StringWidth(term)
-		buf := buffer.Bytes()
-
-		var offset int
-		var results []Result
-		for {
-			index := bytes.Index(buf[offset:], bytesTerm)
-			if index == -1 {
-				break
+		re, err := Pattern(term, options)
+		if err != nil {
+			return searchResultMsg{
+				Err: err,
 			}
-
-			row, col := buffer.Index(index + offset)
-			rowEnd, colEnd := buffer.Index(index + offset + termWidth)
-
-			results = append(results, Result{
-				RowStart: row,
-				ColStart: col,
-				RowEnd:   rowEnd,
-				ColEnd:   colEnd + 1,
-			})
-
-			offset += index + termWidth
 		}
 
 		return searchResultMsg{
-			Results: results,
+			Results: Find(re, buffer),
 		}
 	}
 }
 
 type searchResultMsg struct {
 	Results []Result
+	Err     error
+}
+
+// Replace replaces the match at the result or all matches if result is nil.
+func Replace(term string, options Options, replacement string, result *Result) tea.Cmd {
+	return func() tea.Msg {
+		return ReplaceMsg{
+			Term:        term,
+			Options:     options,
+			Replacement: replacement,
+			Result:      result,
+		}
+	}
+}
+
+type ReplaceMsg struct {
+	Term        string
+	Options     Options
+	Replacement string
+	Result      *Result
 }
@@ -0,0 +1,142 @@
+package searchbar
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+type Options struct {
+	Regex         bool
+	CaseSensitive bool
+	WholeWord     bool
+}
+
+// Pattern compiles the search term with the given options to a RE2 regular expression.
+func Pattern(term string, options Options) (*regexp.Regexp, error) {
+	if !options.Regex {
+		term = regexp.QuoteMeta(term)
+	}
+	if options.WholeWord {
+		term = `\b(?:` + term + `)\b`
+	}
+	if !options.CaseSensitive {
+		term = "(?i)" + term
+	}
+	// ^ and $ match at the start and end of lines
+	term = "(?m)" + term
+
+	re, err := regexp.Compile(term)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	return re, nil
+}
+
+type Result struct {
+	RowStart int
+	ColStart int
+	RowEnd   int
+	ColEnd   int
+}
+
+func (r Result) Range() buffer.Range {
+	return buffer.Range{
+		Start: buffer.Position{Row: r.RowStart, Col: r.ColStart},
+		End:   buffer.Position{Row: r.RowEnd, Col: r.ColEnd},
+	}
+}
+
+type match struct {
+	result  Result
+	indexes []int
+}
+
+// positionWalker converts increasing byte indexes in the buffer content to rows and rune columns.
+// It continues from the last converted index, so converting all matches walks the content only once.
+type positionWalker struct {
+	b *buffer.Buffer
+	// row is the current row and rowStart the index its line starts at
+	row      int
+	rowStart int
+	// index is the last converted index and col its column
+	index int
+	col   int
+}
+
+func (w *positionWalker) position(index int) (int, int) {
+	line := w.b.Line(w.row).Bytes()
+	for w.row < w.b.LinesLen()-1 && w.rowStart+len(line) < index {
+		w.rowStart += len(line) + 1
+		w.row++
+		w.index = w.rowStart
+		w.col = 0
+		line = w.b.Line(w.row).Bytes()
+	}
+
+	end := min(index-w.rowStart, len(line))
+	w.col += utf8.RuneCount(line[w.index-w.rowStart : end])
+	w.index = w.rowStart + end
+	return w.row, w.col
+}
+
+func find(re *regexp.Regexp, b *buffer.Buffer, data []byte) []match {
+	var matches []match
+	w := positionWalker{b: b}
+	for _, indexes := range re.FindAllSubmatchIndex(data, -1) {
+		// empty matches can't be selected or replaced in a meaningful way
+		if indexes[0] == indexes[1] {
+			continue
+		}
+
+		rowStart, colStart := w.position(indexes[0])
+		rowEnd, colEnd := w.position(indexes[1])
+		matches = append(matches, match{
+			result: Result{
+				RowStart: rowStart,
+				ColStart: colStart,
+				RowEnd:   rowEnd,
+				ColEnd:   colEnd,
+			},
+			indexes: indexes,
+		})
+	}
+	return matches
+}
+
+// Find returns the ranges of all matches of the pattern in the buffer.
+func Find(re *regexp.Regexp, b *buffer.Buffer) []Result {
+	matches := find(re, b, b.Bytes())
+	results := make([]Result, len(matches))
+	for i, m := range matches {
+		results[i] = m.result
+	}
+	return results
+}
+
+// ReplaceEdits returns the edits replacing the matches of the pattern in the buffer.
+// If only is set, only the match starting at the result is replaced.
+// With regex enabled, $1 style capture groups in the replacement are expanded.
+func ReplaceEdits(re *regexp.Regexp, options Options, replacement string, b *buffer.Buffer, only *Result) []ls.TextEdit {
+	data := b.Bytes()
+
+	var edits []ls.TextEdit
+	for _, m := range find(re, b, data) {
+		if only != nil && (m.result.RowStart != only.RowStart || m.result.ColStart != only.ColStart) {
+			continue
+		}
+
+		newText := replacement
+		if options.Regex {
+			newText = string(re.Expand(nil, []byte(replacement), data, m.indexes))
+		}
+		edits = append(edits, ls.TextEdit{
+			Range:   m.result.Range(),
+			NewText: newText,
+		})
+	}
+	return edits
+}
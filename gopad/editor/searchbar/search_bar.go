@@ -2,6 +2,7 @@ package searchbar
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss"
@@ -15,36 +16,57 @@ import (
 	"go.gopad.dev/gopad/internal/bubbles/textinput"
 )
 
-const ZoneID = "editor.search-bar"
+const (
+	ZoneID          = "editor.search-bar"
+	ZoneRegex       = "editor.search-bar.regex"
+	ZoneCase        = "editor.search-bar.case"
+	ZoneWholeWord   = "editor.search-bar.whole-word"
+	ZoneReplace     = "editor.search-bar.replace"
+	ZoneReplaceOne  = "editor.search-bar.replace-one"
+	ZoneReplaceAll  = "editor.search-bar.replace-all"
+	ZoneReplaceArea = "editor.search-bar.replace-area"
+)
+
+const maxHistory = 50
 
 func onSelect(result Result) tea.Cmd {
 	return file.Scroll(result.RowStart, result.ColStart)
 }
 
-type Result struct {
-	RowStart int
-	ColStart int
-	RowEnd   int
-	ColEnd   int
-}
-
 func New() Model {
 	ti := config.NewTextInput()
 	ti.Placeholder = "type to search"
 	ti.Width = 20
 
+	ri := config.NewTextInput()
+	ri.Placeholder = "replace with"
+	ri.Width = 20
+
 	return Model{
-		TextInput: ti,
+		TextInput:    ti,
+		ReplaceInput: ri,
+		historyIndex: -1,
+		options: Options{
+			CaseSensitive: true,
+		},
 	}
 }
 
 type Model struct {
-	TextInput textinput.Model
-	focus     bool
-	show      bool
+	TextInput    textinput.Model
+	ReplaceInput textinput.Model
+	focus        bool
+	show         bool
+	showReplace  bool
+	focusReplace bool
+	options      Options
 
 	results     []Result
 	resultIndex int
+	err         error
+
+	history      []string
+	historyIndex int
 }
 
 func (m *Model) Visible() bool {
@@ -65,12 +87,107 @@ func (m *Model) Focused() bool {
 
 func (m *Model) Focus() tea.Cmd {
 	m.focus = true
+	if m.focusReplace {
+		return m.ReplaceInput.Focus()
+	}
 	return m.TextInput.Focus()
 }
 
 func (m *Model) Blur() {
 	m.focus = false
 	m.TextInput.Blur()
+	m.ReplaceInput.Blur()
+}
+
+// RefreshStyles applies the styles of the current theme to the inputs.
+func (m *Model) RefreshStyles() {
+	for _, ti := range []*textinput.Model{&m.TextInput, &m.ReplaceInput} {
+		ti.Styles = config.Theme.UI.TextInput
+		ti.Cursor.Styles = config.Theme.UI.Cursor
+	}
+}
+
+func (m *Model) Options() Options {
+	return m.options
+}
+
+// History returns the search terms starting with the most recent one.
+func (m *Model) History() []string {
+	return m.history
+}
+
+func (m *Model) SetHistory(history []string) {
+	m.history = history[:min(len(history), maxHistory)]
+	m.historyIndex = -1
+}
+
+// addHistory adds the current search term to the history.
+func (m *Model) addHistory() {
+	term := m.TextInput.Value()
+	if term == "" {
+		return
+	}
+	m.history = slices.DeleteFunc(m.history, func(h string) bool {
+		return h == term
+	})
+	m.history = slices.Insert(m.history, 0, term)
+	m.history = m.history[:min(len(m.history), maxHistory)]
+	m.historyIndex = -1
+}
+
+// selectHistory replaces the search term with the history entry at the index, -1 clears the search term.
+func (m *Model) selectHistory(index int) tea.Cmd {
+	if len(m.history) == 0 {
+		return nil
+	}
+	m.historyIndex = max(-1, min(index, len(m.history)-1))
+
+	var term string
+	if m.historyIndex > -1 {
+		term = m.history[m.historyIndex]
+	}
+	m.TextInput.SetValue(term)
+	m.TextInput.CursorEnd()
+	m.resultIndex = 0
+	return m.search()
+}
+
+func (m *Model) search() tea.Cmd {
+	if m.TextInput.Value() == "" {
+		m.results = nil
+		m.resultIndex = 0
+		m.err = nil
+		return nil
+	}
+	return Search(m.TextInput.Value(), m.options)
+}
+
+func (m *Model) toggleReplace() tea.Cmd {
+	m.showReplace = !m.showReplace
+	return m.switchInput(m.showReplace)
+}
+
+func (m *Model) switchInput(replace bool) tea.Cmd {
+	m.focusReplace = replace && m.showReplace
+	if !m.focus {
+		return nil
+	}
+	m.TextInput.Blur()
+	m.ReplaceInput.Blur()
+	return m.Focus()
+}
+
+// replace replaces the selected result or all results.
+func (m *Model) replace(all bool) tea.Cmd {
+	if len(m.results) == 0 {
+		return nil
+	}
+
+	var result *Result
+	if !all {
+		result = &m.results[m.resultIndex]
+	}
+	return Replace(m.TextInput.Value(), m.options, m.ReplaceInput.Value(), result)
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
@@ -79,22 +196,44 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case searchResultMsg:
 		m.results = msg.Results
-		m.resultIndex = 0
+		m.err = msg.Err
+		m.resultIndex = max(min(m.resultIndex, len(m.results)-1), 0)
 		if len(m.results) > 0 {
-			cmds = append(cmds, onSelect(m.results[0]))
+			cmds = append(cmds, onSelect(m.results[m.resultIndex]))
 		}
-	case tea.MouseMsg:
+	case tea.MouseClickMsg:
 		switch {
+		case mouse.Matches(msg, ZoneRegex, tea.MouseLeft):
+			m.options.Regex = !m.options.Regex
+			cmds = append(cmds, m.search())
+		case mouse.Matches(msg, ZoneCase, tea.MouseLeft):
+			m.options.CaseSensitive = !m.options.CaseSensitive
+			cmds = append(cmds, m.search())
+		case mouse.Matches(msg, ZoneWholeWord, tea.MouseLeft):
+			m.options.WholeWord = !m.options.WholeWord
+			cmds = append(cmds, m.search())
+		case mouse.Matches(msg, ZoneReplace, tea.MouseLeft):
+			cmds = append(cmds, m.toggleReplace())
+		case mouse.Matches(msg, ZoneReplaceOne, tea.MouseLeft):
+			cmds = append(cmds, m.replace(false))
+		case mouse.Matches(msg, ZoneReplaceAll, tea.MouseLeft):
+			cmds = append(cmds, m.replace(true))
+		case mouse.Matches(msg, ZoneReplaceArea, tea.MouseLeft):
+			cmds = append(cmds, m.switchInput(true))
 		case mouse.Matches(msg, ZoneID, tea.MouseLeft):
-			if !m.Focused() {
-				cmds = append(cmds, editormsg.Focus(editormsg.ModelSearch))
-			}
+			cmds = append(cmds, m.switchInput(false))
+		default:
 			return m, tea.Batch(cmds...)
 		}
+		if !m.Focused() {
+			cmds = append(cmds, editormsg.Focus(editormsg.ModelSearch))
+		}
+		return m, tea.Batch(cmds...)
 	case tea.KeyPressMsg:
 		if m.Focused() {
 			switch {
 			case key.Matches(msg, config.Keys.Editor.SearchBar.Close):
+				m.addHistory()
 				m.Hide()
 				cmds = append(cmds, editormsg.Focus(editormsg.ModelFile))
 				return m, tea.Batch(cmds...)
@@ -116,39 +255,105 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 					}
 					cmds = append(cmds, onSelect(m.results[m.resultIndex]))
 				}
+			case key.Matches(msg, config.Keys.Editor.SearchBar.HistoryPrev) && !m.focusReplace:
+				cmds = append(cmds, m.selectHistory(m.historyIndex+1))
+				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.HistoryNext) && !m.focusReplace:
+				cmds = append(cmds, m.selectHistory(m.historyIndex-1))
+				return m, tea.Batch(cmds...)
 			case key.Matches(msg, config.Keys.Editor.SearchBar.SelectResult):
+				m.addHistory()
 				if len(m.results) > 0 {
 					cmds = append(cmds, onSelect(m.results[m.resultIndex]), editormsg.Focus(editormsg.ModelFile))
 				}
 				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleRegex):
+				m.options.Regex = !m.options.Regex
+				cmds = append(cmds, m.search())
+				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleCaseSensitive):
+				m.options.CaseSensitive = !m.options.CaseSensitive
+				cmds = append(cmds, m.search())
+				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleWholeWord):
+				m.options.WholeWord = !m.options.WholeWord
+				cmds = append(cmds, m.search())
+				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.ToggleReplace):
+				cmds = append(cmds, m.toggleReplace())
+				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.SwitchInput) && m.showReplace:
+				cmds = append(cmds, m.switchInput(!m.focusReplace))
+				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.Replace) && m.showReplace:
+				cmds = append(cmds, m.replace(false))
+				return m, tea.Batch(cmds...)
+			case key.Matches(msg, config.Keys.Editor.SearchBar.ReplaceAll) && m.showReplace:
+				cmds = append(cmds, m.replace(true))
+				return m, tea.Batch(cmds...)
 			}
 		}
 	}
 
 	if m.Focused() {
-		previousValue := m.TextInput.Value()
 		var cmd tea.Cmd
+		if m.focusReplace {
+			m.ReplaceInput, cmd = m.ReplaceInput.Update(msg)
+			cmds = append(cmds, cmd)
+			return m, tea.Batch(cmds...)
+		}
+
+		previousValue := m.TextInput.Value()
 		m.TextInput, cmd = m.TextInput.Update(msg)
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
-		newValue := m.TextInput.Value()
-		if previousValue != newValue && newValue != "" {
-			cmds = append(cmds, Search(newValue))
+		if previousValue != m.TextInput.Value() {
+			m.resultIndex = 0
+			m.historyIndex = -1
+			cmds = append(cmds, m.search())
 		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+func (m Model) optionView(zoneID string, label string, enabled bool) string {
+	style := config.Theme.UI.SearchBar.OptionStyle
+	if enabled {
+		style = config.Theme.UI.SearchBar.SelectedOptionStyle
+	}
+	return zone.Mark(zoneID, style.Render(label))
+}
+
 func (m Model) View() string {
 	results := "0 results"
-	if len(m.results) > 0 {
+	if m.err != nil {
+		results = "invalid regex"
+	} else if len(m.results) > 0 {
 		results = fmt.Sprintf(" %d/%d 🠅🠇", m.resultIndex+1, len(m.results))
 	}
 
-	return config.Theme.UI.SearchBar.Style.Render(zone.Mark(ZoneID, lipgloss.JoinHorizontal(lipgloss.Center,
+	search := lipgloss.JoinHorizontal(lipgloss.Center,
 		m.TextInput.View(),
+		m.optionView(ZoneRegex, ".*", m.options.Regex),
+		m.optionView(ZoneCase, "Aa", m.options.CaseSensitive),
+		m.optionView(ZoneWholeWord, "ab", m.options.WholeWord),
+		m.optionView(ZoneReplace, "⇄", m.showReplace),
 		config.Theme.UI.SearchBar.ResultStyle.Render(results),
-	)))
+	)
+	if !m.showReplace {
+		return config.Theme.UI.SearchBar.Style.Render(zone.Mark(ZoneID, search))
+	}
+
+	replace := lipgloss.JoinHorizontal(lipgloss.Center,
+		zone.Mark(ZoneReplaceArea, m.ReplaceInput.View()),
+		m.optionView(ZoneReplaceOne, "replace", false),
+		m.optionView(ZoneReplaceAll, "all", false),
+	)
+
+	return config.Theme.UI.SearchBar.Style.Render(lipgloss.JoinVertical(lipgloss.Left,
+		zone.Mark(ZoneID, search),
+		replace,
+	))
 }
@@ -0,0 +1,119 @@
+package searchbar
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/ls"
+)
+
+func TestFind(t *testing.T) {
+	data := []struct {
+		text    string
+		term    string
+		options Options
+		want    []Result
+	}{
+		{
+			text: "äöü foo\nFoo",
+			term: "foo",
+			want: []Result{
+				{RowStart: 0, ColStart: 4, RowEnd: 0, ColEnd: 7},
+				{RowStart: 1, ColStart: 0, RowEnd: 1, ColEnd: 3},
+			},
+		},
+		{
+			text:    "äöü foo\nFoo",
+			term:    "foo",
+			options: Options{CaseSensitive: true},
+			want: []Result{
+				{RowStart: 0, ColStart: 4, RowEnd: 0, ColEnd: 7},
+			},
+		},
+		{
+			text:    "foo foobar",
+			term:    "foo",
+			options: Options{WholeWord: true},
+			want: []Result{
+				{RowStart: 0, ColStart: 0, RowEnd: 0, ColEnd: 3},
+			},
+		},
+		{
+			text:    "a.b\na1b",
+			term:    "a.b",
+			options: Options{},
+			want: []Result{
+				{RowStart: 0, ColStart: 0, RowEnd: 0, ColEnd: 3},
+			},
+		},
+		{
+			text:    "a\nbb\n",
+			term:    "^b+$",
+			options: Options{Regex: true},
+			want: []Result{
+				{RowStart: 1, ColStart: 0, RowEnd: 1, ColEnd: 2},
+			},
+		},
+		{
+			text:    "ä x ö x\n\nü x",
+			term:    "x\n*",
+			options: Options{Regex: true},
+			want: []Result{
+				{RowStart: 0, ColStart: 2, RowEnd: 0, ColEnd: 3},
+				{RowStart: 0, ColStart: 6, RowEnd: 2, ColEnd: 0},
+				{RowStart: 2, ColStart: 2, RowEnd: 2, ColEnd: 3},
+			},
+		},
+	}
+
+	for _, d := range data {
+		b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+		assert.NoError(t, err)
+
+		re, err := Pattern(d.term, d.options)
+		assert.NoError(t, err)
+		assert.Equal(t, d.want, Find(re, b))
+	}
+}
+
+func TestReplaceEdits(t *testing.T) {
+	data := []struct {
+		text        string
+		term        string
+		options     Options
+		replacement string
+		only        *Result
+		want        []ls.TextEdit
+	}{
+		{
+			text:        "key: value",
+			term:        `(\w+): (\w+)`,
+			options:     Options{Regex: true},
+			replacement: "$2 = ${1}",
+			want: []ls.TextEdit{
+				{Range: buffer.Range{End: buffer.Position{Col: 10}}, NewText: "value = key"},
+			},
+		},
+		{
+			text:        "a $1 a",
+			term:        "a",
+			replacement: "$1",
+			only:        &Result{RowStart: 0, ColStart: 5},
+			want: []ls.TextEdit{
+				{Range: buffer.Range{Start: buffer.Position{Col: 5}, End: buffer.Position{Col: 6}}, NewText: "$1"},
+			},
+		},
+	}
+
+	for _, d := range data {
+		b, err := buffer.New("test.txt", bytes.NewReader([]byte(d.text)), "utf-8", buffer.LineEndingLF, false)
+		assert.NoError(t, err)
+
+		re, err := Pattern(d.term, d.options)
+		assert.NoError(t, err)
+		assert.Equal(t, d.want, ReplaceEdits(re, d.options, d.replacement, b, d.only))
+	}
+}
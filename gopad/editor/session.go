@@ -0,0 +1,62 @@
+package editor
+
+import (
+	"log"
+	"slices"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/session"
+)
+
+// Session returns the state of the workspace which is restored on the next start.
+func (e *Editor) Session() session.Session {
+	s := session.Session{
+		Workspace:     e.workspace,
+		ActiveFile:    slices.Index(e.files, e.File()),
+		FileTree:      e.fileTree.Expanded(),
+		SearchHistory: e.searchBar.History(),
+		Theme:         config.Gopad.Theme,
+	}
+
+	for _, f := range e.files {
+		row, col := f.Cursor()
+		offsetRow, offsetCol := f.CursorOffset()
+		s.Files = append(s.Files, session.File{
+			Name:      f.Name(),
+			Row:       row,
+			Col:       col,
+			OffsetRow: offsetRow,
+			OffsetCol: offsetCol,
+		})
+	}
+
+	return s
+}
+
+// restoreSession opens the files of the session and restores their cursor and scroll positions.
+// Files which no longer exist are skipped.
+func (e *Editor) restoreSession(s session.Session) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, sf := range s.Files {
+		cmd, err := e.OpenFile(sf.Name)
+		if err != nil {
+			log.Printf("error while restoring file %s: %s\n", sf.Name, err)
+			continue
+		}
+		cmds = append(cmds, cmd)
+
+		f := e.FileByName(sf.Name)
+		f.SetCursor(sf.Row, sf.Col)
+		f.SetCursorOffset(sf.OffsetRow, sf.OffsetCol)
+	}
+
+	if s.ActiveFile >= 0 && s.ActiveFile < len(s.Files) {
+		e.SetFileByName(s.Files[s.ActiveFile].Name)
+	}
+	e.fileTree.SetExpanded(s.FileTree)
+	e.searchBar.SetHistory(s.SearchHistory)
+
+	return tea.Batch(cmds...)
+}
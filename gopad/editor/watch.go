@@ -0,0 +1,192 @@
+package editor
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/gopad/editor/file"
+	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/internal/bubbles/notifications"
+	"go.gopad.dev/gopad/internal/bubbles/overlay"
+	"go.gopad.dev/gopad/internal/watcher"
+)
+
+const defaultWatchInterval = 5 * time.Second
+
+type watchMsg struct {
+	id       int
+	snapshot watcher.Snapshot
+}
+
+// fileModifiedMsg is sent when the content of an open file differs from the content on disk.
+type fileModifiedMsg struct {
+	name    string
+	version int32
+}
+
+// startWatch starts polling the workspace and the open files for changes and stops any previous poll loop.
+func (e *Editor) startWatch() tea.Cmd {
+	e.watchID++
+	e.snapshot = nil
+	if !config.Gopad.FileTree.Watch {
+		return nil
+	}
+	return e.watch()
+}
+
+func (e *Editor) watch() tea.Cmd {
+	interval := time.Duration(config.Gopad.FileTree.WatchInterval)
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	id := e.watchID
+	workspace := e.workspace
+	names := make([]string, len(e.files))
+	for i, f := range e.files {
+		names[i] = f.Name()
+	}
+
+	// the workspace is walked in the command, not in the update loop
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return watchMsg{
+			id:       id,
+			snapshot: takeSnapshot(workspace, names),
+		}
+	})
+}
+
+// takeSnapshot collects the state of all files in the workspace and the open files.
+func takeSnapshot(workspace string, names []string) watcher.Snapshot {
+	snapshot := watcher.Snapshot{}
+	if workspace != "" {
+		_ = walkWorkspace(context.Background(), workspace, config.Gopad.FileTree.Ignored, func(name string, d fs.DirEntry) {
+			info, err := d.Info()
+			if err != nil {
+				return
+			}
+			snapshot[name] = watcher.Entry{
+				ModTime: info.ModTime(),
+				Size:    info.Size(),
+				IsDir:   d.IsDir(),
+			}
+		})
+	}
+
+	// open files can be outside the workspace or ignored
+	for _, name := range names {
+		if _, ok := snapshot[name]; ok {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		snapshot[name] = watcher.Entry{
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+		}
+	}
+
+	return snapshot
+}
+
+// updateSnapshot applies the changes since the last snapshot to the file tree, the open files and the language servers.
+func (e *Editor) updateSnapshot(snapshot watcher.Snapshot) tea.Cmd {
+	oldSnapshot := e.snapshot
+	e.snapshot = snapshot
+	if oldSnapshot == nil {
+		return nil
+	}
+
+	var (
+		cmds   []tea.Cmd
+		events []ls.FileEvent
+	)
+	addEvent := func(name string, eventType ls.FileEventType) {
+		if e.workspace != "" && strings.HasPrefix(name, e.workspace+string(filepath.Separator)) {
+			events = append(events, ls.FileEvent{Name: name, Type: eventType})
+		}
+	}
+
+	for _, event := range watcher.Diff(oldSnapshot, snapshot) {
+		switch event.Op {
+		case watcher.OpCreate:
+			e.fileTree.Add(event.Name, event.IsDir)
+			addEvent(event.Name, ls.FileEventCreated)
+			cmds = append(cmds, e.checkFileChanged(event.Name))
+		case watcher.OpWrite:
+			addEvent(event.Name, ls.FileEventChanged)
+			cmds = append(cmds, e.checkFileChanged(event.Name))
+		case watcher.OpRemove:
+			e.fileTree.Remove(event.Name)
+			addEvent(event.Name, ls.FileEventDeleted)
+			if f := e.FileByName(event.Name); f != nil {
+				cmds = append(cmds, notifications.Addf("file %s was deleted on disk", event.Name))
+			}
+		case watcher.OpRename:
+			e.fileTree.Rename(event.OldName, event.Name, event.IsDir)
+			addEvent(event.OldName, ls.FileEventDeleted)
+			addEvent(event.Name, ls.FileEventCreated)
+			if f := e.FileByName(event.OldName); f != nil {
+				cmds = append(cmds, notifications.Addf("file %s was moved to %s on disk", event.OldName, event.Name))
+			}
+		}
+	}
+
+	if len(events) > 0 {
+		cmds = append(cmds, ls.WatchedFilesChanged(e.workspace, events))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// checkFileChanged compares the content of the open file with the content on disk in a command.
+// The file is reloaded by fileModified if it changed.
+func (e *Editor) checkFileChanged(name string) tea.Cmd {
+	f := e.FileByName(name)
+	if f == nil || f.Loading() {
+		return nil
+	}
+
+	b := f.Buffer().Copy()
+	version := f.Version()
+	return func() tea.Msg {
+		modified, err := b.ModifiedOnDisk()
+		if err != nil {
+			return notifications.Addf("error while checking file %s: %s", name, err)()
+		}
+		if !modified {
+			return nil
+		}
+		return fileModifiedMsg{
+			name:    name,
+			version: version,
+		}
+	}
+}
+
+// fileModified reloads the open file which changed on disk.
+// Files with unsaved changes are only reloaded after asking.
+func (e *Editor) fileModified(msg fileModifiedMsg) tea.Cmd {
+	f := e.FileByName(msg.name)
+	if f == nil {
+		return nil
+	}
+	// the file was edited while it was checked
+	if f.Version() != msg.version {
+		return e.checkFileChanged(msg.name)
+	}
+
+	if f.Dirty() {
+		return overlay.Open(NewFileChangedOverlay(msg.name))
+	}
+	return file.ReloadFile(msg.name)
+}
@@ -14,6 +14,8 @@ import (
 	"go.gopad.dev/gopad/gopad/config"
 	"go.gopad.dev/gopad/gopad/editor"
 	"go.gopad.dev/gopad/gopad/ls"
+	"go.gopad.dev/gopad/gopad/recovery"
+	"go.gopad.dev/gopad/gopad/session"
 	"go.gopad.dev/gopad/internal/bubbles"
 	"go.gopad.dev/gopad/internal/bubbles/cursor"
 	"go.gopad.dev/gopad/internal/bubbles/key"
@@ -26,12 +28,33 @@ const (
 	ZoneTheme = "theme"
 )
 
-func New(lsClient *ls.Client, version string, workspace string, args []string) *Gopad {
+func New(lsClient *ls.Client, version string, workspace string, args []string, noSession bool) *Gopad {
+	recoveries, err := recovery.Pending()
+	if err != nil {
+		log.Printf("error reading recovery files: %s\n", err)
+	}
+
+	var s *session.Session
+	if workspace != "" && !noSession {
+		if s, err = session.Load(workspace); err != nil {
+			log.Printf("error reading session: %s\n", err)
+		}
+		// the theme has to be set before any styles are created
+		if s != nil && s.Theme != "" {
+			if err = config.SetTheme(s.Theme); err != nil {
+				log.Printf("error restoring theme: %s\n", err)
+			}
+		}
+	}
+
 	return &Gopad{
-		lsClient:  lsClient,
-		version:   version,
-		workspace: workspace,
-		args:      args,
+		lsClient:   lsClient,
+		version:    version,
+		workspace:  workspace,
+		args:       args,
+		noSession:  noSession,
+		session:    s,
+		recoveries: recoveries,
 	}
 }
 
@@ -40,6 +63,11 @@ type Gopad struct {
 	version   string
 	workspace string
 	args      []string
+	noSession bool
+	// session holds the state of the last session in the workspace
+	session *session.Session
+	// recoveries holds the recovery files of the last session which are offered to restore
+	recoveries []recovery.File
 
 	height int
 	width  int
@@ -63,7 +91,7 @@ func (g Gopad) Init() (tea.Model, tea.Cmd) {
 	log.Printf("Initializing gopad, version: %s\n", g.version)
 
 	var err error
-	g.editor, err = editor.NewEditor(g.workspace, g.args)
+	g.editor, err = editor.NewEditor(g.workspace, g.args, g.session)
 	if err != nil {
 		return g, notifications.Add(fmt.Sprintf("Error initializing editor: %s", err))
 	}
@@ -83,10 +111,22 @@ func (g Gopad) Init() (tea.Model, tea.Cmd) {
 	g.overlays = config.NewOverlays()
 	g.notifications = config.NewNotifications()
 
+	for _, r := range g.recoveries {
+		cmds = append(cmds, overlay.Open(editor.NewRecoveryOverlay(r)))
+	}
+	g.recoveries = nil
+
 	return g, tea.Batch(cmds...)
 }
 
 func (g Gopad) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	g, cmd := g.update(msg)
+	// highlight the lines the next view shows, so rendering only reads the cached highlights
+	g.editor.RefreshHighlights(g.height)
+	return g, cmd
+}
+
+func (g Gopad) update(msg tea.Msg) (Gopad, tea.Cmd) {
 	//now := time.Now()
 	//defer func() {
 	//	log.Printf("Update time: %s\nMessage: %T", time.Since(now), msg)
@@ -104,6 +144,26 @@ func (g Gopad) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		g.width = msg.Width
 		return g, tea.Batch(cmds...)
 
+	case quitMsg:
+		// unsaved changes are discarded on purpose, so there is nothing to recover
+		cmds = append(cmds, tea.Sequence(g.editor.DiscardRecovery(), g.saveSession(), tea.Quit))
+		return g, tea.Batch(cmds...)
+
+	case setThemeMsg:
+		if err := config.SetTheme(msg.name); err != nil {
+			cmds = append(cmds, notifications.Addf("error while setting theme: %s", err))
+			return g, tea.Batch(cmds...)
+		}
+		g.overlays.Styles = config.Theme.UI.Overlay.Styles
+		g.notifications.Styles = config.Theme.UI.NotificationStyle
+		g.editor.RefreshStyles()
+		cmds = append(cmds,
+			tea.SetBackgroundColor(config.Theme.UI.Background),
+			tea.SetForegroundColor(config.Theme.UI.Foreground),
+			notifications.Addf("theme %s set", msg.name),
+		)
+		return g, tea.Batch(cmds...)
+
 	case overlay.ResetFocusMsg:
 		cmds = append(cmds, g.editor.Focus())
 		return g, tea.Batch(cmds...)
@@ -135,7 +195,7 @@ func (g Gopad) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if g.editor.HasChanges() {
 					return g, overlay.Open(NewQuitOverlay())
 				}
-				return g, tea.Quit
+				return g, Quit
 			}
 		case key.Matches(msg, config.Keys.Help):
 			if !g.overlays.Has(HelpOverlayID) {
@@ -156,6 +216,15 @@ func (g Gopad) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if !g.overlays.Has(KeyMapperOverlayID) {
 					cmds = append(cmds, overlay.Open(NewKeyMapperOverlay()))
 				}
+			case key.Matches(msg, config.Keys.Editor.File.QuickOpen):
+				if !g.overlays.Has(editor.FileFinderOverlayID) {
+					workspace := g.editor.Workspace()
+					if workspace == "" {
+						cmds = append(cmds, notifications.Add("no folder open"))
+						return g, tea.Batch(cmds...)
+					}
+					cmds = append(cmds, overlay.Open(editor.NewFileFinderOverlay(workspace, g.editor.RecentFiles())))
+				}
 			case key.Matches(msg, config.Keys.Editor.File.Open):
 				if !g.overlays.Has(editor.OpenOverlayID) {
 					path, err := os.Getwd()
@@ -201,6 +270,21 @@ func (g Gopad) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return g, tea.Batch(cmds...)
 }
 
+// saveSession returns a command writing the session of the workspace.
+func (g Gopad) saveSession() tea.Cmd {
+	if g.noSession || g.editor.Workspace() == "" {
+		return nil
+	}
+
+	s := g.editor.Session()
+	return func() tea.Msg {
+		if err := session.Save(s); err != nil {
+			log.Printf("error writing session: %s\n", err)
+		}
+		return nil
+	}
+}
+
 func (g Gopad) View() string {
 	//now := time.Now()
 	//defer func() {
@@ -253,7 +337,9 @@ func (g Gopad) CodeBar() string {
 			infoLine = append(infoLine, zone.Mark(editor.ZoneFileGoTo, inlineBarStyle(fmt.Sprintf("[%d:%d]", cursorRow+1, cursorCol+1))))
 		}
 
-		if servers := g.lsClient.SupportedServers(file.Name()); len(servers) > 0 {
+		if file.LargeFile() {
+			infoLine = append(infoLine, inlineBarStyle("large file"))
+		} else if servers := g.lsClient.SupportedServers(file.Name()); len(servers) > 0 {
 			var clientNames []string
 			for _, server := range servers {
 				clientNames = append(clientNames, server.Name())
@@ -5,9 +5,37 @@ import (
 
 	"go.lsp.dev/protocol"
 
+	"go.gopad.dev/gopad/gopad/buffer"
 	"go.gopad.dev/gopad/gopad/config"
 )
 
+// initializeParams adds the position encodings of LSP 3.17 to the initialize request, the protocol package doesn't support them yet.
+type initializeParams struct {
+	protocol.InitializeParams
+	Capabilities initializeClientCapabilities `json:"capabilities"`
+}
+
+type initializeClientCapabilities struct {
+	protocol.ClientCapabilities
+	General initializeGeneralCapabilities `json:"general"`
+}
+
+type initializeGeneralCapabilities struct {
+	protocol.GeneralClientCapabilities
+	PositionEncodings []buffer.PositionEncoding `json:"positionEncodings,omitempty"`
+}
+
+// initializeResult adds the position encoding chosen by the server to the initialize result.
+type initializeResult struct {
+	protocol.InitializeResult
+	Capabilities initializeServerCapabilities `json:"capabilities"`
+}
+
+type initializeServerCapabilities struct {
+	protocol.ServerCapabilities
+	PositionEncoding buffer.PositionEncoding `json:"positionEncoding,omitempty"`
+}
+
 func clientCapabilities(cfg config.LanguageServerConfig) protocol.ClientCapabilities {
 	var completion *protocol.CompletionTextDocumentClientCapabilities
 	if slices.Contains(cfg.Features, config.LanguageServerFeatureCompletion) {
@@ -103,10 +131,100 @@ func clientCapabilities(cfg config.LanguageServerConfig) protocol.ClientCapabili
 		}
 	}
 
+	var implementation *protocol.ImplementationTextDocumentClientCapabilities
+	if slices.Contains(cfg.Features, config.LanguageServerFeatureGoToImplementation) {
+		implementation = &protocol.ImplementationTextDocumentClientCapabilities{
+			DynamicRegistration: false,
+			LinkSupport:         false,
+		}
+	}
+
+	var references *protocol.ReferencesTextDocumentClientCapabilities
+	if slices.Contains(cfg.Features, config.LanguageServerFeatureFindReferences) {
+		references = &protocol.ReferencesTextDocumentClientCapabilities{
+			DynamicRegistration: false,
+		}
+	}
+
+	var rename *protocol.RenameClientCapabilities
+	if slices.Contains(cfg.Features, config.LanguageServerFeatureRename) {
+		rename = &protocol.RenameClientCapabilities{
+			DynamicRegistration: false,
+			PrepareSupport:      true,
+		}
+	}
+
+	var hover *protocol.HoverTextDocumentClientCapabilities
+	if slices.Contains(cfg.Features, config.LanguageServerFeatureHover) {
+		hover = &protocol.HoverTextDocumentClientCapabilities{
+			DynamicRegistration: false,
+			ContentFormat:       []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+		}
+	}
+
+	var signatureHelp *protocol.SignatureHelpTextDocumentClientCapabilities
+	if slices.Contains(cfg.Features, config.LanguageServerFeatureSignatureHelp) {
+		signatureHelp = &protocol.SignatureHelpTextDocumentClientCapabilities{
+			DynamicRegistration: false,
+			SignatureInformation: &protocol.TextDocumentClientCapabilitiesSignatureInformation{
+				DocumentationFormat: []protocol.MarkupKind{protocol.PlainText},
+				ParameterInformation: &protocol.TextDocumentClientCapabilitiesParameterInformation{
+					LabelOffsetSupport: false,
+				},
+				ActiveParameterSupport: true,
+			},
+			ContextSupport: true,
+		}
+	}
+
+	var formatting *protocol.DocumentFormattingClientCapabilities
+	var rangeFormatting *protocol.DocumentRangeFormattingClientCapabilities
+	if slices.Contains(cfg.Features, config.LanguageServerFeatureFormatting) {
+		formatting = &protocol.DocumentFormattingClientCapabilities{
+			DynamicRegistration: false,
+		}
+		rangeFormatting = &protocol.DocumentRangeFormattingClientCapabilities{
+			DynamicRegistration: false,
+		}
+	}
+
+	var codeAction *protocol.CodeActionClientCapabilities
+	if slices.Contains(cfg.Features, config.LanguageServerFeatureCodeActions) {
+		codeAction = &protocol.CodeActionClientCapabilities{
+			DynamicRegistration: false,
+			CodeActionLiteralSupport: &protocol.CodeActionClientCapabilitiesLiteralSupport{
+				CodeActionKind: &protocol.CodeActionClientCapabilitiesKind{
+					ValueSet: []protocol.CodeActionKind{
+						protocol.QuickFix,
+						protocol.Refactor,
+						protocol.RefactorExtract,
+						protocol.RefactorInline,
+						protocol.RefactorRewrite,
+						protocol.Source,
+						protocol.SourceOrganizeImports,
+					},
+				},
+			},
+			IsPreferredSupport: true,
+			DisabledSupport:    true,
+			DataSupport:        false,
+		}
+	}
+
 	return protocol.ClientCapabilities{
 		Workspace: &protocol.WorkspaceClientCapabilities{
+			ApplyEdit: true,
+			WorkspaceEdit: &protocol.WorkspaceClientCapabilitiesWorkspaceEdit{
+				DocumentChanges: true,
+			},
 			WorkspaceFolders: true,
-			InlayHint:        inlayHintWorkspace,
+			DidChangeWatchedFiles: &protocol.DidChangeWatchedFilesWorkspaceClientCapabilities{
+				DynamicRegistration: false,
+			},
+			InlayHint: inlayHintWorkspace,
+			ExecuteCommand: &protocol.ExecuteCommandClientCapabilities{
+				DynamicRegistration: false,
+			},
 		},
 		TextDocument: &protocol.TextDocumentClientCapabilities{
 			Completion:         completion,
@@ -114,6 +232,34 @@ func clientCapabilities(cfg config.LanguageServerConfig) protocol.ClientCapabili
 			InlayHint:          inlayHint,
 			Diagnostic:         diagnostic,
 			Definition:         definition,
+			Implementation:     implementation,
+			References:         references,
+			Rename:             rename,
+			CodeAction:         codeAction,
+			Hover:              hover,
+			SignatureHelp:      signatureHelp,
+			Formatting:         formatting,
+			RangeFormatting:    rangeFormatting,
 		},
 	}
 }
+
+// textDocumentSyncKind returns the change sync kind from the server capabilities which is either a TextDocumentSyncKind or TextDocumentSyncOptions.
+func textDocumentSyncKind(sync any) protocol.TextDocumentSyncKind {
+	switch s := sync.(type) {
+	case protocol.TextDocumentSyncKind:
+		return s
+	case float64:
+		return protocol.TextDocumentSyncKind(s)
+	case *protocol.TextDocumentSyncOptions:
+		return s.Change
+	case protocol.TextDocumentSyncOptions:
+		return s.Change
+	case map[string]any:
+		if change, ok := s["change"].(float64); ok {
+			return protocol.TextDocumentSyncKind(change)
+		}
+	}
+
+	return protocol.TextDocumentSyncKindNone
+}
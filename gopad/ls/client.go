@@ -121,6 +121,12 @@ func (l *Client) Update(msg tea.Msg) tea.Cmd {
 				}()
 			}
 		}
+	case WatchedFilesChangedMsg:
+		for _, server := range l.servers {
+			if server.workspace == msg.Workspace {
+				cmds = append(cmds, server.Update(msg))
+			}
+		}
 	case GetAutocompletionMsg:
 		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
 
@@ -150,6 +156,47 @@ func (l *Client) Update(msg tea.Msg) tea.Cmd {
 
 	case GetDefinitionMsg:
 		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case GetImplementationMsg:
+		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case GetReferencesMsg:
+		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case PrepareRenameMsg:
+		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case RenameMsg:
+		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case FormatMsg:
+		// only one server should format the file, fall back to external formatters if none can
+		i := slices.IndexFunc(l.SupportedServers(msg.Name), func(server *Server) bool {
+			return server.SupportsFormatting(msg.Range != nil)
+		})
+		if i == -1 {
+			cmds = append(cmds, func() tea.Msg {
+				return FormatUnsupportedMsg(msg)
+			})
+			break
+		}
+		cmds = append(cmds, l.SupportedServers(msg.Name)[i].Update(msg))
+
+	case GetSignatureHelpMsg:
+		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case GetHoverMsg:
+		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case GetCodeActionsMsg:
+		cmds = append(cmds, l.updateSupportedServers(msg.Name, msg)...)
+
+	case ExecuteCommandMsg:
+		for _, server := range l.servers {
+			if server.Name() == msg.Server {
+				cmds = append(cmds, server.Update(msg))
+			}
+		}
 	}
 
 	return tea.Batch(cmds...)
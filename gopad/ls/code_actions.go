@@ -108,3 +108,179 @@ type TypeDefinition struct {
 	Name  string
 	Range buffer.Range
 }
+
+func GetImplementation(name string, row int, col int) tea.Cmd {
+	return func() tea.Msg {
+		return GetImplementationMsg{
+			Name: name,
+			Row:  row,
+			Col:  col,
+		}
+	}
+}
+
+type GetImplementationMsg struct {
+	Name string
+	Row  int
+	Col  int
+}
+
+func UpdateImplementation(name string, implementations []Implementation) tea.Msg {
+	return UpdateImplementationMsg{
+		Name:            name,
+		Implementations: implementations,
+	}
+}
+
+type UpdateImplementationMsg struct {
+	Name            string
+	Implementations []Implementation
+}
+
+type Implementation struct {
+	Name  string
+	Range buffer.Range
+}
+
+func GetReferences(name string, row int, col int) tea.Cmd {
+	return func() tea.Msg {
+		return GetReferencesMsg{
+			Name: name,
+			Row:  row,
+			Col:  col,
+		}
+	}
+}
+
+type GetReferencesMsg struct {
+	Name string
+	Row  int
+	Col  int
+}
+
+func UpdateReferences(name string, references []SymbolReference) tea.Msg {
+	return UpdateReferencesMsg{
+		Name:       name,
+		References: references,
+	}
+}
+
+type UpdateReferencesMsg struct {
+	Name       string
+	References []SymbolReference
+}
+
+type SymbolReference struct {
+	Name  string
+	Range buffer.Range
+}
+
+func PrepareRename(name string, row int, col int) tea.Cmd {
+	return func() tea.Msg {
+		return PrepareRenameMsg{
+			Name: name,
+			Row:  row,
+			Col:  col,
+		}
+	}
+}
+
+type PrepareRenameMsg struct {
+	Name string
+	Row  int
+	Col  int
+}
+
+func UpdatePrepareRename(name string, row int, col int, r *buffer.Range) tea.Msg {
+	return UpdatePrepareRenameMsg{
+		Name:  name,
+		Row:   row,
+		Col:   col,
+		Range: r,
+	}
+}
+
+type UpdatePrepareRenameMsg struct {
+	Name string
+	Row  int
+	Col  int
+	// Range is the range of the symbol to rename. It is nil if the symbol can't be renamed.
+	Range *buffer.Range
+}
+
+func Rename(name string, row int, col int, newName string) tea.Cmd {
+	return func() tea.Msg {
+		return RenameMsg{
+			Name:    name,
+			Row:     row,
+			Col:     col,
+			NewName: newName,
+		}
+	}
+}
+
+type RenameMsg struct {
+	Name    string
+	Row     int
+	Col     int
+	NewName string
+}
+
+func GetCodeActions(name string, r buffer.Range, diagnostics []Diagnostic) tea.Cmd {
+	return func() tea.Msg {
+		return GetCodeActionsMsg{
+			Name:        name,
+			Range:       r,
+			Diagnostics: diagnostics,
+		}
+	}
+}
+
+type GetCodeActionsMsg struct {
+	Name        string
+	Range       buffer.Range
+	Diagnostics []Diagnostic
+}
+
+func UpdateCodeActions(name string, actions []CodeAction) tea.Msg {
+	return UpdateCodeActionsMsg{
+		Name:    name,
+		Actions: actions,
+	}
+}
+
+type UpdateCodeActionsMsg struct {
+	Name    string
+	Actions []CodeAction
+}
+
+type CodeAction struct {
+	// Server is the name of the language server which provided the action.
+	Server      string
+	Title       string
+	Kind        string
+	IsPreferred bool
+	Disabled    string
+	Edit        *WorkspaceEdit
+	Command     *Command
+}
+
+type Command struct {
+	Title     string
+	Command   string
+	Arguments []any
+}
+
+func ExecuteCommand(server string, command Command) tea.Cmd {
+	return func() tea.Msg {
+		return ExecuteCommandMsg{
+			Server:  server,
+			Command: command,
+		}
+	}
+}
+
+type ExecuteCommandMsg struct {
+	Server  string
+	Command Command
+}
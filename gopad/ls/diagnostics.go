@@ -2,10 +2,12 @@ package ls
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss"
+	"go.lsp.dev/protocol"
 
 	"go.gopad.dev/gopad/gopad/buffer"
 	"go.gopad.dev/gopad/gopad/config"
@@ -127,6 +129,33 @@ type Diagnostic struct {
 	Priority        int
 }
 
+func (d Diagnostic) ToProtocol() protocol.Diagnostic {
+	var code any
+	if d.Code != "" {
+		code = d.Code
+		if intCode, err := strconv.Atoi(d.Code); err == nil {
+			code = int32(intCode)
+		}
+	}
+
+	var codeDescription *protocol.CodeDescription
+	if d.CodeDescription != "" {
+		codeDescription = &protocol.CodeDescription{
+			Href: protocol.URI(d.CodeDescription),
+		}
+	}
+
+	return protocol.Diagnostic{
+		Range:           d.Range.ToProtocol(),
+		Severity:        protocol.DiagnosticSeverity(d.Severity),
+		Code:            code,
+		CodeDescription: codeDescription,
+		Source:          d.Source,
+		Message:         d.Message,
+		Data:            d.Data,
+	}
+}
+
 func (d Diagnostic) ShortView(style lipgloss.Style) string {
 	msg := strings.SplitN(d.Message, "\n", 2)[0]
 
@@ -0,0 +1,146 @@
+package ls
+
+import (
+	"go.lsp.dev/protocol"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+// positionEncodings are the position encodings offered to servers in order of preference.
+// Columns are counted in runes, so UTF-32 positions don't need to be converted.
+var positionEncodings = []buffer.PositionEncoding{
+	buffer.PositionEncodingUTF32,
+	buffer.PositionEncodingUTF8,
+	buffer.PositionEncodingUTF16,
+}
+
+// negotiatePositionEncoding returns the encoding chosen by the server, servers which don't choose one use UTF-16.
+func negotiatePositionEncoding(encoding buffer.PositionEncoding) buffer.PositionEncoding {
+	for _, e := range positionEncodings {
+		if e == encoding {
+			return e
+		}
+	}
+	return buffer.PositionEncodingUTF16
+}
+
+func (c *Server) setDocument(name string, b *buffer.Buffer) *buffer.Buffer {
+	c.documentsMu.Lock()
+	defer c.documentsMu.Unlock()
+
+	old := c.documents[name]
+	if b == nil {
+		delete(c.documents, name)
+	} else {
+		c.documents[name] = b
+	}
+	return old
+}
+
+// document returns the content of the file as last sent to the server.
+// Files which are not open are read from disk, nil is returned if they can't be read.
+// It must not be called from the update loop.
+func (c *Server) document(name string) *buffer.Buffer {
+	c.documentsMu.Lock()
+	b, ok := c.documents[name]
+	c.documentsMu.Unlock()
+	if ok {
+		return b
+	}
+	return c.readDocument(name)
+}
+
+// snapshotDocument returns a function returning the content of the file as sent to the server at the time of the call.
+// Files which are not open are read from disk when the function is called, so it can be used in the update loop.
+func (c *Server) snapshotDocument(name string) func() *buffer.Buffer {
+	c.documentsMu.Lock()
+	b, ok := c.documents[name]
+	c.documentsMu.Unlock()
+	return func() *buffer.Buffer {
+		if ok {
+			return b
+		}
+		return c.readDocument(name)
+	}
+}
+
+// readDocument reads the file from disk, nil is returned if it can't be read.
+func (c *Server) readDocument(name string) *buffer.Buffer {
+	// rune columns are used as they are
+	if c.positionEncoding == buffer.PositionEncodingUTF32 {
+		return nil
+	}
+
+	b, err := buffer.NewFromFile(name, "utf-8", buffer.LineEndingAuto)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func (c *Server) protocolPosition(b *buffer.Buffer, row int, col int) protocol.Position {
+	p := buffer.Position{Row: row, Col: col}
+	if b == nil {
+		return p.ToProtocol()
+	}
+	return b.ProtocolPosition(p, c.positionEncoding)
+}
+
+func (c *Server) protocolRange(b *buffer.Buffer, r buffer.Range) protocol.Range {
+	if b == nil {
+		return r.ToProtocol()
+	}
+	return b.ProtocolRange(r, c.positionEncoding)
+}
+
+func (c *Server) parsePosition(b *buffer.Buffer, p protocol.Position) buffer.Position {
+	if b == nil {
+		return buffer.ParsePosition(p)
+	}
+	return b.ParsePosition(p, c.positionEncoding)
+}
+
+func (c *Server) parseRange(b *buffer.Buffer, r protocol.Range) buffer.Range {
+	if b == nil {
+		return buffer.ParseRange(r)
+	}
+	return b.ParseRange(r, c.positionEncoding)
+}
+
+// parseLocations converts the ranges of the locations, every file is only read once.
+func (c *Server) parseLocations(locations []protocol.Location) []buffer.Range {
+	documents := make(map[string]*buffer.Buffer)
+	ranges := make([]buffer.Range, 0, len(locations))
+	for _, location := range locations {
+		name := location.URI.Filename()
+		b, ok := documents[name]
+		if !ok {
+			b = c.document(name)
+			documents[name] = b
+		}
+		ranges = append(ranges, c.parseRange(b, location.Range))
+	}
+	return ranges
+}
+
+// contentChanges converts the changes to incremental content changes.
+// The changes are applied to a copy of the previous content one after another, so every range is converted with the line data it refers to.
+func (c *Server) contentChanges(old *buffer.Buffer, changes []TextChange) []protocol.TextDocumentContentChangeEvent {
+	var b *buffer.Buffer
+	if old != nil && c.positionEncoding != buffer.PositionEncodingUTF32 {
+		b = old.Copy()
+	}
+
+	events := make([]protocol.TextDocumentContentChangeEvent, 0, len(changes))
+	for _, change := range changes {
+		r := c.protocolRange(b, change.Range)
+		events = append(events, protocol.TextDocumentContentChangeEvent{
+			Range: &r,
+			Text:  string(change.Text),
+		})
+		if b != nil {
+			b.Replace(change.Range.Start.Row, change.Range.Start.Col, change.Range.End.Row, change.Range.End.Col, change.Text)
+		}
+	}
+	return events
+}
@@ -0,0 +1,74 @@
+package ls
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+// FormatTimeout is the time a language server or an external formatter may take to format a file.
+const FormatTimeout = 10 * time.Second
+
+// Format requests formatting edits for the whole document or the given range.
+// If save is true the file should be saved once the edits are applied.
+func Format(name string, version int32, options FormattingOptions, r *buffer.Range, save bool) tea.Cmd {
+	return func() tea.Msg {
+		return FormatMsg{
+			Name:    name,
+			Version: version,
+			Options: options,
+			Range:   r,
+			Save:    save,
+		}
+	}
+}
+
+type FormatMsg struct {
+	Name    string
+	Version int32
+	Options FormattingOptions
+	Range   *buffer.Range
+	Save    bool
+}
+
+type FormattingOptions struct {
+	TabSize                int
+	InsertSpaces           bool
+	TrimTrailingWhitespace bool
+	InsertFinalNewline     bool
+}
+
+func UpdateFormat(name string, version int32, edits []TextEdit, err error, save bool) tea.Msg {
+	return UpdateFormatMsg{
+		Name:    name,
+		Version: version,
+		Edits:   edits,
+		Err:     err,
+		Save:    save,
+	}
+}
+
+type UpdateFormatMsg struct {
+	Name    string
+	Version int32
+	Edits   []TextEdit
+	// Err is set if the file could not be formatted, it is still saved if Save is true.
+	Err  error
+	Save bool
+}
+
+// FormatUnsupportedMsg is sent when no language server can format the file.
+type FormatUnsupportedMsg FormatMsg
+
+// providerSupported reports whether a server capability which is either a bool or an options struct is set.
+func providerSupported(provider any) bool {
+	switch p := provider.(type) {
+	case nil:
+		return false
+	case bool:
+		return p
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package ls
+
+import (
+	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func GetHover(name string, row int, col int) tea.Cmd {
+	return func() tea.Msg {
+		return GetHoverMsg{
+			Name: name,
+			Row:  row,
+			Col:  col,
+		}
+	}
+}
+
+type GetHoverMsg struct {
+	Name string
+	Row  int
+	Col  int
+}
+
+func UpdateHover(name string, row int, col int, hover Hover) tea.Msg {
+	return UpdateHoverMsg{
+		Name:  name,
+		Row:   row,
+		Col:   col,
+		Hover: hover,
+	}
+}
+
+type UpdateHoverMsg struct {
+	Name  string
+	Row   int
+	Col   int
+	Hover Hover
+}
+
+type MarkupKind string
+
+const (
+	MarkupKindPlainText MarkupKind = "plaintext"
+	MarkupKindMarkdown  MarkupKind = "markdown"
+)
+
+type Hover struct {
+	Kind     MarkupKind
+	Contents string
+	Range    *buffer.Range
+}
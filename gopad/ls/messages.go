@@ -2,6 +2,8 @@ package ls
 
 import (
 	"github.com/charmbracelet/bubbletea/v2"
+
+	"go.gopad.dev/gopad/gopad/buffer"
 )
 
 func Err(err error) tea.Cmd {
@@ -34,6 +36,33 @@ type WorkspaceClosedMsg struct {
 	Workspace string
 }
 
+func WatchedFilesChanged(workspace string, events []FileEvent) tea.Cmd {
+	return func() tea.Msg {
+		return WatchedFilesChangedMsg{
+			Workspace: workspace,
+			Events:    events,
+		}
+	}
+}
+
+type WatchedFilesChangedMsg struct {
+	Workspace string
+	Events    []FileEvent
+}
+
+type FileEventType int
+
+const (
+	FileEventCreated FileEventType = iota + 1
+	FileEventChanged
+	FileEventDeleted
+)
+
+type FileEvent struct {
+	Name string
+	Type FileEventType
+}
+
 func FileCreated(name string, text []byte) tea.Cmd {
 	return func() tea.Msg {
 		return FileCreatedMsg{
@@ -48,20 +77,23 @@ type FileCreatedMsg struct {
 	Text []byte
 }
 
-func FileOpened(name string, version int32, text []byte) tea.Cmd {
+func FileOpened(name string, languageID string, version int32, b *buffer.Buffer) tea.Cmd {
 	return func() tea.Msg {
 		return FileOpenedMsg{
-			Name:    name,
-			Version: version,
-			Text:    text,
+			Name:       name,
+			LanguageID: languageID,
+			Version:    version,
+			Buffer:     b,
 		}
 	}
 }
 
 type FileOpenedMsg struct {
-	Name    string
-	Version int32
-	Text    []byte
+	Name       string
+	LanguageID string
+	Version    int32
+	// Buffer is a snapshot of the file content.
+	Buffer *buffer.Buffer
 }
 
 func FileClosed(name string) tea.Cmd {
@@ -76,20 +108,32 @@ type FileClosedMsg struct {
 	Name string
 }
 
-func FileChanged(name string, version int32, text []byte) tea.Cmd {
+func FileChanged(name string, prevVersion int32, version int32, b *buffer.Buffer, changes []TextChange) tea.Cmd {
 	return func() tea.Msg {
 		return FileChangedMsg{
-			Name:    name,
-			Version: version,
-			Text:    text,
+			Name:        name,
+			PrevVersion: prevVersion,
+			Version:     version,
+			Buffer:      b,
+			Changes:     changes,
 		}
 	}
 }
 
 type FileChangedMsg struct {
-	Name    string
-	Version int32
-	Text    []byte
+	Name string
+	// PrevVersion is the version the changes were made on.
+	PrevVersion int32
+	Version     int32
+	// Buffer is a snapshot of the file after all changes.
+	Buffer *buffer.Buffer
+	// Changes are the incremental edits in the order they were applied.
+	Changes []TextChange
+}
+
+type TextChange struct {
+	Range buffer.Range
+	Text  []byte
 }
 
 func FileSaved(name string, text []byte) tea.Cmd {
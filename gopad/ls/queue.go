@@ -0,0 +1,75 @@
+package ls
+
+import (
+	"sync"
+)
+
+func newNotificationQueue() *notificationQueue {
+	return &notificationQueue{
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+}
+
+// notificationQueue sends notifications one after another in the order they were pushed.
+// Pushing never blocks, so it can be used from the update loop.
+type notificationQueue struct {
+	mu      sync.Mutex
+	pending []func() error
+	wake    chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+func (q *notificationQueue) push(notify func() error) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.pending = append(q.pending, notify)
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the pushed notifications until the queue is closed, errors are passed to onErr.
+func (q *notificationQueue) run(onErr func(err error)) {
+	for {
+		select {
+		case <-q.wake:
+		case <-q.done:
+			return
+		}
+
+		for {
+			q.mu.Lock()
+			if len(q.pending) == 0 {
+				q.mu.Unlock()
+				break
+			}
+			notify := q.pending[0]
+			q.pending = q.pending[1:]
+			q.mu.Unlock()
+
+			if err := notify(); err != nil {
+				onErr(err)
+			}
+		}
+	}
+}
+
+// close stops run, notifications which were not sent yet are dropped.
+func (q *notificationQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	q.pending = nil
+	close(q.done)
+}
@@ -9,9 +9,11 @@ import (
 	"path/filepath"
 	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbletea/v2"
+	"go.lsp.dev/jsonrpc2"
 	"go.lsp.dev/protocol"
 
 	"go.gopad.dev/gopad/gopad/buffer"
@@ -51,11 +53,17 @@ func newServer(name string, send SendFunc, workspace string, version string, cfg
 		send:      send,
 		cfg:       cfg,
 		w:         w,
+		documents: make(map[string]*buffer.Buffer),
+		versions:  make(map[string]int32),
+		queue:     newNotificationQueue(),
 	}
 
 	if err := c.start(); err != nil {
 		return nil, fmt.Errorf("error starting client: %w", err)
 	}
+	go c.queue.run(func(err error) {
+		c.send(Err(err))
+	})
 
 	return c, nil
 }
@@ -67,10 +75,28 @@ type Server struct {
 
 	send   SendFunc
 	cfg    config.LanguageServerConfig
+	conn   jsonrpc2.Conn
 	server protocol.Server
 	cmd    *exec.Cmd
 	rwc    io.ReadWriteCloser
 	w      io.Writer
+
+	syncKind         protocol.TextDocumentSyncKind
+	positionEncoding buffer.PositionEncoding
+
+	// documents holds the content of the open files as last sent to the server, it is used to convert positions
+	documentsMu sync.Mutex
+	documents   map[string]*buffer.Buffer
+	// versions holds the version of the open files as last sent to the server
+	versions map[string]int32
+	// queue sends the notifications about open files in the order they were made
+	queue *notificationQueue
+
+	signatureHelpTriggers   []string
+	signatureHelpRetriggers []string
+
+	formatting      bool
+	rangeFormatting bool
 }
 
 func (c *Server) Name() string {
@@ -81,6 +107,17 @@ func (c *Server) SupportedFile(name string) bool {
 	return slices.Contains(c.cfg.FileTypes, filepath.Ext(name)) || slices.Contains(c.cfg.Files, filepath.Base(name))
 }
 
+// SupportsFormatting reports whether the server can format a whole document or a range of it.
+func (c *Server) SupportsFormatting(rangeFormatting bool) bool {
+	if !slices.Contains(c.cfg.Features, config.LanguageServerFeatureFormatting) {
+		return false
+	}
+	if rangeFormatting {
+		return c.rangeFormatting
+	}
+	return c.formatting
+}
+
 func (c *Server) start() error {
 	var err error
 	c.cmd, c.rwc, err = newServerCmdStream(context.Background(), c.w, c.cfg.Command, c.cfg.Args...)
@@ -88,7 +125,7 @@ func (c *Server) start() error {
 		return fmt.Errorf("error creating command stream: %w", err)
 	}
 
-	_, c.server, err = newServerConn(context.Background(), c.rwc, c, c.w)
+	c.conn, c.server, err = newServerConn(context.Background(), c.rwc, c, c.w)
 	if err != nil {
 		return fmt.Errorf("error creating server: %w", err)
 	}
@@ -103,18 +140,34 @@ func (c *Server) start() error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	if _, err = c.server.Initialize(ctx, &protocol.InitializeParams{
-		ClientInfo: &protocol.ClientInfo{
-			Name:    c.name,
-			Version: c.version,
+	var result initializeResult
+	if err = protocol.Call(ctx, c.conn, protocol.MethodInitialize, &initializeParams{
+		InitializeParams: protocol.InitializeParams{
+			ClientInfo: &protocol.ClientInfo{
+				Name:    c.name,
+				Version: c.version,
+			},
+			Locale:                "de",
+			InitializationOptions: c.cfg.Config,
+			WorkspaceFolders:      workspaceFolders,
+		},
+		Capabilities: initializeClientCapabilities{
+			ClientCapabilities: clientCapabilities(c.cfg),
+			General: initializeGeneralCapabilities{
+				PositionEncodings: positionEncodings,
+			},
 		},
-		Locale:                "de",
-		InitializationOptions: c.cfg.Config,
-		WorkspaceFolders:      workspaceFolders,
-		Capabilities:          clientCapabilities(c.cfg),
-	}); err != nil {
+	}, &result); err != nil {
 		return fmt.Errorf("error initializing server: %w", err)
 	}
+	c.positionEncoding = negotiatePositionEncoding(result.Capabilities.PositionEncoding)
+	c.syncKind = textDocumentSyncKind(result.Capabilities.TextDocumentSync)
+	c.formatting = providerSupported(result.Capabilities.DocumentFormattingProvider)
+	c.rangeFormatting = providerSupported(result.Capabilities.DocumentRangeFormattingProvider)
+	if provider := result.Capabilities.SignatureHelpProvider; provider != nil {
+		c.signatureHelpTriggers = provider.TriggerCharacters
+		c.signatureHelpRetriggers = provider.RetriggerCharacters
+	}
 
 	ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel2()
@@ -126,6 +179,8 @@ func (c *Server) start() error {
 }
 
 func (c *Server) Stop(ctx context.Context) error {
+	c.queue.close()
+
 	if err := c.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("error sending shutdown: %w", err)
 	}
@@ -188,38 +243,358 @@ func (c *Server) Update(msg tea.Msg) tea.Cmd {
 
 	switch msg := msg.(type) {
 	case GetDefinitionMsg:
+		document := c.snapshotDocument(msg.Name)
 		return func() tea.Msg {
+			b := document()
 			locations, err := c.server.Definition(context.Background(), &protocol.DefinitionParams{
 				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
 					TextDocument: protocol.TextDocumentIdentifier{
 						URI: protocol.DocumentURI("file://" + msg.Name),
 					},
-					Position: protocol.Position{
-						Line:      uint32(msg.Row),
-						Character: uint32(msg.Col),
-					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
 				},
 			})
 			if err != nil {
 				return err
 			}
 
+			ranges := c.parseLocations(locations)
 			definitions := make([]Definition, 0, len(locations))
-			for _, location := range locations {
+			for i, location := range locations {
 				definitions = append(definitions, Definition{
 					Name:  location.URI.Filename(),
-					Range: buffer.ParseRange(location.Range),
+					Range: ranges[i],
 				})
 			}
 			return UpdateDefinition(msg.Name, definitions)
 		}
+	case GetImplementationMsg:
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			locations, err := c.server.Implementation(context.Background(), &protocol.ImplementationParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{
+						URI: protocol.DocumentURI("file://" + msg.Name),
+					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			ranges := c.parseLocations(locations)
+			implementations := make([]Implementation, 0, len(locations))
+			for i, location := range locations {
+				implementations = append(implementations, Implementation{
+					Name:  location.URI.Filename(),
+					Range: ranges[i],
+				})
+			}
+			return UpdateImplementation(msg.Name, implementations)
+		}
+	case GetReferencesMsg:
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			locations, err := c.server.References(context.Background(), &protocol.ReferenceParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{
+						URI: protocol.DocumentURI("file://" + msg.Name),
+					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
+				},
+				Context: protocol.ReferenceContext{
+					IncludeDeclaration: true,
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			ranges := c.parseLocations(locations)
+			references := make([]SymbolReference, 0, len(locations))
+			for i, location := range locations {
+				references = append(references, SymbolReference{
+					Name:  location.URI.Filename(),
+					Range: ranges[i],
+				})
+			}
+			return UpdateReferences(msg.Name, references)
+		}
+	case PrepareRenameMsg:
+		if !slices.Contains(c.cfg.Features, config.LanguageServerFeatureRename) {
+			return nil
+		}
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			result, err := c.server.PrepareRename(context.Background(), &protocol.PrepareRenameParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{
+						URI: protocol.DocumentURI("file://" + msg.Name),
+					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
+				},
+			})
+			if err != nil {
+				return notifications.Addf("error preparing rename: %s", err)()
+			}
+
+			var r *buffer.Range
+			if result != nil {
+				parsed := c.parseRange(b, *result)
+				r = &parsed
+			}
+			return UpdatePrepareRename(msg.Name, msg.Row, msg.Col, r)
+		}
+	case RenameMsg:
+		if !slices.Contains(c.cfg.Features, config.LanguageServerFeatureRename) {
+			return nil
+		}
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			result, err := c.server.Rename(context.Background(), &protocol.RenameParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{
+						URI: protocol.DocumentURI("file://" + msg.Name),
+					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
+				},
+				NewName: msg.NewName,
+			})
+			if err != nil {
+				return notifications.Addf("error renaming symbol: %s", err)()
+			}
+			if result == nil {
+				return nil
+			}
+
+			return ApplyWorkspaceEdit(c.parseWorkspaceEdit("Rename to "+msg.NewName, *result), nil)()
+		}
+	case GetCodeActionsMsg:
+		if !slices.Contains(c.cfg.Features, config.LanguageServerFeatureCodeActions) {
+			return nil
+		}
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			var diagnostics []protocol.Diagnostic
+			for _, diagnostic := range msg.Diagnostics {
+				if diagnostic.Type != DiagnosticTypeLanguageServer || diagnostic.Name != c.Name() {
+					continue
+				}
+				d := diagnostic.ToProtocol()
+				d.Range = c.protocolRange(b, diagnostic.Range)
+				diagnostics = append(diagnostics, d)
+			}
+
+			result, err := c.server.CodeAction(context.Background(), &protocol.CodeActionParams{
+				TextDocument: protocol.TextDocumentIdentifier{
+					URI: protocol.DocumentURI("file://" + msg.Name),
+				},
+				Context: protocol.CodeActionContext{
+					Diagnostics: diagnostics,
+				},
+				Range: c.protocolRange(b, msg.Range),
+			})
+			if err != nil {
+				return notifications.Addf("error getting code actions: %s", err)()
+			}
+
+			actions := make([]CodeAction, 0, len(result))
+			for _, action := range result {
+				codeAction := CodeAction{
+					Server:      c.Name(),
+					Title:       action.Title,
+					Kind:        string(action.Kind),
+					IsPreferred: action.IsPreferred,
+				}
+				if action.Disabled != nil {
+					codeAction.Disabled = action.Disabled.Reason
+				}
+				if action.Edit != nil {
+					edit := c.parseWorkspaceEdit(action.Title, *action.Edit)
+					codeAction.Edit = &edit
+				}
+				if action.Command != nil {
+					codeAction.Command = &Command{
+						Title:     action.Command.Title,
+						Command:   action.Command.Command,
+						Arguments: action.Command.Arguments,
+					}
+				}
+				actions = append(actions, codeAction)
+			}
+			return UpdateCodeActions(msg.Name, actions)
+		}
+	case ExecuteCommandMsg:
+		return func() tea.Msg {
+			_, err := c.server.ExecuteCommand(context.Background(), &protocol.ExecuteCommandParams{
+				Command:   msg.Command.Command,
+				Arguments: msg.Command.Arguments,
+			})
+			if err != nil {
+				return notifications.Addf("error executing command %s: %s", msg.Command.Title, err)()
+			}
+			return nil
+		}
+	case GetHoverMsg:
+		if !slices.Contains(c.cfg.Features, config.LanguageServerFeatureHover) {
+			return nil
+		}
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			result, err := c.server.Hover(context.Background(), &protocol.HoverParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{
+						URI: protocol.DocumentURI("file://" + msg.Name),
+					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if result == nil {
+				return UpdateHover(msg.Name, msg.Row, msg.Col, Hover{})
+			}
+
+			var r *buffer.Range
+			if result.Range != nil {
+				parsed := c.parseRange(b, *result.Range)
+				r = &parsed
+			}
+			return UpdateHover(msg.Name, msg.Row, msg.Col, Hover{
+				Kind:     MarkupKind(result.Contents.Kind),
+				Contents: result.Contents.Value,
+				Range:    r,
+			})
+		}
+	case GetSignatureHelpMsg:
+		if !slices.Contains(c.cfg.Features, config.LanguageServerFeatureSignatureHelp) {
+			return nil
+		}
+
+		var triggerKind protocol.SignatureHelpTriggerKind
+		switch {
+		case slices.Contains(c.signatureHelpTriggers, msg.Trigger):
+			triggerKind = protocol.SignatureHelpTriggerKindTriggerCharacter
+		case msg.Active && slices.Contains(c.signatureHelpRetriggers, msg.Trigger):
+			triggerKind = protocol.SignatureHelpTriggerKindTriggerCharacter
+		case msg.Active:
+			triggerKind = protocol.SignatureHelpTriggerKindContentChange
+		default:
+			return nil
+		}
+
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			var trigger string
+			if triggerKind == protocol.SignatureHelpTriggerKindTriggerCharacter {
+				trigger = msg.Trigger
+			}
+
+			var result *signatureHelp
+			if err := protocol.Call(context.Background(), c.conn, protocol.MethodTextDocumentSignatureHelp, &protocol.SignatureHelpParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{
+						URI: protocol.DocumentURI("file://" + msg.Name),
+					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
+				},
+				Context: &protocol.SignatureHelpContext{
+					TriggerKind:      triggerKind,
+					TriggerCharacter: trigger,
+					IsRetrigger:      msg.Active,
+				},
+			}, &result); err != nil {
+				return err
+			}
+			if result == nil {
+				return UpdateSignatureHelp(msg.Name, SignatureHelp{})
+			}
+
+			signatures := make([]Signature, 0, len(result.Signatures))
+			for _, signature := range result.Signatures {
+				parameters := make([]string, 0, len(signature.Parameters))
+				for _, parameter := range signature.Parameters {
+					parameters = append(parameters, parameter.Label)
+				}
+
+				activeParameter := int(result.ActiveParameter)
+				if signature.ActiveParameter != nil {
+					activeParameter = int(*signature.ActiveParameter)
+				}
+
+				signatures = append(signatures, Signature{
+					Label:           signature.Label,
+					Documentation:   markupString(signature.Documentation),
+					Parameters:      parameters,
+					ActiveParameter: activeParameter,
+				})
+			}
+
+			return UpdateSignatureHelp(msg.Name, SignatureHelp{
+				Signatures:      signatures,
+				ActiveSignature: int(result.ActiveSignature),
+			})
+		}
+	case FormatMsg:
+		if !c.SupportsFormatting(msg.Range != nil) {
+			return nil
+		}
+		document := c.snapshotDocument(msg.Name)
+		return func() tea.Msg {
+			b := document()
+			textDocument := protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentURI("file://" + msg.Name),
+			}
+			options := protocol.FormattingOptions{
+				InsertSpaces:           msg.Options.InsertSpaces,
+				TabSize:                uint32(msg.Options.TabSize),
+				TrimTrailingWhitespace: msg.Options.TrimTrailingWhitespace,
+				InsertFinalNewline:     msg.Options.InsertFinalNewline,
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), FormatTimeout)
+			defer cancel()
+
+			var (
+				result []protocol.TextEdit
+				err    error
+			)
+			if msg.Range != nil {
+				result, err = c.server.RangeFormatting(ctx, &protocol.DocumentRangeFormattingParams{
+					TextDocument: textDocument,
+					Range:        c.protocolRange(b, *msg.Range),
+					Options:      options,
+				})
+			} else {
+				result, err = c.server.Formatting(ctx, &protocol.DocumentFormattingParams{
+					TextDocument: textDocument,
+					Options:      options,
+				})
+			}
+			if err != nil {
+				return UpdateFormat(msg.Name, msg.Version, nil, err, msg.Save)
+			}
+
+			return UpdateFormat(msg.Name, msg.Version, c.parseTextEdits(b, result), nil, msg.Save)
+		}
 	case GetInlayHintMsg:
+		document := c.snapshotDocument(msg.Name)
 		return func() tea.Msg {
+			b := document()
 			result, err := c.server.InlayHint(context.Background(), &protocol.InlayHintParams{
 				TextDocument: protocol.TextDocumentIdentifier{
 					URI: protocol.DocumentURI("file://" + msg.Name),
 				},
-				Range: msg.Range.ToProtocol(),
+				Range: c.protocolRange(b, msg.Range),
 			})
 			if err != nil {
 				return err
@@ -251,7 +626,7 @@ func (c *Server) Update(msg tea.Msg) tea.Cmd {
 				}
 				hints = append(hints, InlayHint{
 					Type:         kind,
-					Position:     buffer.ParsePosition(hint.Position),
+					Position:     c.parsePosition(b, hint.Position),
 					Label:        label,
 					Tooltip:      tooltip,
 					PaddingLeft:  hint.PaddingLeft,
@@ -261,16 +636,15 @@ func (c *Server) Update(msg tea.Msg) tea.Cmd {
 			return UpdateInlayHint(msg.Name, msg.Version, hints)
 		}
 	case GetAutocompletionMsg:
+		document := c.snapshotDocument(msg.Name)
 		return func() tea.Msg {
+			b := document()
 			result, err := c.server.Completion(context.Background(), &protocol.CompletionParams{
 				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
 					TextDocument: protocol.TextDocumentIdentifier{
 						URI: protocol.DocumentURI("file://" + msg.Name),
 					},
-					Position: protocol.Position{
-						Line:      uint32(msg.Row),
-						Character: uint32(msg.Col),
-					},
+					Position: c.protocolPosition(b, msg.Row, msg.Col),
 				},
 			})
 			if err != nil {
@@ -289,7 +663,7 @@ func (c *Server) Update(msg tea.Msg) tea.Cmd {
 
 				if resultItem.TextEdit != nil {
 					item.Edit = &TextEdit{
-						Range:   buffer.ParseRange(resultItem.TextEdit.Range),
+						Range:   c.parseRange(b, resultItem.TextEdit.Range),
 						NewText: resultItem.TextEdit.NewText,
 					}
 				}
@@ -299,32 +673,30 @@ func (c *Server) Update(msg tea.Msg) tea.Cmd {
 			return UpdateAutocompletion(msg.Name, items)
 		}
 	case FileOpenedMsg:
-		return func() tea.Msg {
-			if err := c.server.DidOpen(context.Background(), &protocol.DidOpenTextDocumentParams{
+		c.setDocument(msg.Name, msg.Buffer)
+		c.versions[msg.Name] = msg.Version
+		c.queue.push(func() error {
+			return c.server.DidOpen(context.Background(), &protocol.DidOpenTextDocumentParams{
 				TextDocument: protocol.TextDocumentItem{
 					URI:        protocol.DocumentURI("file://" + msg.Name),
-					LanguageID: protocol.GoLanguage,
+					LanguageID: protocol.LanguageIdentifier(msg.LanguageID),
 					Version:    msg.Version,
-					Text:       string(msg.Text),
+					Text:       string(msg.Buffer.Bytes()),
 				},
-			}); err != nil {
-				return err
-			}
-
-			return nil
-		}
+			})
+		})
+		return nil
 	case FileClosedMsg:
-		return func() tea.Msg {
-			if err := c.server.DidClose(context.Background(), &protocol.DidCloseTextDocumentParams{
+		c.setDocument(msg.Name, nil)
+		delete(c.versions, msg.Name)
+		c.queue.push(func() error {
+			return c.server.DidClose(context.Background(), &protocol.DidCloseTextDocumentParams{
 				TextDocument: protocol.TextDocumentIdentifier{
 					URI: protocol.DocumentURI("file://" + msg.Name),
 				},
-			}); err != nil {
-				return err
-			}
-
-			return nil
-		}
+			})
+		})
+		return nil
 	case FileCreatedMsg:
 		return func() tea.Msg {
 			if err := c.server.DidCreateFiles(context.Background(), &protocol.CreateFilesParams{
@@ -354,53 +726,86 @@ func (c *Server) Update(msg tea.Msg) tea.Cmd {
 			return nil
 		}
 	case FileRenamedMsg:
-		return func() tea.Msg {
-			if err := c.server.DidRenameFiles(context.Background(), &protocol.RenameFilesParams{
+		c.setDocument(msg.NewName, c.setDocument(msg.OldName, nil))
+		if version, ok := c.versions[msg.OldName]; ok {
+			delete(c.versions, msg.OldName)
+			c.versions[msg.NewName] = version
+		}
+		c.queue.push(func() error {
+			return c.server.DidRenameFiles(context.Background(), &protocol.RenameFilesParams{
 				Files: []protocol.FileRename{
 					{
 						OldURI: "file://" + msg.OldName,
 						NewURI: "file://" + msg.NewName,
 					},
 				},
-			}); err != nil {
-				return err
-			}
+			})
+		})
+		return nil
+	case FileChangedMsg:
+		if c.syncKind == protocol.TextDocumentSyncKindNone {
+			return nil
+		}
 
+		// messages are delivered from concurrent commands, changes to closed files and changes older than the last one sent are dropped
+		version, ok := c.versions[msg.Name]
+		if !ok || msg.Version <= version {
 			return nil
 		}
-	case FileChangedMsg:
-		return func() tea.Msg {
-			if err := c.server.DidChange(context.Background(), &protocol.DidChangeTextDocumentParams{
+		c.versions[msg.Name] = msg.Version
+
+		old := c.setDocument(msg.Name, msg.Buffer)
+		var changes []protocol.TextDocumentContentChangeEvent
+		// incremental changes only apply to the version they were made on, otherwise the whole content is sent
+		if c.syncKind == protocol.TextDocumentSyncKindIncremental && len(msg.Changes) > 0 && msg.PrevVersion == version {
+			changes = c.contentChanges(old, msg.Changes)
+		} else {
+			changes = []protocol.TextDocumentContentChangeEvent{
+				{
+					Text: string(msg.Buffer.Bytes()),
+				},
+			}
+		}
+
+		c.queue.push(func() error {
+			return c.server.DidChange(context.Background(), &protocol.DidChangeTextDocumentParams{
 				TextDocument: protocol.VersionedTextDocumentIdentifier{
 					TextDocumentIdentifier: protocol.TextDocumentIdentifier{
 						URI: protocol.DocumentURI("file://" + msg.Name),
 					},
 					Version: msg.Version,
 				},
-				ContentChanges: []protocol.TextDocumentContentChangeEvent{
-					{
-						Text: string(msg.Text),
-					},
-				},
+				ContentChanges: changes,
+			})
+		})
+		return nil
+	case WatchedFilesChangedMsg:
+		changes := make([]*protocol.FileEvent, 0, len(msg.Events))
+		for _, event := range msg.Events {
+			changes = append(changes, &protocol.FileEvent{
+				Type: protocol.FileChangeType(event.Type),
+				URI:  protocol.URI("file://" + event.Name),
+			})
+		}
+		return func() tea.Msg {
+			if err := c.server.DidChangeWatchedFiles(context.Background(), &protocol.DidChangeWatchedFilesParams{
+				Changes: changes,
 			}); err != nil {
-				return err
+				return Err(err)
 			}
 
 			return nil
 		}
 	case FileSavedMsg:
-		return func() tea.Msg {
-			if err := c.server.DidSave(context.Background(), &protocol.DidSaveTextDocumentParams{
+		c.queue.push(func() error {
+			return c.server.DidSave(context.Background(), &protocol.DidSaveTextDocumentParams{
 				Text: string(msg.Text),
 				TextDocument: protocol.TextDocumentIdentifier{
 					URI: protocol.DocumentURI("file://" + msg.Name),
 				},
-			}); err != nil {
-				return Err(err)
-			}
-
-			return nil
-		}
+			})
+		})
+		return nil
 	}
 
 	return nil
@@ -423,6 +828,7 @@ func (c *Server) LogMessage(ctx context.Context, params *protocol.LogMessagePara
 }
 
 func (c *Server) PublishDiagnostics(ctx context.Context, params *protocol.PublishDiagnosticsParams) error {
+	b := c.document(params.URI.Filename())
 	diagnostics := make([]Diagnostic, 0, len(params.Diagnostics))
 	for _, diagnostic := range params.Diagnostics {
 		var code string
@@ -442,7 +848,7 @@ func (c *Server) PublishDiagnostics(ctx context.Context, params *protocol.Publis
 			Type:            DiagnosticTypeLanguageServer,
 			Name:            c.Name(),
 			Source:          diagnostic.Source,
-			Range:           buffer.ParseRange(diagnostic.Range),
+			Range:           c.parseRange(b, diagnostic.Range),
 			Severity:        DiagnosticSeverity(diagnostic.Severity),
 			Code:            code,
 			CodeDescription: codeDescription,
@@ -477,7 +883,23 @@ func (c *Server) UnregisterCapability(ctx context.Context, params *protocol.Unre
 }
 
 func (c *Server) ApplyEdit(ctx context.Context, params *protocol.ApplyWorkspaceEditParams) (*protocol.ApplyWorkspaceEditResponse, error) {
-	return nil, nil
+	result := make(chan error, 1)
+	c.send(ApplyWorkspaceEdit(c.parseWorkspaceEdit(params.Label, params.Edit), result))
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return &protocol.ApplyWorkspaceEditResponse{
+				Applied:       false,
+				FailureReason: err.Error(),
+			}, nil
+		}
+		return &protocol.ApplyWorkspaceEditResponse{
+			Applied: true,
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 func (c *Server) Configuration(ctx context.Context, params *protocol.ConfigurationParams) ([]any, error) {
@@ -0,0 +1,82 @@
+package ls
+
+import (
+	"github.com/charmbracelet/bubbletea/v2"
+	"go.lsp.dev/protocol"
+)
+
+// GetSignatureHelp requests the signature help for the given position.
+// The trigger is the typed character which caused the request or empty if the content changed in any other way.
+// Active reports whether the signature help is currently shown.
+func GetSignatureHelp(name string, row int, col int, trigger string, active bool) tea.Cmd {
+	return func() tea.Msg {
+		return GetSignatureHelpMsg{
+			Name:    name,
+			Row:     row,
+			Col:     col,
+			Trigger: trigger,
+			Active:  active,
+		}
+	}
+}
+
+type GetSignatureHelpMsg struct {
+	Name    string
+	Row     int
+	Col     int
+	Trigger string
+	Active  bool
+}
+
+func UpdateSignatureHelp(name string, help SignatureHelp) tea.Msg {
+	return UpdateSignatureHelpMsg{
+		Name: name,
+		Help: help,
+	}
+}
+
+type UpdateSignatureHelpMsg struct {
+	Name string
+	Help SignatureHelp
+}
+
+// signatureHelp is the result of a signature help request.
+// The active parameter of a signature is optional, so an active parameter of 0 can be told apart from none.
+type signatureHelp struct {
+	protocol.SignatureHelp
+	Signatures []signatureInformation `json:"signatures"`
+}
+
+type signatureInformation struct {
+	protocol.SignatureInformation
+	ActiveParameter *uint32 `json:"activeParameter,omitempty"`
+}
+
+type SignatureHelp struct {
+	Signatures      []Signature
+	ActiveSignature int
+}
+
+type Signature struct {
+	Label           string
+	Documentation   string
+	Parameters      []string
+	ActiveParameter int
+}
+
+// markupString returns the value of a string or MarkupContent.
+func markupString(v any) string {
+	switch m := v.(type) {
+	case string:
+		return m
+	case protocol.MarkupContent:
+		return m.Value
+	case *protocol.MarkupContent:
+		return m.Value
+	case map[string]any:
+		if value, ok := m["value"].(string); ok {
+			return value
+		}
+	}
+	return ""
+}
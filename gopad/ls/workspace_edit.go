@@ -0,0 +1,79 @@
+package ls
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/v2"
+	"go.lsp.dev/protocol"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+)
+
+func ApplyWorkspaceEdit(edit WorkspaceEdit, result chan<- error) tea.Cmd {
+	return func() tea.Msg {
+		return ApplyWorkspaceEditMsg{
+			Edit:   edit,
+			Result: result,
+		}
+	}
+}
+
+type ApplyWorkspaceEditMsg struct {
+	Edit WorkspaceEdit
+	// Result receives the outcome of applying the edit if it is not nil.
+	Result chan<- error
+}
+
+type WorkspaceEdit struct {
+	Label string
+	Files []FileEdit
+}
+
+type FileEdit struct {
+	Name string
+	// Version is the version of the open file the edits were made for, nil if they were made for the file on disk.
+	Version *int32
+	Edits   []TextEdit
+}
+
+func (c *Server) parseWorkspaceEdit(label string, edit protocol.WorkspaceEdit) WorkspaceEdit {
+	var files []FileEdit
+	for _, change := range edit.DocumentChanges {
+		name := change.TextDocument.URI.Filename()
+		files = append(files, FileEdit{
+			Name:    name,
+			Version: change.TextDocument.Version,
+			Edits:   c.parseTextEdits(c.document(name), change.Edits),
+		})
+	}
+
+	var changes []FileEdit
+	for uri, edits := range edit.Changes {
+		name := uri.Filename()
+		changes = append(changes, FileEdit{
+			Name:  name,
+			Edits: c.parseTextEdits(c.document(name), edits),
+		})
+	}
+	slices.SortFunc(changes, func(a, b FileEdit) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	files = append(files, changes...)
+
+	return WorkspaceEdit{
+		Label: label,
+		Files: files,
+	}
+}
+
+func (c *Server) parseTextEdits(b *buffer.Buffer, edits []protocol.TextEdit) []TextEdit {
+	textEdits := make([]TextEdit, 0, len(edits))
+	for _, edit := range edits {
+		textEdits = append(textEdits, TextEdit{
+			Range:   c.parseRange(b, edit.Range),
+			NewText: edit.NewText,
+		})
+	}
+	return textEdits
+}
@@ -22,7 +22,7 @@ type quitMsg struct{}
 
 func NewQuitOverlay() QuitOverlay {
 	bOK := config.NewButton("OK", func() tea.Cmd {
-		return tea.Quit
+		return Quit
 	})
 
 	bCancel := config.NewButton("Cancel", func() tea.Cmd {
@@ -0,0 +1,174 @@
+package recovery
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/internal/diff"
+	"go.gopad.dev/gopad/internal/xos"
+)
+
+const (
+	dirName = "recovery"
+	fileExt = ".json"
+)
+
+// File holds the unsaved content of a buffer so it can be recovered after a crash.
+// PID is the process which wrote the file, files of running processes are not recovered.
+type File struct {
+	Name       string            `json:"name"`
+	Version    int32             `json:"version"`
+	Encoding   string            `json:"encoding"`
+	LineEnding buffer.LineEnding `json:"line_ending"`
+	Time       time.Time         `json:"time"`
+	Content    []byte            `json:"content"`
+	PID        int               `json:"pid"`
+}
+
+// Buffer returns the recovered content as buffer.
+func (f File) Buffer() (*buffer.Buffer, error) {
+	return buffer.New(f.Name, bytes.NewReader(f.Content), "utf-8", buffer.LineEndingLF, false)
+}
+
+// Diff returns the line diff from the file on disk to the recovered content.
+func (f File) Diff() ([]diff.Line, error) {
+	var oldLines []string
+	b, err := buffer.NewFromFile(f.Name, f.Encoding, f.LineEnding)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if b != nil {
+		oldLines = strings.Split(string(b.Bytes()), "\n")
+	}
+
+	return diff.Lines(oldLines, strings.Split(string(f.Content), "\n")), nil
+}
+
+// Dir returns the directory the recovery files are stored in.
+func Dir() string {
+	return filepath.Join(config.Path, dirName)
+}
+
+// fileName returns the recovery file name for the absolute file name.
+func fileName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return filepath.Join(Dir(), hex.EncodeToString(sum[:16])+fileExt)
+}
+
+// Write stores the recovery file, an existing recovery file for the same file is replaced.
+// The file is owned by the current process unless its PID is set.
+func Write(f File) error {
+	if f.PID == 0 {
+		f.PID = os.Getpid()
+	}
+	if err := os.MkdirAll(Dir(), 0700); err != nil {
+		return fmt.Errorf("error creating recovery directory: %w", err)
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("error encoding recovery file: %w", err)
+	}
+
+	if err = xos.WriteFile(fileName(f.Name), 0600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return fmt.Errorf("error writing recovery file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the recovery file of the file.
+func Load(name string) (*File, error) {
+	return load(fileName(name))
+}
+
+func load(name string) (*File, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading recovery file: %w", err)
+	}
+
+	var f File
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("error decoding recovery file: %w", err)
+	}
+	return &f, nil
+}
+
+// Remove deletes the recovery file of the file if it exists.
+func Remove(name string) error {
+	if err := os.Remove(fileName(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing recovery file: %w", err)
+	}
+	return nil
+}
+
+// Pending returns the recovery files which are newer than the files on disk sorted by name.
+// Files owned by other running instances are skipped, outdated and unreadable recovery files are removed.
+func Pending() ([]File, error) {
+	entries, err := os.ReadDir(Dir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading recovery directory: %w", err)
+	}
+
+	var files []File
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+
+		name := filepath.Join(Dir(), entry.Name())
+		f, err := load(name)
+		if err != nil {
+			_ = os.Remove(name)
+			continue
+		}
+
+		if f.PID != 0 && f.PID != os.Getpid() && processRunning(f.PID) {
+			continue
+		}
+
+		if info, err := os.Stat(f.Name); err == nil && !f.Time.After(info.ModTime()) {
+			_ = os.Remove(name)
+			continue
+		}
+
+		files = append(files, *f)
+	}
+
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return files, nil
+}
+
+// processRunning reports whether a process with the pid exists.
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// signal 0 only checks whether the process exists, processes of other users can't be signaled but exist
+	err = p.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone)
+}
@@ -0,0 +1,57 @@
+package recovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/buffer"
+	"go.gopad.dev/gopad/gopad/config"
+)
+
+func TestPending(t *testing.T) {
+	dir := t.TempDir()
+	config.Path = filepath.Join(dir, "config")
+
+	outdated := filepath.Join(dir, "outdated.txt")
+	changed := filepath.Join(dir, "changed.txt")
+	created := filepath.Join(dir, "created.txt")
+	assert.NoError(t, os.WriteFile(outdated, []byte("a"), 0600))
+	assert.NoError(t, os.WriteFile(changed, []byte("a"), 0600))
+
+	now := time.Now()
+	assert.NoError(t, os.Chtimes(changed, now, now.Add(-time.Minute)))
+
+	data := []File{
+		{Name: outdated, Version: 1, Time: now.Add(-time.Hour), Content: []byte("b")},
+		{Name: changed, Version: 2, LineEnding: buffer.LineEndingLF, Time: now, Content: []byte("a\nb")},
+		{Name: created, Version: 3, Time: now, Content: []byte("c")},
+		// owned by a running process
+		{Name: filepath.Join(dir, "running.txt"), Version: 4, Time: now, Content: []byte("d"), PID: os.Getppid()},
+	}
+	for _, d := range data {
+		assert.NoError(t, Write(d))
+	}
+
+	files, err := Pending()
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2) {
+		assert.Equal(t, changed, files[0].Name)
+		assert.Equal(t, int32(2), files[0].Version)
+		assert.Equal(t, []byte("a\nb"), files[0].Content)
+		assert.Equal(t, created, files[1].Name)
+	}
+
+	// outdated recovery files are removed
+	_, err = Load(outdated)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.NoError(t, Remove(created))
+	assert.NoError(t, Remove(created))
+	files, err = Pending()
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.gopad.dev/gopad/gopad/config"
+	"go.gopad.dev/gopad/internal/xos"
+)
+
+const dirName = "sessions"
+
+// Session holds the state of a workspace which is restored when the workspace is opened again.
+type Session struct {
+	Workspace     string   `json:"workspace"`
+	Files         []File   `json:"files"`
+	ActiveFile    int      `json:"active_file"`
+	FileTree      []string `json:"file_tree"`
+	SearchHistory []string `json:"search_history"`
+	Theme         string   `json:"theme"`
+}
+
+// File holds the cursor and scroll position of an open file.
+type File struct {
+	Name      string `json:"name"`
+	Row       int    `json:"row"`
+	Col       int    `json:"col"`
+	OffsetRow int    `json:"offset_row"`
+	OffsetCol int    `json:"offset_col"`
+}
+
+// fileName returns the session file name for the workspace.
+func fileName(workspace string) string {
+	if abs, err := filepath.Abs(workspace); err == nil {
+		workspace = abs
+	}
+	sum := sha256.Sum256([]byte(workspace))
+	return filepath.Join(config.Path, dirName, hex.EncodeToString(sum[:16])+".json")
+}
+
+// Load reads the session of the workspace, it returns nil if there is no session.
+func Load(workspace string) (*Session, error) {
+	data, err := os.ReadFile(fileName(workspace))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading session: %w", err)
+	}
+
+	var s Session
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("error decoding session: %w", err)
+	}
+	return &s, nil
+}
+
+// Save writes the session of the workspace.
+func Save(s Session) error {
+	name := fileName(s.Workspace)
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return fmt.Errorf("error creating session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding session: %w", err)
+	}
+
+	if err = xos.WriteFile(name, 0600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return fmt.Errorf("error writing session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.gopad.dev/gopad/gopad/config"
+)
+
+func TestSession(t *testing.T) {
+	config.Path = t.TempDir()
+
+	s, err := Load("/workspace")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	expected := Session{
+		Workspace: "/workspace",
+		Files: []File{
+			{Name: "/workspace/a.go", Row: 10, Col: 2, OffsetRow: 5},
+			{Name: "/workspace/b.go"},
+		},
+		ActiveFile:    1,
+		FileTree:      []string{"/workspace/dir"},
+		SearchHistory: []string{"foo", "bar"},
+		Theme:         "dark",
+	}
+	assert.NoError(t, Save(expected))
+
+	s, err = Load("/workspace")
+	assert.NoError(t, err)
+	assert.Equal(t, &expected, s)
+
+	s, err = Load("/other")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+}
@@ -13,6 +13,18 @@ import (
 
 const SetThemeOverlayID = "theme"
 
+func SetTheme(name string) tea.Cmd {
+	return func() tea.Msg {
+		return setThemeMsg{
+			name: name,
+		}
+	}
+}
+
+type setThemeMsg struct {
+	name string
+}
+
 var _ overlay.Overlay = (*SetThemeOverlay)(nil)
 
 func NewSetThemeOverlay() SetThemeOverlay {
@@ -60,9 +72,7 @@ func (s SetThemeOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
 			if theme.Name == "" {
 				return s, nil
 			}
-			// TODO: set theme somehow
-			// theme := item.(config.ThemeConfig)
-			return s, tea.Batch(overlay.Close(SetThemeOverlayID))
+			return s, tea.Batch(overlay.Close(SetThemeOverlayID), SetTheme(theme.Name))
 		}
 	}
 
@@ -72,9 +82,7 @@ func (s SetThemeOverlay) Update(msg tea.Msg) (overlay.Overlay, tea.Cmd) {
 	if s.l.Clicked() {
 		item := s.l.Selected()
 		if item.Name != "" {
-			// TODO: set theme somehow
-			// theme := item.(config.ThemeConfig)
-			return s, tea.Batch(cmd, overlay.Close(SetThemeOverlayID))
+			return s, tea.Batch(cmd, overlay.Close(SetThemeOverlayID), SetTheme(item.Name))
 		}
 	}
 
@@ -1,20 +1,26 @@
 package groupedlist
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/charmbracelet/bubbletea/v2"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lrstanley/bubblezone"
 	"go.gopad.dev/gopad/internal/bubbles/key"
 
+	"go.gopad.dev/gopad/internal/bubbles/mouse"
 	"go.gopad.dev/gopad/internal/bubbles/textinput"
 )
 
 func New(items []Item) Model {
 	return Model{
-		TextInput: textinput.New(),
-		Keys:      DefaultKeyMap,
-		Styles:    DefaultStyles,
-		filter:    filterItems,
-		items:     items,
+		TextInput:  textinput.New(),
+		Keys:       DefaultKeyMap,
+		Styles:     DefaultStyles,
+		filter:     filterItems,
+		items:      items,
+		zonePrefix: zone.NewPrefix(),
 	}
 }
 
@@ -23,12 +29,45 @@ type Model struct {
 	Keys      KeyMap
 	Styles    Styles
 
-	filter func(filter string, item Item) bool
-	width  int
-	height int
-	items  []Item
-	item   int
-	offset int
+	filter     func(filter string, item Item) bool
+	width      int
+	height     int
+	items      []Item
+	item       int
+	offset     int
+	zonePrefix string
+	clicked    bool
+}
+
+type visibleItem struct {
+	item   Item
+	indent int
+}
+
+func (m *Model) Focus() tea.Cmd {
+	return m.TextInput.Focus()
+}
+
+func (m *Model) Blur() {
+	m.TextInput.Blur()
+}
+
+func (m *Model) Focused() bool {
+	return m.TextInput.Focused()
+}
+
+func (m *Model) SetWidth(width int) {
+	m.width = width
+}
+
+func (m *Model) SetHeight(height int) {
+	m.height = height
+}
+
+// SetFilter sets the function used to filter the items by the text input value.
+// A nil filter shows all items.
+func (m *Model) SetFilter(filter func(filter string, item Item) bool) {
+	m.filter = filter
 }
 
 func (m *Model) Items() []Item {
@@ -39,31 +78,77 @@ func (m *Model) SetItems(items []Item) {
 	m.items = items
 }
 
-func (m Model) SelectedItem() Item {
-	var i int
-	var walk func(items []Item) Item
-	walk = func(items []Item) Item {
+func (m *Model) Clicked() bool {
+	return m.clicked
+}
+
+// visibleItems returns the filtered items and their sub items flattened in display order.
+func (m Model) visibleItems() []visibleItem {
+	value := m.TextInput.Value()
+
+	var visible []visibleItem
+	var walk func(items []Item, indent int)
+	walk = func(items []Item, indent int) {
 		for _, item := range items {
-			if i == m.item {
-				return item
+			if m.filter != nil && !m.filter(value, item) {
+				continue
 			}
-			i++
+			visible = append(visible, visibleItem{
+				item:   item,
+				indent: indent,
+			})
 			if subItems := item.Items(); len(subItems) > 0 {
-				if found := walk(subItems); found != nil {
-					return found
-				}
+				walk(subItems, indent+1)
 			}
 		}
-		return nil
 	}
+	walk(m.items, 0)
+
+	return visible
+}
+
+func (m Model) SelectedItem() Item {
+	items := m.visibleItems()
+	if m.item < len(items) {
+		return items[m.item].item
+	}
+	return nil
+}
+
+func (m Model) zoneItemID(i int) string {
+	return fmt.Sprintf("groupedlist:%s:%d", m.zonePrefix, i)
+}
 
-	return walk(m.items)
+func (m Model) zoneID() string {
+	return fmt.Sprintf("groupedlist:%s", m.zonePrefix)
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
-	var cmds []tea.Cmd
+	m.clicked = false
+	itemsLen := len(m.visibleItems())
 
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		for i := range itemsLen {
+			if mouse.Matches(msg, m.zoneItemID(i), tea.MouseLeft) {
+				m.item = i
+				m.clicked = true
+				return m, nil
+			}
+		}
+
+		switch {
+		case mouse.Matches(msg, m.zoneID(), tea.MouseWheelUp):
+			if m.item > 0 {
+				m.item--
+			}
+			return m, nil
+		case mouse.Matches(msg, m.zoneID(), tea.MouseWheelDown):
+			if m.item < itemsLen-1 {
+				m.item++
+			}
+			return m, nil
+		}
 	case tea.KeyPressMsg:
 		switch {
 		case key.Matches(msg, m.Keys.Up):
@@ -72,7 +157,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 			return m, nil
 		case key.Matches(msg, m.Keys.Down):
-			if m.item < len(m.items)-1 {
+			if m.item < itemsLen-1 {
 				m.item++
 			}
 			return m, nil
@@ -80,18 +165,34 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.item = 0
 			return m, nil
 		case key.Matches(msg, m.Keys.End):
-			m.item = len(m.items) - 1
+			m.item = max(itemsLen-1, 0)
 			return m, nil
 		}
 	}
 
 	var cmd tea.Cmd
 	m.TextInput, cmd = m.TextInput.Update(msg)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
+
+	// the filter might have hidden the selected item
+	if itemsLen = len(m.visibleItems()); m.item >= itemsLen {
+		m.item = max(itemsLen-1, 0)
 	}
 
-	return m, tea.Batch(cmds...)
+	return m, cmd
+}
+
+// calculateOffset calculates the offset based on the current selected item and the height of the list
+func (m *Model) calculateOffset(height int) {
+	if height <= 0 {
+		m.offset = 0
+		return
+	}
+
+	if m.item >= m.offset+height {
+		m.offset = m.item - height + 1
+	} else if m.item < m.offset {
+		m.offset = m.item
+	}
 }
 
 func (m *Model) View() string {
@@ -101,41 +202,58 @@ func (m *Model) View() string {
 		listWidth = m.width
 	}
 
-	listHeight := len(m.items)
+	items := m.visibleItems()
+	listHeight := len(items)
 	if m.height > 0 {
 		listHeight = m.height - 1 // for the text input
 	}
+	m.calculateOffset(listHeight)
 
-	return lipgloss.JoinVertical(lipgloss.Left,
+	return strings.Join([]string{
 		m.TextInput.View(),
-		m.itemsView(listWidth, listHeight),
-	)
+		m.itemsView(items, listWidth, listHeight),
+	}, "\n")
 }
 
-func (m *Model) itemsView(width int, height int) string {
-	var list string
+func (m *Model) itemsView(items []visibleItem, width int, height int) string {
+	style := m.Styles.Style
+	itemWidth := width - style.GetHorizontalFrameSize()
 
-	var i int
-	var walk func(items []Item, indent int)
-	walk = func(items []Item, indent int) {
-		for _, item := range items {
-			list += m.itemView(i, item, 0)
-			i++
-			if subItems := item.Items(); len(subItems) > 0 {
-				walk(subItems, indent+1)
-			}
+	var list []string
+	for i := range height {
+		ii := i + m.offset
+		if ii >= len(items) {
+			break
 		}
+		list = append(list, m.itemView(ii, items[ii], itemWidth))
 	}
 
-	walk(m.items, 0)
-	return list
+	if itemWidth > 0 {
+		style = style.Width(itemWidth)
+	}
+	str := style.Render(strings.Join(list, "\n"))
+
+	if m.zonePrefix != "" {
+		str = zone.Mark(m.zoneID(), str)
+	}
+	return str
 }
 
-func (m *Model) itemView(i int, item Item, indent int) string {
+func (m *Model) itemView(i int, item visibleItem, width int) string {
 	style := m.Styles.ItemStyle
 	if i == m.item {
 		style = m.Styles.ItemSelectedStyle
 	}
+	style = style.PaddingLeft(style.GetPaddingLeft() + item.indent*2)
 
-	return style.PaddingLeft(indent).Render(item.Title())
+	title := item.item.Title()
+	if width > 0 {
+		title = ansi.Truncate(title, width-style.GetHorizontalFrameSize(), "…")
+	}
+
+	s := style.Render(title)
+	if m.zonePrefix != "" {
+		s = zone.Mark(m.zoneItemID(i), s)
+	}
+	return s
 }
@@ -44,6 +44,7 @@ var DefaultStyles = Styles{
 	ItemStyle:            lipgloss.NewStyle().Padding(0, 1),
 	ItemSelectedStyle:    lipgloss.NewStyle().Padding(0, 1).Reverse(true),
 	ItemDescriptionStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#666666")),
+	ItemMatchStyle:       lipgloss.NewStyle().Bold(true),
 }
 
 type Styles struct {
@@ -51,6 +52,7 @@ type Styles struct {
 	ItemStyle            lipgloss.Style
 	ItemSelectedStyle    lipgloss.Style
 	ItemDescriptionStyle lipgloss.Style
+	ItemMatchStyle       lipgloss.Style
 }
 
 func New[T Item](items []T) Model[T] {
@@ -77,6 +79,7 @@ type Model[T Item] struct {
 	offset     int
 	zonePrefix string
 	clicked    bool
+	noFilter   bool
 }
 
 func (m *Model[T]) Focus() tea.Cmd {
@@ -99,6 +102,12 @@ func (m *Model[T]) SetHeight(height int) {
 	m.height = height
 }
 
+// SetFiltering enables or disables filtering the items by the text input value.
+// With filtering disabled the items are expected to be filtered by the caller.
+func (m *Model[T]) SetFiltering(enabled bool) {
+	m.noFilter = !enabled
+}
+
 func (m *Model[T]) selectedItem() *modelItem[T] {
 	items := m.filteredItems()
 	if m.item < len(items) {
@@ -142,6 +151,9 @@ func (m *Model[T]) SetItems(items []T) {
 }
 
 func (m *Model[T]) filteredItems() []modelItem[T] {
+	if m.noFilter {
+		return m.items
+	}
 	return filterItems(m.items, m.TextInput.Value())
 }
 
@@ -0,0 +1,126 @@
+package diff
+
+type Kind uint8
+
+const (
+	KindEqual Kind = iota
+	KindDelete
+	KindInsert
+)
+
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Lines returns the shortest edit script turning a into b using the Myers diff algorithm.
+func Lines(a []string, b []string) []Line {
+	// strip the common prefix and suffix to keep the search space small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, Line{Kind: KindEqual, Text: text})
+	}
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Kind: KindEqual, Text: text})
+	}
+
+	return lines
+}
+
+// maxEdits limits the edit distance searched, larger differences fall back to replacing all lines.
+const maxEdits = 4096
+
+func myers(a []string, b []string) []Line {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxEdits)
+	if n+m == 0 {
+		return nil
+	}
+
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace stores the part of v used by each step
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+
+	lines := make([]Line, 0, n+m)
+	for _, text := range a {
+		lines = append(lines, Line{Kind: KindDelete, Text: text})
+	}
+	for _, text := range b {
+		lines = append(lines, Line{Kind: KindInsert, Text: text})
+	}
+	return lines
+}
+
+func backtrack(trace [][]int, a []string, b []string) []Line {
+	var lines []Line
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		// v starts at k = -d-1
+		at := func(k int) int {
+			return v[k+d+1]
+		}
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			lines = append(lines, Line{Kind: KindEqual, Text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				lines = append(lines, Line{Kind: KindInsert, Text: b[y]})
+			} else {
+				x--
+				lines = append(lines, Line{Kind: KindDelete, Text: a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
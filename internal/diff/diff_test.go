@@ -0,0 +1,46 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	data := []struct {
+		a        []string
+		b        []string
+		expected []Line
+	}{
+		{nil, nil, []Line{}},
+		{
+			[]string{"a", "b"},
+			[]string{"a", "b"},
+			[]Line{{KindEqual, "a"}, {KindEqual, "b"}},
+		},
+		{
+			[]string{"a", "b", "c"},
+			[]string{"a", "c"},
+			[]Line{{KindEqual, "a"}, {KindDelete, "b"}, {KindEqual, "c"}},
+		},
+		{
+			[]string{"a", "c"},
+			[]string{"a", "b", "c"},
+			[]Line{{KindEqual, "a"}, {KindInsert, "b"}, {KindEqual, "c"}},
+		},
+		{
+			[]string{"a", "b", "c", "d"},
+			[]string{"x", "b", "y", "d"},
+			[]Line{{KindDelete, "a"}, {KindInsert, "x"}, {KindEqual, "b"}, {KindDelete, "c"}, {KindInsert, "y"}, {KindEqual, "d"}},
+		},
+		{
+			nil,
+			[]string{"a"},
+			[]Line{{KindInsert, "a"}},
+		},
+	}
+
+	for _, d := range data {
+		assert.Equal(t, d.expected, Lines(d.a, d.b))
+	}
+}
@@ -0,0 +1,119 @@
+package fuzzymatch
+
+import (
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	scoreGapStart    = -3
+	scoreGapExtend   = -1
+	bonusBoundary    = 8
+	bonusSeparator   = 10
+	bonusCamelCase   = 7
+	bonusConsecutive = 6
+	bonusFirstChar   = 2
+	bonusBaseName    = 4
+)
+
+// Match matches the pattern case-insensitively against the target.
+// It returns a score where higher is better and the rune indexes of the matched characters.
+// The pattern matches if all of its characters appear in order in the target.
+func Match(pattern string, target string) (int, []int, bool) {
+	p := []rune(pattern)
+	t := []rune(target)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	// find the first end of a match
+	pi := 0
+	end := -1
+	for ti := 0; ti < len(t); ti++ {
+		if equalFold(p[pi], t[ti]) {
+			pi++
+			if pi == len(p) {
+				end = ti
+				break
+			}
+		}
+	}
+	if end == -1 {
+		return 0, nil, false
+	}
+
+	// walk backwards from the end to find the shortest match
+	positions := make([]int, len(p))
+	pi = len(p) - 1
+	for ti := end; ti >= 0; ti-- {
+		if equalFold(p[pi], t[ti]) {
+			positions[pi] = ti
+			if pi == 0 {
+				break
+			}
+			pi--
+		}
+	}
+
+	return score(t, positions), positions, true
+}
+
+func score(t []rune, positions []int) int {
+	baseName := 0
+	for i := len(t) - 1; i >= 0; i-- {
+		if t[i] == '/' || t[i] == '\\' {
+			baseName = i + 1
+			break
+		}
+	}
+
+	var s int
+	for i, pos := range positions {
+		s += scoreMatch
+
+		bonus := charBonus(t, pos)
+		if i == 0 {
+			bonus *= bonusFirstChar
+		}
+		s += bonus
+
+		if pos >= baseName {
+			s += bonusBaseName
+		}
+
+		if i > 0 {
+			gap := pos - positions[i-1] - 1
+			if gap == 0 {
+				s += bonusConsecutive
+			} else {
+				s += scoreGapStart + scoreGapExtend*(gap-1)
+			}
+		}
+	}
+
+	// prefer shorter targets
+	s -= len(t) / 8
+
+	return s
+}
+
+func charBonus(t []rune, pos int) int {
+	if pos == 0 {
+		return bonusBoundary
+	}
+
+	prev := t[pos-1]
+	switch {
+	case prev == '/' || prev == '\\':
+		return bonusSeparator
+	case prev == '_' || prev == '-' || prev == '.' || prev == ' ':
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(t[pos]):
+		return bonusCamelCase
+	}
+	return 0
+}
+
+func equalFold(a rune, b rune) bool {
+	return a == b || unicode.ToLower(a) == unicode.ToLower(b)
+}
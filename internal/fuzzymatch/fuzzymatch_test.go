@@ -0,0 +1,50 @@
+package fuzzymatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	data := []struct {
+		pattern   string
+		target    string
+		ok        bool
+		positions []int
+	}{
+		{"", "main.go", true, nil},
+		{"main", "main.go", true, []int{0, 1, 2, 3}},
+		{"MG", "main.go", true, []int{0, 5}},
+		{"fb", "foo/bar.go", true, []int{0, 4}},
+		{"edgo", "gopad/editor/editor.go", true, []int{13, 14, 20, 21}},
+		{"xyz", "main.go", false, nil},
+		{"og", "go", false, nil},
+	}
+
+	for _, d := range data {
+		_, positions, ok := Match(d.pattern, d.target)
+		assert.Equal(t, d.ok, ok, d.pattern)
+		assert.Equal(t, d.positions, positions, d.pattern)
+	}
+}
+
+func TestMatch_Score(t *testing.T) {
+	data := []struct {
+		pattern string
+		better  string
+		worse   string
+	}{
+		{"edit", "gopad/editor/editor.go", "gopad/editor/file/edit_history.go.bak"},
+		{"fg", "file_go.txt", "afxgx.txt"},
+		{"main", "cmd/main.go", "main/cmd/deep/other/path/file.go"},
+	}
+
+	for _, d := range data {
+		better, _, ok := Match(d.pattern, d.better)
+		assert.True(t, ok)
+		worse, _, ok := Match(d.pattern, d.worse)
+		assert.True(t, ok)
+		assert.Greater(t, better, worse, d.pattern)
+	}
+}
@@ -0,0 +1,119 @@
+package gitignore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const FileName = ".gitignore"
+
+type pattern struct {
+	base     string
+	glob     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Matcher matches slash separated paths relative to the workspace against gitignore patterns.
+type Matcher struct {
+	patterns []pattern
+}
+
+// Parse reads the patterns of a .gitignore file located in the base directory.
+// The base directory is relative to the workspace, an empty string is the workspace itself.
+func (m *Matcher) Parse(base string, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if p, ok := parsePattern(base, scanner.Text()); ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+	return scanner.Err()
+}
+
+// ParseFile reads the .gitignore file of the directory if it exists.
+func (m *Matcher) ParseFile(base string, dir string) error {
+	file, err := os.Open(filepath.Join(dir, FileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return m.Parse(base, file)
+}
+
+func parsePattern(base string, line string) (pattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+
+	p := pattern{
+		base: base,
+	}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	// escaped leading ! and #
+	line = strings.TrimPrefix(line, `\`)
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	// a slash at the start or in the middle anchors the pattern to the base directory
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	if line == "" {
+		return pattern{}, false
+	}
+	p.glob = line
+
+	return p, true
+}
+
+func (p pattern) match(name string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	if p.base != "" {
+		if !strings.HasPrefix(name, p.base+"/") {
+			return false
+		}
+		name = strings.TrimPrefix(name, p.base+"/")
+	}
+
+	if !p.anchored {
+		name = path.Base(name)
+	}
+
+	ok, _ := doublestar.Match(p.glob, name)
+	return ok
+}
+
+// Match returns whether the path is ignored. The last matching pattern wins.
+func (m *Matcher) Match(name string, isDir bool) bool {
+	var ignored bool
+	for _, p := range m.patterns {
+		if p.match(name, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
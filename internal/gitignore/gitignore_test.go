@@ -0,0 +1,45 @@
+package gitignore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	var m Matcher
+	assert.NoError(t, m.Parse("", strings.NewReader(`# comment
+*.log
+!keep.log
+build/
+/vendor
+docs/**/*.tmp
+`)))
+	assert.NoError(t, m.Parse("sub", strings.NewReader("local.txt\n")))
+
+	data := []struct {
+		name     string
+		isDir    bool
+		expected bool
+	}{
+		{"main.go", false, false},
+		{"debug.log", false, true},
+		{"logs/debug.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"cmd/build", true, true},
+		{"vendor", true, true},
+		{"cmd/vendor", true, false},
+		{"docs/a/b/c.tmp", false, true},
+		{"c.tmp", false, false},
+		{"sub/local.txt", false, true},
+		{"sub/deep/local.txt", false, true},
+		{"local.txt", false, false},
+	}
+
+	for _, d := range data {
+		assert.Equal(t, d.expected, m.Match(d.name, d.isDir), d.name)
+	}
+}
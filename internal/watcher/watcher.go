@@ -0,0 +1,106 @@
+package watcher
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+type Op uint8
+
+const (
+	OpCreate Op = iota
+	OpWrite
+	OpRemove
+	OpRename
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpCreate:
+		return "create"
+	case OpWrite:
+		return "write"
+	case OpRemove:
+		return "remove"
+	case OpRename:
+		return "rename"
+	}
+	return "unknown"
+}
+
+// Event describes a change of a file or directory between two snapshots.
+// OldName is only set for OpRename.
+type Event struct {
+	Op      Op
+	Name    string
+	OldName string
+	IsDir   bool
+}
+
+type Entry struct {
+	ModTime time.Time
+	Size    int64
+	IsDir   bool
+}
+
+// Snapshot is the state of the watched files by their name.
+type Snapshot map[string]Entry
+
+// Diff returns the events turning the old snapshot into the new one sorted by name.
+// A removed and a created file with the same size and modification time are reported as a rename.
+func Diff(old Snapshot, new Snapshot) []Event {
+	var created, removed, events []Event
+	for name, entry := range new {
+		oldEntry, ok := old[name]
+		if !ok {
+			created = append(created, Event{Op: OpCreate, Name: name, IsDir: entry.IsDir})
+			continue
+		}
+		if !entry.IsDir && (!oldEntry.ModTime.Equal(entry.ModTime) || oldEntry.Size != entry.Size) {
+			events = append(events, Event{Op: OpWrite, Name: name})
+		}
+	}
+	for name, entry := range old {
+		if _, ok := new[name]; !ok {
+			removed = append(removed, Event{Op: OpRemove, Name: name, IsDir: entry.IsDir})
+		}
+	}
+
+	sortEvents(created)
+	sortEvents(removed)
+
+	for i, c := range created {
+		if c.IsDir {
+			continue
+		}
+		entry := new[c.Name]
+		matches := func(e Event) bool {
+			oldEntry := old[e.Name]
+			return e.Op == OpRemove && !e.IsDir && oldEntry.Size == entry.Size && oldEntry.ModTime.Equal(entry.ModTime)
+		}
+		// only pair unambiguous files
+		j := slices.IndexFunc(removed, matches)
+		if j == -1 || slices.IndexFunc(removed[j+1:], matches) > -1 {
+			continue
+		}
+		created[i] = Event{Op: OpRename, Name: c.Name, OldName: removed[j].Name}
+		removed[j].Op = OpRename
+	}
+
+	for _, e := range removed {
+		if e.Op == OpRemove {
+			events = append(events, e)
+		}
+	}
+	events = append(events, created...)
+	sortEvents(events)
+
+	return events
+}
+
+func sortEvents(events []Event) {
+	slices.SortFunc(events, func(a Event, b Event) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
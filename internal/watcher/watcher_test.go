@@ -0,0 +1,62 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t1 := time.Unix(1, 0)
+	t2 := time.Unix(2, 0)
+
+	data := []struct {
+		name     string
+		old      Snapshot
+		new      Snapshot
+		expected []Event
+	}{
+		{
+			name:     "unchanged",
+			old:      Snapshot{"a": {ModTime: t1, Size: 1}},
+			new:      Snapshot{"a": {ModTime: t1, Size: 1}},
+			expected: nil,
+		},
+		{
+			name: "create write remove",
+			old:  Snapshot{"a": {ModTime: t1, Size: 1}, "b": {ModTime: t1, Size: 2}},
+			new:  Snapshot{"a": {ModTime: t2, Size: 1}, "c": {ModTime: t2, Size: 3}, "d": {IsDir: true}},
+			expected: []Event{
+				{Op: OpWrite, Name: "a"},
+				{Op: OpRemove, Name: "b"},
+				{Op: OpCreate, Name: "c"},
+				{Op: OpCreate, Name: "d", IsDir: true},
+			},
+		},
+		{
+			name: "rename",
+			old:  Snapshot{"a": {ModTime: t1, Size: 1}},
+			new:  Snapshot{"b": {ModTime: t1, Size: 1}},
+			expected: []Event{
+				{Op: OpRename, Name: "b", OldName: "a"},
+			},
+		},
+		{
+			name: "ambiguous rename",
+			old:  Snapshot{"a": {ModTime: t1, Size: 1}, "b": {ModTime: t1, Size: 1}},
+			new:  Snapshot{"c": {ModTime: t1, Size: 1}},
+			expected: []Event{
+				{Op: OpRemove, Name: "a"},
+				{Op: OpRemove, Name: "b"},
+				{Op: OpCreate, Name: "c"},
+			},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			assert.Equal(t, d.expected, Diff(d.old, d.new))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package xos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes the file to a temporary file next to it and replaces the file once the content is synced to disk.
+// This way the file is never left partially written. The mode and ownership of an existing file are preserved,
+// new files are created with perm.
+func WriteFile(name string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	// replace the target of a symlink instead of the symlink itself
+	if target, err := filepath.EvalSymlinks(name); err == nil {
+		name = target
+	}
+
+	info, err := os.Stat(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading file info: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	if err = write(file); err != nil {
+		return err
+	}
+
+	mode := perm
+	if info != nil {
+		mode = info.Mode().Perm()
+		if err = chown(file, info); err != nil {
+			return fmt.Errorf("error setting file owner: %w", err)
+		}
+	}
+	if err = file.Chmod(mode); err != nil {
+		return fmt.Errorf("error setting file mode: %w", err)
+	}
+
+	if err = file.Sync(); err != nil {
+		return fmt.Errorf("error syncing file: %w", err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("error closing file: %w", err)
+	}
+
+	if err = os.Rename(file.Name(), name); err != nil {
+		return fmt.Errorf("error replacing file: %w", err)
+	}
+
+	return syncDir(filepath.Dir(name))
+}
@@ -0,0 +1,50 @@
+package xos
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFile(t *testing.T) {
+	writeString := func(s string) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.txt")
+
+	assert.NoError(t, WriteFile(name, 0600, writeString("a")))
+	info, err := os.Stat(name)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the mode of the existing file is kept
+	assert.NoError(t, os.Chmod(name, 0640))
+	assert.NoError(t, WriteFile(name, 0600, writeString("b")))
+	info, err = os.Stat(name)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// a failed write leaves the file and no temporary file behind
+	writeErr := errors.New("write failed")
+	assert.ErrorIs(t, WriteFile(name, 0600, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "c")
+		return writeErr
+	}), writeErr)
+
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(data))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
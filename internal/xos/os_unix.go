@@ -0,0 +1,41 @@
+//go:build !windows
+
+package xos
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// chown gives the file the owner and group of the file info.
+func chown(file *os.File, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if err := file.Chown(int(stat.Uid), int(stat.Gid)); err != nil {
+		// only privileged users can hand files to other users, keep our own ownership in that case
+		if errors.Is(err, os.ErrPermission) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// syncDir syncs the directory so a rename inside it survives a crash.
+func syncDir(name string) error {
+	dir, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("error opening directory: %w", err)
+	}
+	defer dir.Close()
+
+	// some file systems do not support syncing directories
+	if err = dir.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) {
+		return fmt.Errorf("error syncing directory: %w", err)
+	}
+	return nil
+}
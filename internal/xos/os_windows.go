@@ -0,0 +1,17 @@
+//go:build windows
+
+package xos
+
+import (
+	"os"
+)
+
+// chown is not supported on windows, files keep the owner of the creating user.
+func chown(file *os.File, info os.FileInfo) error {
+	return nil
+}
+
+// syncDir is not supported on windows, the rename is durable once it returns.
+func syncDir(name string) error {
+	return nil
+}